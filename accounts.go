@@ -0,0 +1,186 @@
+package yahoo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// RevokedError is returned for an account whose refresh token has been revoked or has expired,
+// the user must authorize the application again.
+type RevokedError struct {
+	Guid string
+	Err  error
+}
+
+func (e *RevokedError) Error() string {
+	return fmt.Sprintf("Refresh token for %s is no longer valid, authorization is required: %v", e.Guid, e.Err)
+}
+
+// IsRevoked determines if err is a token endpoint rejection of the refresh token.
+func IsRevoked(err error) bool {
+	var re *RevokedError
+	if errors.As(err, &re) {
+		return true
+	}
+
+	var rerr *oauth2.RetrieveError
+	if !errors.As(err, &rerr) {
+		return false
+	}
+	// the error code is only parsed when the response has a json or form content type.
+	return rerr.ErrorCode == "invalid_grant" || (rerr.ErrorCode == "" && strings.Contains(string(rerr.Body), `"invalid_grant"`))
+}
+
+// DirTokenStores returns a store factory for Accounts which saves each account's token in dir/<guid>.json.
+func DirTokenStores(dir string) func(guid string) TokenStore {
+	return func(guid string) TokenStore {
+		return NewFileTokenStore(filepath.Join(dir, filepath.Base(guid)+".json"))
+	}
+}
+
+// Accounts holds the token sources of many users keyed by their Yahoo GUID, the fantasy.User.Guid.
+// Tokens are loaded and refreshed lazily when an account is used.
+// It is safe for concurrent use.
+type Accounts struct {
+	// OnRefresh, OnRefreshError, OnInvalid and OnSaveError are set as the hooks of every account's TokenSource.
+	// They must be set before the Accounts is used.
+	OnRefresh      func(guid string, old, new *oauth2.Token)
+	OnRefreshError func(guid string, err error)
+	OnInvalid      func(guid string, err error)
+	OnSaveError    func(guid string, err error)
+
+	ctx      context.Context
+	conf     *oauth2.Config
+	newStore func(guid string) TokenStore
+
+	mu      sync.Mutex
+	sources map[string]*TokenSource
+	revoked map[string]error
+}
+
+// NewAccounts creates an Accounts registry, newStore returns the TokenStore of a guid.
+func NewAccounts(ctx context.Context, conf *oauth2.Config, newStore func(guid string) TokenStore) *Accounts {
+	return &Accounts{
+		ctx:      ctx,
+		conf:     conf,
+		newStore: newStore,
+		sources:  map[string]*TokenSource{},
+		revoked:  map[string]error{},
+	}
+}
+
+// Add saves a newly authorized token for guid, clearing any revoked state.
+func (a *Accounts) Add(guid string, t *oauth2.Token) error {
+	ts := a.TokenSource(guid)
+	if err := ts.SetToken(t); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	delete(a.revoked, guid)
+	a.mu.Unlock()
+	return nil
+}
+
+// Remove forgets the account, its saved token is left in the store.
+func (a *Accounts) Remove(guid string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.sources, guid)
+	delete(a.revoked, guid)
+}
+
+// TokenSource returns the token source of guid, creating it on first use.
+func (a *Accounts) TokenSource(guid string) *TokenSource {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ts, ok := a.sources[guid]
+	if !ok {
+		ts = NewTokenSource(a.ctx, a.conf, a.newStore(guid))
+		if a.OnRefresh != nil {
+			ts.OnRefresh = func(old, new *oauth2.Token) { a.OnRefresh(guid, old, new) }
+		}
+		if a.OnRefreshError != nil {
+			ts.OnRefreshError = func(err error) { a.OnRefreshError(guid, err) }
+		}
+		if a.OnInvalid != nil {
+			ts.OnInvalid = func(err error) { a.OnInvalid(guid, err) }
+		}
+		if a.OnSaveError != nil {
+			ts.OnSaveError = func(err error) { a.OnSaveError(guid, err) }
+		}
+		a.sources[guid] = ts
+	}
+	return ts
+}
+
+// Token returns a valid token for guid, refreshing it if needed.
+// It returns a *RevokedError without contacting Yahoo once the refresh token has been rejected.
+func (a *Accounts) Token(guid string) (*oauth2.Token, error) {
+	a.mu.Lock()
+	err := a.revoked[guid]
+	a.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := a.TokenSource(guid).Token()
+	if err != nil && IsRevoked(err) {
+		err = &RevokedError{Guid: guid, Err: err}
+		a.mu.Lock()
+		a.revoked[guid] = err
+		a.mu.Unlock()
+	}
+	return t, err
+}
+
+// Client returns an http.Client for the fantasy query builders which acts on behalf of guid.
+func (a *Accounts) Client(guid string) *http.Client {
+	return withScopes(oauth2.NewClient(a.ctx, accountTokenSource{a, guid}), a.TokenSource(guid).scopes)
+}
+
+// Guids returns the guids of every account which has been used, sorted.
+func (a *Accounts) Guids() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	guids := make([]string, 0, len(a.sources))
+	for guid := range a.sources {
+		guids = append(guids, guid)
+	}
+	sort.Strings(guids)
+	return guids
+}
+
+// Revoked returns the guids of accounts whose refresh token has been rejected, sorted.
+func (a *Accounts) Revoked() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	guids := make([]string, 0, len(a.revoked))
+	for guid := range a.revoked {
+		guids = append(guids, guid)
+	}
+	sort.Strings(guids)
+	return guids
+}
+
+// AccountTokenSource is the oauth2.TokenSource of a single account.
+type accountTokenSource struct {
+	accounts *Accounts
+	guid     string
+}
+
+func (s accountTokenSource) Token() (*oauth2.Token, error) {
+	return s.accounts.Token(s.guid)
+}
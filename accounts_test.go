@@ -0,0 +1,175 @@
+package yahoo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestAccounts(t *testing.T) {
+	server, conf := newTokenServer(t)
+	defer server.Close()
+
+	stores := map[string]*memoryTokenStore{
+		"GOOD": {token: &oauth2.Token{AccessToken: "expired", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Hour)}},
+		"BAD":  {token: &oauth2.Token{AccessToken: "expired", RefreshToken: "revoked", Expiry: time.Now().Add(-time.Hour)}},
+	}
+	var mu sync.Mutex
+	loads := 0
+	accounts := NewAccounts(context.Background(), conf, func(guid string) TokenStore {
+		mu.Lock()
+		defer mu.Unlock()
+		loads++
+		if s, ok := stores[guid]; ok {
+			return s
+		}
+		return &memoryTokenStore{}
+	})
+
+	var invalid []string
+	accounts.OnInvalid = func(guid string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		invalid = append(invalid, guid)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 20)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			guid := "GOOD"
+			if i%2 == 1 {
+				guid = "BAD"
+			}
+			_, errs[i] = accounts.Token(guid)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if i%2 == 0 && err != nil {
+			t.Errorf("Unexpected Accounts.Token error for GOOD: %v", err)
+		}
+		if i%2 == 1 && !IsRevoked(err) {
+			t.Errorf("Expected a revoked error for BAD got %v", err)
+		}
+	}
+
+	// the rejected refresh token is only sent to Yahoo once.
+	if len(invalid) != 1 || invalid[0] != "BAD" {
+		t.Errorf("Expected OnInvalid to be called once for BAD got %v", invalid)
+	}
+
+	if loads != 2 {
+		t.Errorf("Expected one store per account got %d", loads)
+	}
+	if stores["GOOD"].saves != 1 {
+		t.Errorf("Expected the refreshed token to be saved once got %d", stores["GOOD"].saves)
+	}
+	if r := accounts.Revoked(); len(r) != 1 || r[0] != "BAD" {
+		t.Errorf("Incorrect revoked accounts %v", r)
+	}
+	if g := accounts.Guids(); len(g) != 2 || g[0] != "BAD" || g[1] != "GOOD" {
+		t.Errorf("Incorrect account guids %v", g)
+	}
+
+	if _, err := accounts.Token("MISSING"); err != ErrNoToken {
+		t.Errorf("Expected ErrNoToken for an unknown account got %v", err)
+	}
+
+	// authorizing again clears the revoked state.
+	if err := accounts.Add("BAD", &oauth2.Token{AccessToken: "new", Expiry: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("Unexpected Accounts.Add error: %v", err)
+	}
+	if tok, err := accounts.Token("BAD"); err != nil || tok.AccessToken != "new" {
+		t.Errorf("Accounts.Token returned %v, %v after Add", tok, err)
+	}
+	if r := accounts.Revoked(); len(r) != 0 {
+		t.Errorf("Accounts.Add did not clear the revoked state %v", r)
+	}
+}
+
+func TestAccountsSaveError(t *testing.T) {
+	server, conf := newTokenServer(t)
+	defer server.Close()
+
+	accounts := NewAccounts(context.Background(), conf, func(guid string) TokenStore {
+		return &memoryTokenStore{
+			token:   &oauth2.Token{AccessToken: "expired", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Hour)},
+			saveErr: errors.New("disk full"),
+		}
+	})
+
+	var failed []string
+	accounts.OnSaveError = func(guid string, err error) { failed = append(failed, guid) }
+
+	if tok, err := accounts.Token("GOOD"); err != nil || tok.AccessToken != "access-1" {
+		t.Errorf("Accounts.Token returned %v, %v when the store fails", tok, err)
+	}
+	if len(failed) != 1 || failed[0] != "GOOD" {
+		t.Errorf("Expected OnSaveError to be called once for GOOD got %v", failed)
+	}
+}
+
+func TestAccountsClient(t *testing.T) {
+	server, conf := newTokenServer(t)
+	defer server.Close()
+
+	api := newBearerServer()
+	defer api.Close()
+
+	accounts := NewAccounts(context.Background(), conf, func(guid string) TokenStore {
+		return &memoryTokenStore{token: &oauth2.Token{AccessToken: "access-" + guid, Expiry: time.Now().Add(time.Hour)}}
+	})
+
+	for _, guid := range []string{"A", "B"} {
+		resp, err := accounts.Client(guid).Get(api.URL)
+		if err != nil {
+			t.Fatalf("Unexpected request error: %v", err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if string(body) != "Bearer access-"+guid {
+			t.Errorf("Client for %s sent authorization %s", guid, body)
+		}
+	}
+}
+
+func TestDirTokenStores(t *testing.T) {
+	dir, err := ioutil.TempDir("", "yahoo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	stores := DirTokenStores(dir)
+	if err := stores("GUID").Save(&oauth2.Token{AccessToken: "access"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(fmt.Sprintf("%s/GUID.json", dir)); err != nil {
+		t.Errorf("Token was not saved to GUID.json: %v", err)
+	}
+	if _, err := stores("OTHER").Load(); err != ErrNoToken {
+		t.Errorf("Expected ErrNoToken for another guid got %v", err)
+	}
+}
+
+// newBearerServer responds with the Authorization header of each request.
+func newBearerServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Header.Get("Authorization"))
+	}))
+}
@@ -2,6 +2,9 @@
 package yahoo
 
 import (
+	"net/http"
+	"strings"
+
 	"golang.org/x/oauth2"
 )
 
@@ -12,20 +15,71 @@ const (
 
 // NewConfig creates a ready to use Auth instance.
 // if the redirectUrl is empty, this is assumed to be an installed application.
-func NewConfig(clientId, clientSecret, redirectUrl string) *oauth2.Config {
+// if the clientSecret is empty, this is assumed to be a public client which authorizes with PKCE,
+// the client id is then sent in the token request body.
+// scopes are requested during authorization, e.g. ScopeRead or ScopeWrite,
+// without them the access granted is that of the application registration.
+func NewConfig(clientId, clientSecret, redirectUrl string, scopes ...string) *oauth2.Config {
 	if redirectUrl == "" {
 		redirectUrl = "oob"
 	}
 
+	authStyle := oauth2.AuthStyleAutoDetect
+	if clientSecret == "" {
+		authStyle = oauth2.AuthStyleInParams
+	}
+
 	config := &oauth2.Config{
 		ClientID:     clientId,
 		ClientSecret: clientSecret,
 		Endpoint: oauth2.Endpoint{
-			AuthURL:  AuthURL,
-			TokenURL: TokenURL,
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: authStyle,
 		},
 		RedirectURL: redirectUrl,
+		Scopes:      scopes,
 	}
 
 	return config
 }
+
+// WithScopes returns a copy of client whose transport reports scopes,
+// the fantasy package then fails write requests without ScopeWrite before sending them.
+func WithScopes(client *http.Client, scopes ...string) *http.Client {
+	return withScopes(client, func() []string { return scopes })
+}
+
+func withScopes(client *http.Client, scopes func() []string) *http.Client {
+	c := *client
+	c.Transport = &scopeTransport{base: client.Transport, scopes: scopes}
+	return &c
+}
+
+// ScopeTransport is an http.RoundTripper which reports the scopes of its token.
+type scopeTransport struct {
+	base   http.RoundTripper
+	scopes func() []string
+}
+
+func (t *scopeTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if t.base == nil {
+		return http.DefaultTransport.RoundTrip(r)
+	}
+	return t.base.RoundTrip(r)
+}
+
+// Scopes returns the scopes of the token, nil when they are unknown.
+func (t *scopeTransport) Scopes() []string {
+	return t.scopes()
+}
+
+// TokenScopes returns the scopes Yahoo granted with t, or the requested scopes when the token does not list them.
+func tokenScopes(t *oauth2.Token, requested []string) []string {
+	if t != nil {
+		if granted, ok := t.Extra("scope").(string); ok && granted != "" {
+			return strings.Fields(granted)
+		}
+	}
+	return requested
+}
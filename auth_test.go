@@ -1,7 +1,14 @@
 package yahoo
 
 import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
 )
 
 func TestNewConfig(t *testing.T) {
@@ -25,4 +32,64 @@ func TestNewConfig(t *testing.T) {
 	if auth.RedirectURL != "oob" {
 		t.Errorf("Unexpected redirectUrl: got %s want %s", auth.RedirectURL, "oob")
 	}
+
+	if auth.Endpoint.AuthStyle != oauth2.AuthStyleAutoDetect {
+		t.Errorf("Unexpected AuthStyle: got %v want %v", auth.Endpoint.AuthStyle, oauth2.AuthStyleAutoDetect)
+	}
+
+	auth = NewConfig(clientId, "", "")
+
+	if auth.Endpoint.AuthStyle != oauth2.AuthStyleInParams {
+		t.Errorf("Unexpected AuthStyle for a public client: got %v want %v", auth.Endpoint.AuthStyle, oauth2.AuthStyleInParams)
+	}
+
+	if len(auth.Scopes) != 0 {
+		t.Errorf("Unexpected scopes: got %v want none", auth.Scopes)
+	}
+
+	auth = NewConfig(clientId, clientSecret, "", ScopeWrite, ScopeOpenID)
+
+	if !reflect.DeepEqual(auth.Scopes, []string{ScopeWrite, ScopeOpenID}) {
+		t.Errorf("Unexpected scopes: got %v want %v", auth.Scopes, []string{ScopeWrite, ScopeOpenID})
+	}
+
+	if u := auth.AuthCodeURL("state"); !strings.Contains(u, "scope=fspt-w+openid") {
+		t.Errorf("AuthCodeURL %s does not request the scopes", u)
+	}
+}
+
+// scopesOf returns the scopes reported by a client's transport.
+func scopesOf(t *testing.T, client *http.Client) []string {
+	r, ok := client.Transport.(interface{ Scopes() []string })
+	if !ok {
+		t.Fatalf("Client transport %T does not report scopes", client.Transport)
+	}
+	return r.Scopes()
+}
+
+func TestClientScopes(t *testing.T) {
+	if s := scopesOf(t, WithScopes(http.DefaultClient, ScopeRead)); !reflect.DeepEqual(s, []string{ScopeRead}) {
+		t.Errorf("WithScopes client reported %v", s)
+	}
+
+	conf := NewConfig("clientId", "clientSecret", "", ScopeRead)
+	store := &memoryTokenStore{token: &oauth2.Token{AccessToken: "access", Expiry: time.Now().Add(time.Hour)}}
+	ts := NewTokenSource(context.Background(), conf, store)
+	client := ts.Client()
+
+	// before a token is loaded the requested scopes are reported.
+	if s := scopesOf(t, client); !reflect.DeepEqual(s, []string{ScopeRead}) {
+		t.Errorf("TokenSource client reported %v, expected the requested scopes", s)
+	}
+
+	// the scopes Yahoo granted take precedence.
+	ts.SetToken(store.token.WithExtra(map[string]interface{}{"scope": "fspt-w openid"}))
+	if s := scopesOf(t, client); !reflect.DeepEqual(s, []string{ScopeWrite, ScopeOpenID}) {
+		t.Errorf("TokenSource client reported %v, expected the granted scopes", s)
+	}
+
+	accounts := NewAccounts(context.Background(), conf, func(string) TokenStore { return store })
+	if s := scopesOf(t, accounts.Client("GUID")); !reflect.DeepEqual(s, []string{ScopeRead}) {
+		t.Errorf("Accounts client reported %v", s)
+	}
 }
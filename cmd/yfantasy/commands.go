@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/muswell/yahoo/fantasy"
+)
+
+// activeUser selects the logged in user.
+var activeUser = &fantasy.UserQueryBuilder{ActiveUser: true}
+
+// whoami prints the guid of the logged in user.
+func (a *app) whoami(args []string) error {
+	if _, err := parseArgs(a.flags("whoami"), args); err != nil {
+		return err
+	}
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	users, err := activeUser.Get(client)
+	if err != nil {
+		return err
+	}
+	r := result{header: []string{"GUID"}}
+	for _, u := range users {
+		r.rows = append(r.rows, []string{u.Guid})
+	}
+	return a.print(r)
+}
+
+// games lists the user's games, or every available game.
+func (a *app) games(args []string) error {
+	fs := a.flags("games")
+	available := fs.Bool("available", false, "list every game open for registration instead of yours")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	q := &fantasy.GameQueryBuilder{UserQB: activeUser}
+	if *available {
+		q = &fantasy.GameQueryBuilder{Available: true}
+	}
+	games, err := q.Get(client)
+	if err != nil {
+		return err
+	}
+
+	r := result{header: []string{"KEY", "CODE", "NAME", "SEASON"}}
+	for _, g := range games {
+		r.rows = append(r.rows, []string{itoa(g.Key), g.Code, g.Name, itoa(g.Season)})
+	}
+	return a.print(r)
+}
+
+// leagues lists the user's leagues.
+func (a *app) leagues(args []string) error {
+	fs := a.flags("leagues")
+	game := fs.String("game", "", "only list leagues of the comma separated game `codes or keys` e.g. mlb")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	user := activeUser
+	if *game != "" {
+		user = &fantasy.UserQueryBuilder{ActiveUser: true, GameQB: &fantasy.GameQueryBuilder{Keys: strings.Split(*game, ",")}}
+	}
+	leagues, err := (&fantasy.LeagueQueryBuilder{UserQB: user}).Get(client)
+	if err != nil {
+		return err
+	}
+
+	r := result{header: []string{"KEY", "NAME", "GAME", "SEASON", "TEAMS", "SCORING", "DRAFT"}}
+	for _, l := range leagues {
+		r.rows = append(r.rows, []string{l.Key, l.Name, l.GameCode, itoa(l.Season), itoa(l.NumTeams), l.ScoringType.String(), l.DraftStatus.String()})
+	}
+	return a.print(r)
+}
+
+// standings lists the teams of a league by rank.
+func (a *app) standings(args []string) error {
+	keys, err := parseArgs(a.flags("standings"), args, "league_key")
+	if err != nil {
+		return err
+	}
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	q := &fantasy.TeamQueryBuilder{LeagueQB: &fantasy.LeagueQueryBuilder{Keys: keys}, Standings: true}
+	teams, err := q.Get(client)
+	if err != nil {
+		return err
+	}
+
+	standings := func(t fantasy.Team) fantasy.TeamStandings {
+		if t.Standings == nil {
+			return fantasy.TeamStandings{}
+		}
+		return *t.Standings
+	}
+	sort.SliceStable(teams, func(i, j int) bool {
+		ri, rj := standings(teams[i]).Rank, standings(teams[j]).Rank
+		return ri.Valid && (!rj.Valid || ri.Value < rj.Value)
+	})
+
+	r := result{header: []string{"RANK", "TEAM", "KEY", "RECORD", "PCT", "GB", "POINTS"}}
+	for _, t := range teams {
+		s := standings(t)
+		var record string
+		if o := s.OutcomeTotals; o.Wins.Valid {
+			record = o.Wins.String() + "-" + o.Losses.String() + "-" + o.Ties.String()
+		}
+		r.rows = append(r.rows, []string{s.Rank.String(), t.Name, t.Key, record, s.OutcomeTotals.Percentage.String(), s.GamesBack, s.PointsFor.String()})
+	}
+	return a.print(r)
+}
+
+// roster lists the players of a team and their positions.
+func (a *app) roster(args []string) error {
+	keys, err := parseArgs(a.flags("roster"), args, "team_key")
+	if err != nil {
+		return err
+	}
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	teams, err := (&fantasy.TeamQueryBuilder{Keys: keys, Roster: true}).Get(client)
+	if err != nil {
+		return err
+	}
+	if len(teams) == 0 || teams[0].Roster == nil {
+		return fmt.Errorf("Team %s has no roster", keys[0])
+	}
+
+	r := result{header: []string{"POSITION", "PLAYER", "KEY", "TEAM", "ELIGIBLE", "STATUS"}}
+	for _, p := range teams[0].Roster.Players {
+		r.rows = append(r.rows, []string{p.SelectedPosition.Position, p.Name.Full, p.Key, p.EditorialTeamAbbr, strings.Join(p.EligiblePositions, ","), p.Status})
+	}
+	return a.print(r)
+}
+
+// players lists the players of a league.
+func (a *app) players(args []string) error {
+	fs := a.flags("players")
+	q := &fantasy.PlayerQueryBuilder{}
+	fs.StringVar(&q.Status, "status", "", "filter by `status`: A (available), FA (free agents), W (waivers) or T (taken)")
+	fs.StringVar(&q.Position, "position", "", "filter by eligible `position` e.g. OF")
+	fs.StringVar(&q.Search, "search", "", "filter by `name`")
+	fs.StringVar(&q.Sort, "sort", "", "sort `order` e.g. OR (overall rank), AR (actual rank) or a stat id")
+	fs.IntVar(&q.Start, "start", 0, "offset of the first player")
+	fs.IntVar(&q.Count, "count", 25, "maximum number of players")
+	keys, err := parseArgs(fs, args, "league_key")
+	if err != nil {
+		return err
+	}
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	q.LeagueQB = &fantasy.LeagueQueryBuilder{Keys: keys}
+	players, err := q.Get(client)
+	if err != nil {
+		return err
+	}
+
+	r := result{header: []string{"KEY", "NAME", "TEAM", "POSITIONS", "STATUS"}}
+	for _, p := range players {
+		r.rows = append(r.rows, []string{p.Key, p.Name.Full, p.EditorialTeamAbbr, p.DisplayPosition, p.Status})
+	}
+	return a.print(r)
+}
+
+// transactions lists the transactions of a league, newest first.
+func (a *app) transactions(args []string) error {
+	fs := a.flags("transactions")
+	q := &fantasy.TransactionQueryBuilder{}
+	types := fs.String("type", "", "only list the comma separated `types` e.g. add,drop,trade")
+	fs.StringVar(&q.TeamKey, "team", "", "only list transactions of the team with `team_key`")
+	fs.IntVar(&q.Start, "start", 0, "offset of the first transaction")
+	fs.IntVar(&q.Count, "count", 25, "maximum number of transactions")
+	keys, err := parseArgs(fs, args, "league_key")
+	if err != nil {
+		return err
+	}
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	q.LeagueQB = &fantasy.LeagueQueryBuilder{Keys: keys}
+	if *types != "" {
+		q.Types = strings.Split(*types, ",")
+	}
+	transactions, err := q.Get(client)
+	if err != nil {
+		return err
+	}
+
+	return a.print(transactionResult(transactions))
+}
+
+// transactionResult lists transactions with the players they move.
+func transactionResult(transactions []fantasy.Transaction) result {
+	r := result{header: []string{"KEY", "TYPE", "STATUS", "TIME", "PLAYERS"}}
+	for _, t := range transactions {
+		var moves []string
+		for _, p := range t.Players {
+			moves = append(moves, p.TransactionData.Type+" "+p.Name.Full)
+		}
+		r.rows = append(r.rows, []string{t.Key, t.Type, t.Status, t.Timestamp.String(), strings.Join(moves, ", ")})
+	}
+	return r
+}
+
+// itoa formats an id.
+func itoa(i int64) string {
+	return strconv.FormatInt(i, 10)
+}
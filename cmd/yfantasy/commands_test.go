@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/muswell/yahoo/fantasy"
+	"github.com/muswell/yahoo/fantasy/fantasytest"
+)
+
+// testApp returns an app sending requests to a fake server.
+func testApp(s *fantasytest.Server) (*app, *bytes.Buffer, *bytes.Buffer) {
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	a := &app{in: strings.NewReader(""), out: out, errOut: errOut}
+	a.client = func() (*http.Client, error) { return s.Client(), nil }
+	return a, out, errOut
+}
+
+func TestCommands(t *testing.T) {
+	s := fantasytest.NewServer(nil)
+	defer s.Close()
+
+	tests := []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{"whoami"}, []string{"GUID", "GUID1"}},
+		{[]string{"games"}, []string{"KEY  CODE  NAME      SEASON", "357  mlb   Baseball  2016"}},
+		{[]string{"leagues", "-game", "mlb"}, []string{"357.l.86753  Fantasytest League  mlb   2016    2      roto     postdraft"}},
+		{[]string{"standings", "357.l.86753"}, []string{"1     Bochy's Bullpen", "2     Halo Effect"}},
+		{[]string{"roster", "357.l.86753.t.1"}, []string{"C         Buster Posey       357.p.8967", "SP        Madison Bumgarner"}},
+		{[]string{"players", "-status", "FA", "357.l.86753"}, []string{"357.p.9573  Mookie Betts  BOS   OF"}},
+		{[]string{"-format", "csv", "players", "-position", "SP", "357.l.86753"}, []string{"KEY,NAME,TEAM,POSITIONS,STATUS", "357.p.9105,Madison Bumgarner,SF,SP,", "357.p.9116,Clayton Kershaw,LAD,SP,"}},
+		{[]string{"-format", "json", "whoami"}, []string{`"guid": "GUID1"`}},
+	}
+
+	for _, test := range tests {
+		a, out, errOut := testApp(s)
+		if status := a.run(test.args); status != 0 {
+			t.Errorf("%v exited with %d: %s", test.args, status, errOut)
+			continue
+		}
+		for _, e := range test.expected {
+			if !strings.Contains(out.String(), e) {
+				t.Errorf("%v output does not contain %q:\n%s", test.args, e, out)
+			}
+		}
+	}
+}
+
+func TestTransactionsCommand(t *testing.T) {
+	s := fantasytest.NewServer(nil)
+	defer s.Close()
+
+	add := &fantasy.AddDrop{LeagueKey: "357.l.86753", TeamKey: "357.l.86753.t.1", AddPlayerKey: "357.p.9573", DropPlayerKey: "357.p.9105"}
+	if _, err := fantasy.Send(s.Client(), add); err != nil {
+		t.Fatalf("Unexpected add/drop error: %v", err)
+	}
+
+	a, out, errOut := testApp(s)
+	if status := a.run([]string{"transactions", "-type", "add", "357.l.86753"}); status != 0 {
+		t.Fatalf("transactions exited with %d: %s", status, errOut)
+	}
+	for _, e := range []string{"357.l.86753.tr.1", "successful", "add Mookie Betts, drop Madison Bumgarner"} {
+		if !strings.Contains(out.String(), e) {
+			t.Errorf("transactions output does not contain %q:\n%s", e, out)
+		}
+	}
+}
+
+func TestCommandErrors(t *testing.T) {
+	s := fantasytest.NewServer(nil)
+	defer s.Close()
+
+	tests := []struct {
+		args   []string
+		status int
+		output string
+	}{
+		{[]string{}, 2, "Usage: yfantasy"},
+		{[]string{"draft"}, 2, `unknown command "draft"`},
+		{[]string{"-format", "xml", "whoami"}, 2, `unknown format "xml"`},
+		{[]string{"roster"}, 2, "Usage: yfantasy roster team_key"},
+		{[]string{"players", "-bogus", "357.l.86753"}, 2, "flag provided but not defined"},
+		{[]string{"roster", "357.l.86753"}, 1, "yfantasy roster:"},
+	}
+
+	for _, test := range tests {
+		a, _, errOut := testApp(s)
+		if status := a.run(test.args); status != test.status || !strings.Contains(errOut.String(), test.output) {
+			t.Errorf("%v exited with %d, expected %d with %q:\n%s", test.args, status, test.status, test.output, errOut)
+		}
+	}
+}
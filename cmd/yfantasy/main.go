@@ -0,0 +1,234 @@
+// Command yfantasy queries the Yahoo fantasy sports API from the command line.
+//
+// Usage:
+//
+//	yfantasy [-config file] [-token file] [-format table|json|csv] command [arguments]
+//
+// The application credentials are read like yahoo.LoadConfig, from the -config file, $YAHOO_CONFIG
+// and the YAHOO_ environment variables. Run yfantasy login once to authorize the tool, the token is
+// saved to the -token file and refreshed as needed.
+//
+// Commands:
+//
+//	login                      authorize yfantasy with your Yahoo account
+//	whoami                     print the guid of the logged in user
+//	games [-available]         list your games, or every available game
+//	leagues [-game code]       list your leagues
+//	standings league_key       list the teams of a league by rank
+//	roster team_key            list the players of a team and their positions
+//	players league_key         list the players of a league, filtered by status, position or name
+//	transactions league_key    list the transactions of a league
+//	lineup set team_key player_key=position ...
+//	                           move players of your team to new positions
+//	add team_key player_key    add a free agent to your team, -drop a player to make room
+//	drop team_key player_key   drop a player from your team
+//	claim team_key player_key  claim a player on waivers, -faab to bid
+//	trade propose trader_team_key tradee_team_key
+//	                           propose a trade, -give and -get list the players
+//	trade accept|reject transaction_key
+//	                           answer a trade proposed to your team
+//
+// Write commands print the request and ask for confirmation before sending it, -yes skips the question
+// and -dry-run prints the endpoint and xml body without sending anything. They need a token with the
+// fspt-w scope.
+//
+// Run yfantasy command -h for the flags of a command.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/muswell/yahoo"
+)
+
+// command is a yfantasy subcommand, run with the arguments following its name.
+type command struct {
+	usage string
+	run   func(a *app, args []string) error
+}
+
+// commands are the subcommands by name.
+var commands = map[string]command{}
+
+func init() {
+	commands["login"] = command{"login [-oob] [-pkce]", (*app).login}
+	commands["whoami"] = command{"whoami", (*app).whoami}
+	commands["games"] = command{"games [-available]", (*app).games}
+	commands["leagues"] = command{"leagues [-game code]", (*app).leagues}
+	commands["standings"] = command{"standings league_key", (*app).standings}
+	commands["roster"] = command{"roster team_key", (*app).roster}
+	commands["players"] = command{"players [-status A|FA|W|T] [-position pos] [-search name] [-sort order] [-start n] [-count n] league_key", (*app).players}
+	commands["transactions"] = command{"transactions [-type types] [-team team_key] [-count n] league_key", (*app).transactions}
+	commands["lineup"] = command{"lineup set [-date yyyy-mm-dd | -week n] [-dry-run] [-yes] team_key player_key=position ...", (*app).lineup}
+	commands["add"] = command{"add [-drop player_key] [-dry-run] [-yes] team_key player_key", (*app).add}
+	commands["drop"] = command{"drop [-dry-run] [-yes] team_key player_key", (*app).drop}
+	commands["claim"] = command{"claim [-faab bid] [-drop player_key] [-dry-run] [-yes] team_key player_key", (*app).claim}
+	commands["trade"] = command{"trade propose -give player_keys -get player_keys [-note text] [-dry-run] [-yes] trader_team_key tradee_team_key\n  trade accept|reject [-note text] [-dry-run] [-yes] transaction_key", (*app).trade}
+}
+
+// app holds the global flags and the streams of a run.
+type app struct {
+	config string
+	token  string
+	format string
+
+	ctx    context.Context
+	in     io.Reader
+	out    io.Writer
+	errOut io.Writer
+
+	// client returns the client api requests are sent with, it is replaced in tests.
+	client func() (*http.Client, error)
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run parses the global flags and runs a command, it returns the exit status.
+func run(args []string, in io.Reader, out, errOut io.Writer) int {
+	a := &app{ctx: context.Background(), in: in, out: out, errOut: errOut}
+	a.client = a.tokenClient
+	return a.run(args)
+}
+
+func (a *app) run(args []string) int {
+	fs := flag.NewFlagSet("yfantasy", flag.ContinueOnError)
+	fs.SetOutput(a.errOut)
+	fs.StringVar(&a.config, "config", "", "the application config `file`, $YAHOO_CONFIG by default")
+	fs.StringVar(&a.token, "token", defaultTokenPath(), "the `file` the token is saved in")
+	fs.StringVar(&a.format, "format", "table", "the output `format`: table, json or csv")
+	fs.Usage = func() { a.usage(fs) }
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if fs.NArg() == 0 {
+		a.usage(fs)
+		return 2
+	}
+	if !validFormat(a.format) {
+		fmt.Fprintf(a.errOut, "yfantasy: unknown format %q\n", a.format)
+		return 2
+	}
+
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(a.errOut, "yfantasy: unknown command %q\n", fs.Arg(0))
+		a.usage(fs)
+		return 2
+	}
+
+	if err := cmd.run(a, fs.Args()[1:]); err == errUsage {
+		return 2
+	} else if err != nil {
+		fmt.Fprintf(a.errOut, "yfantasy %s: %v\n", fs.Arg(0), err)
+		return 1
+	}
+	return 0
+}
+
+// usage prints the global flags and the commands.
+func (a *app) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(a.errOut, "Usage: yfantasy [flags] command [arguments]")
+	fmt.Fprintln(a.errOut, "\nFlags:")
+	fs.PrintDefaults()
+	fmt.Fprintln(a.errOut, "\nCommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintln(a.errOut, "  "+commands[name].usage)
+	}
+}
+
+// flags returns the flag set of a command.
+func (a *app) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.errOut)
+	fs.Usage = func() {
+		fmt.Fprintln(a.errOut, "Usage: yfantasy "+commands[name].usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// errUsage is returned by commands called with bad flags or arguments once their usage has been printed.
+var errUsage = errors.New("Bad usage")
+
+// parseArgs parses a command's flags and checks it was given exactly the named arguments.
+func parseArgs(fs *flag.FlagSet, args []string, names ...string) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, errUsage
+	}
+	if fs.NArg() != len(names) {
+		fs.Usage()
+		return nil, errUsage
+	}
+	return fs.Args(), nil
+}
+
+// defaultTokenPath is yfantasy/token.json in the user's config directory.
+func defaultTokenPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "yfantasy-token.json"
+	}
+	return filepath.Join(dir, "yfantasy", "token.json")
+}
+
+// tokenClient returns a client authorized with the saved token.
+func (a *app) tokenClient() (*http.Client, error) {
+	conf, err := yahoo.LoadConfig(a.config)
+	if err != nil {
+		return nil, err
+	}
+
+	ts := yahoo.NewTokenSource(a.ctx, conf, yahoo.NewFileTokenStore(a.token))
+	ts.OnSaveError = func(err error) { fmt.Fprintln(a.errOut, "yfantasy: "+err.Error()) }
+	if _, err := ts.Token(); err == yahoo.ErrNoToken {
+		return nil, errors.New("Not logged in, run yfantasy login")
+	} else if err != nil {
+		return nil, err
+	}
+	return ts.Client(), nil
+}
+
+// login authorizes yfantasy and saves the token.
+func (a *app) login(args []string) error {
+	fs := a.flags("login")
+	l := yahoo.LocalLogin{Open: yahoo.OpenBrowser, Out: a.errOut, In: a.in}
+	fs.BoolVar(&l.OOB, "oob", false, "paste the code instead of listening for the callback")
+	fs.BoolVar(&l.PKCE, "pkce", false, "protect the code exchange with a proof key")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	conf, err := yahoo.LoadConfig(a.config)
+	if err != nil {
+		return err
+	}
+	t, err := l.Login(a.ctx, conf)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(a.token), 0700); err != nil {
+		return err
+	}
+	if err := yahoo.NewFileTokenStore(a.token).Save(t); err != nil {
+		return err
+	}
+	fmt.Fprintln(a.errOut, "Logged in, the token is saved in "+a.token)
+	return nil
+}
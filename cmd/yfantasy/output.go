@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"text/tabwriter"
+)
+
+// result is the output of a command, a row per item with a cell per header column.
+type result struct {
+	header []string
+	rows   [][]string
+}
+
+// validFormat reports whether yfantasy can print format.
+func validFormat(format string) bool {
+	return format == "table" || format == "json" || format == "csv"
+}
+
+// print writes r in the app's format: aligned columns, csv with a header line,
+// or a json array with an object per row keyed by the lower case column names.
+func (a *app) print(r result) error {
+	switch a.format {
+	case "json":
+		records := make([]map[string]string, len(r.rows))
+		for i, row := range r.rows {
+			records[i] = map[string]string{}
+			for j, cell := range row {
+				records[i][strings.ToLower(r.header[j])] = cell
+			}
+		}
+		data, err := json.MarshalIndent(records, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = a.out.Write(append(data, '\n'))
+		return err
+
+	case "csv":
+		w := csv.NewWriter(a.out)
+		w.Write(r.header)
+		w.WriteAll(r.rows)
+		return w.Error()
+	}
+
+	w := tabwriter.NewWriter(a.out, 0, 4, 2, ' ', 0)
+	w.Write([]byte(strings.Join(r.header, "\t") + "\n"))
+	for _, row := range r.rows {
+		w.Write([]byte(strings.Join(row, "\t") + "\n"))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPrint(t *testing.T) {
+	r := result{
+		header: []string{"KEY", "NAME"},
+		rows:   [][]string{{"357.p.8967", "Buster Posey"}, {"357.p.9105", `Madison "MadBum" Bumgarner`}},
+	}
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{"table", "KEY         NAME\n357.p.8967  Buster Posey\n357.p.9105  Madison \"MadBum\" Bumgarner\n"},
+		{"csv", "KEY,NAME\n357.p.8967,Buster Posey\n357.p.9105,\"Madison \"\"MadBum\"\" Bumgarner\"\n"},
+		{"json", "[\n  {\n    \"key\": \"357.p.8967\",\n    \"name\": \"Buster Posey\"\n  },\n  {\n    \"key\": \"357.p.9105\",\n    \"name\": \"Madison \\\"MadBum\\\" Bumgarner\"\n  }\n]\n"},
+	}
+
+	for _, test := range tests {
+		out := &bytes.Buffer{}
+		a := &app{format: test.format, out: out}
+		if err := a.print(r); err != nil {
+			t.Errorf("Unexpected %s error: %v", test.format, err)
+		}
+		if out.String() != test.expected {
+			t.Errorf("%s output is\n%s\nexpected\n%s", test.format, out, test.expected)
+		}
+	}
+
+	out := &bytes.Buffer{}
+	if err := (&app{format: "json", out: out}).print(result{header: []string{"KEY"}}); err != nil || out.String() != "[]\n" {
+		t.Errorf("Empty json output is %q, %v", out, err)
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/muswell/yahoo/fantasy"
+)
+
+// errCancelled is returned when the user does not confirm a write request.
+var errCancelled = errors.New("Cancelled, nothing was sent")
+
+// writeOptions are the flags shared by every write command.
+type writeOptions struct {
+	dryRun bool
+	yes    bool
+}
+
+// writeFlags adds the -dry-run and -yes flags to a write command.
+func writeFlags(fs *flag.FlagSet) *writeOptions {
+	o := &writeOptions{}
+	fs.BoolVar(&o.dryRun, "dry-run", false, "print the endpoint and xml body without sending them")
+	fs.BoolVar(&o.yes, "yes", false, "send without asking for confirmation")
+	return o
+}
+
+// send prints a write request and sends it once the user confirms, the changed transaction is printed.
+func (a *app) send(w fantasy.WriteRequest, o *writeOptions) error {
+	body, err := w.Body()
+	if err != nil {
+		return err
+	}
+
+	if o.dryRun {
+		fmt.Fprintf(a.out, "%s %s\n\n%s\n", w.Method(), w.Url(), body)
+		return nil
+	}
+
+	client, err := a.client()
+	if err != nil {
+		return err
+	}
+
+	if !o.yes {
+		fmt.Fprintf(a.errOut, "%s %s\n\n%s\n\nSend? [y/N] ", w.Method(), w.Url(), body)
+		answer, _ := bufio.NewReader(a.in).ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			return errCancelled
+		}
+	}
+
+	result, err := fantasy.Send(client, w)
+	if err != nil {
+		return err
+	}
+	if result.Transaction == nil {
+		fmt.Fprintln(a.errOut, "Sent")
+		return nil
+	}
+	return a.print(transactionResult([]fantasy.Transaction{*result.Transaction}))
+}
+
+// leagueOf returns the key of the league of a team.
+func leagueOf(teamKey string) (string, error) {
+	k, err := fantasy.ParseTeamKey(teamKey)
+	if err != nil {
+		return "", err
+	}
+	return k.LeagueKey().String(), nil
+}
+
+// subcommand runs the subcommand named by the first argument, e.g. set of lineup set.
+func (a *app) subcommand(name string, args []string, subs map[string]func(*app, []string) error) error {
+	if len(args) > 0 {
+		if run, ok := subs[args[0]]; ok {
+			return run(a, args[1:])
+		}
+	}
+	fmt.Fprintln(a.errOut, "Usage: yfantasy "+commands[name].usage)
+	return errUsage
+}
+
+// lineup runs the lineup subcommands.
+func (a *app) lineup(args []string) error {
+	return a.subcommand("lineup", args, map[string]func(*app, []string) error{"set": (*app).lineupSet})
+}
+
+// lineupSet moves players of a team to new positions.
+func (a *app) lineupSet(args []string) error {
+	fs := a.flags("lineup")
+	o := writeFlags(fs)
+	edit := &fantasy.RosterEdit{}
+	fs.StringVar(&edit.Date, "date", "", "the `yyyy-mm-dd` date of the lineup in leagues with daily lineups, today by default")
+	fs.IntVar(&edit.Week, "week", 0, "the `week` of the lineup in leagues with weekly lineups")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return errUsage
+	}
+
+	edit.TeamKey = fs.Arg(0)
+	for _, arg := range fs.Args()[1:] {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return fmt.Errorf("Bad player position %q, expected player_key=position", arg)
+		}
+		edit.Players = append(edit.Players, fantasy.RosterPosition{PlayerKey: kv[0], Position: kv[1]})
+	}
+	if edit.Week == 0 && edit.Date == "" {
+		edit.Date = time.Now().Format(fantasy.DateFormat)
+	}
+	return a.send(edit, o)
+}
+
+// add adds a free agent to a team, optionally dropping a player.
+func (a *app) add(args []string) error {
+	return a.addDrop("add", args)
+}
+
+// claim puts in a waiver claim, optionally bidding FAAB and dropping a player.
+func (a *app) claim(args []string) error {
+	return a.addDrop("claim", args)
+}
+
+// addDrop runs add and claim, which only differ by the FAAB bid of a claim.
+func (a *app) addDrop(name string, args []string) error {
+	fs := a.flags(name)
+	o := writeFlags(fs)
+	drop := fs.String("drop", "", "the `player_key` of a player to drop in the same transaction")
+	var faab *int
+	if name == "claim" {
+		faab = fs.Int("faab", -1, "the FAAB `bid` in leagues which use FAAB")
+	}
+	keys, err := parseArgs(fs, args, "team_key", "player_key")
+	if err != nil {
+		return err
+	}
+
+	league, err := leagueOf(keys[0])
+	if err != nil {
+		return err
+	}
+	w := &fantasy.AddDrop{LeagueKey: league, TeamKey: keys[0], AddPlayerKey: keys[1], DropPlayerKey: *drop}
+	if faab != nil && *faab >= 0 {
+		w.FAABBid = faab
+	}
+	return a.send(w, o)
+}
+
+// drop drops a player from a team.
+func (a *app) drop(args []string) error {
+	fs := a.flags("drop")
+	o := writeFlags(fs)
+	keys, err := parseArgs(fs, args, "team_key", "player_key")
+	if err != nil {
+		return err
+	}
+
+	league, err := leagueOf(keys[0])
+	if err != nil {
+		return err
+	}
+	return a.send(&fantasy.AddDrop{LeagueKey: league, TeamKey: keys[0], DropPlayerKey: keys[1]}, o)
+}
+
+// trade runs the trade subcommands.
+func (a *app) trade(args []string) error {
+	return a.subcommand("trade", args, map[string]func(*app, []string) error{
+		"propose": (*app).tradePropose,
+		"accept":  func(a *app, args []string) error { return a.tradeRespond(fantasy.TradeAccept, args) },
+		"reject":  func(a *app, args []string) error { return a.tradeRespond(fantasy.TradeReject, args) },
+	})
+}
+
+// tradePropose proposes a trade to another team.
+func (a *app) tradePropose(args []string) error {
+	fs := a.flags("trade")
+	o := writeFlags(fs)
+	give := fs.String("give", "", "the comma separated `player_keys` your team gives up")
+	get := fs.String("get", "", "the comma separated `player_keys` your team receives")
+	note := fs.String("note", "", "a `message` to the other manager")
+	keys, err := parseArgs(fs, args, "trader_team_key", "tradee_team_key")
+	if err != nil {
+		return err
+	}
+
+	league, err := leagueOf(keys[0])
+	if err != nil {
+		return err
+	}
+	return a.send(&fantasy.TradeProposal{
+		LeagueKey:        league,
+		TraderTeamKey:    keys[0],
+		TradeeTeamKey:    keys[1],
+		TraderPlayerKeys: splitList(*give),
+		TradeePlayerKeys: splitList(*get),
+		Note:             *note,
+	}, o)
+}
+
+// tradeRespond accepts or rejects a trade proposed to the user's team.
+func (a *app) tradeRespond(action string, args []string) error {
+	fs := a.flags("trade")
+	o := writeFlags(fs)
+	note := fs.String("note", "", "a `message` to the other manager")
+	keys, err := parseArgs(fs, args, "transaction_key")
+	if err != nil {
+		return err
+	}
+	return a.send(&fantasy.TradeResponse{TransactionKey: keys[0], Action: action, Note: *note}, o)
+}
+
+// splitList splits a comma separated flag value, an empty value is an empty list.
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/muswell/yahoo/fantasy/fantasytest"
+)
+
+func TestWriteCommands(t *testing.T) {
+	s := fantasytest.NewServer(nil)
+	defer s.Close()
+
+	tests := []struct {
+		args     []string
+		input    string
+		expected []string
+	}{
+		{[]string{"add", "-drop", "357.p.9105", "357.l.86753.t.1", "357.p.9573"}, "y\n", []string{"357.l.86753.tr.1", "add/drop", "successful", "add Mookie Betts, drop Madison Bumgarner"}},
+		{[]string{"claim", "-faab", "12", "-yes", "357.l.86753.t.1", "357.p.8875"}, "", []string{"357.l.86753.w.c.2", "waiver", "pending", "add Kenley Jansen"}},
+		{[]string{"drop", "-yes", "357.l.86753.t.1", "357.p.9573"}, "", []string{"357.l.86753.tr.3", "drop", "successful", "drop Mookie Betts"}},
+		{[]string{"lineup", "set", "-date", "2016-04-10", "-yes", "357.l.86753.t.1", "357.p.8967=1B"}, "", nil},
+		{[]string{"-format", "csv", "trade", "propose", "-give", "357.p.8967", "-get", "357.p.8658", "-note", "Fair?", "-yes", "357.l.86753.t.1", "357.l.86753.t.2"}, "", []string{"357.l.86753.pt.4,pending_trade,proposed"}},
+	}
+
+	for _, test := range tests {
+		a, out, errOut := testApp(s)
+		a.in = strings.NewReader(test.input)
+		if status := a.run(test.args); status != 0 {
+			t.Errorf("%v exited with %d: %s", test.args, status, errOut)
+			continue
+		}
+		for _, e := range test.expected {
+			if !strings.Contains(out.String(), e) {
+				t.Errorf("%v output does not contain %q:\n%s", test.args, e, out)
+			}
+		}
+	}
+
+	s.Lock()
+	team := s.Model.Leagues[0].Teams[0]
+	if len(team.Players) != 1 || team.Players[0].Key != "357.p.8967" || team.Players[0].SelectedPosition.Position != "1B" {
+		t.Errorf("The write commands were not applied, got %+v", team.Players)
+	}
+	s.Model.Guid = "GUID2"
+	s.Unlock()
+
+	a, out, errOut := testApp(s)
+	if status := a.run([]string{"trade", "accept", "-yes", "357.l.86753.pt.4"}); status != 0 || !strings.Contains(out.String(), "successful") {
+		t.Errorf("trade accept exited with %d: %s%s", status, out, errOut)
+	}
+}
+
+func TestWriteConfirmation(t *testing.T) {
+	s := fantasytest.NewServer(nil)
+	defer s.Close()
+
+	for _, input := range []string{"n\n", "\n", ""} {
+		a, _, errOut := testApp(s)
+		a.in = strings.NewReader(input)
+		status := a.run([]string{"add", "357.l.86753.t.1", "357.p.9573"})
+		if status != 1 || !strings.Contains(errOut.String(), "Send? [y/N]") || !strings.Contains(errOut.String(), "Cancelled") {
+			t.Errorf("Answering %q exited with %d:\n%s", input, status, errOut)
+		}
+	}
+
+	a, out, errOut := testApp(s)
+	if status := a.run([]string{"trade", "reject", "-dry-run", "-note", "No thanks", "357.l.86753.pt.1"}); status != 0 {
+		t.Fatalf("dry run exited with %d: %s", status, errOut)
+	}
+	for _, e := range []string{"PUT https://fantasysports.yahooapis.com/fantasy/v2/transaction/357.l.86753.pt.1\n", "<action>reject</action>", "<trade_note>No thanks</trade_note>"} {
+		if !strings.Contains(out.String(), e) {
+			t.Errorf("dry run output does not contain %q:\n%s", e, out)
+		}
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	if len(s.Model.Leagues[0].Transactions) != 0 {
+		t.Errorf("A cancelled request was sent %+v", s.Model.Leagues[0].Transactions)
+	}
+}
+
+func TestWriteCommandErrors(t *testing.T) {
+	s := fantasytest.NewServer(nil)
+	defer s.Close()
+
+	tests := []struct {
+		args   []string
+		status int
+		output string
+	}{
+		{[]string{"lineup"}, 2, "Usage: yfantasy lineup set"},
+		{[]string{"trade", "counter"}, 2, "Usage: yfantasy trade propose"},
+		{[]string{"lineup", "set", "357.l.86753.t.1"}, 2, "Usage: yfantasy lineup set"},
+		{[]string{"lineup", "set", "357.l.86753.t.1", "357.p.8967"}, 1, "expected player_key=position"},
+		{[]string{"add", "357.l.86753", "357.p.9573"}, 1, "yfantasy add:"},
+		{[]string{"trade", "propose", "-give", "357.p.8967", "357.l.86753.t.1", "357.l.86753.t.2"}, 1, "TradeProposal requires players from both teams"},
+		{[]string{"drop", "-yes", "357.l.86753.t.2", "357.p.8658"}, 1, "403"},
+	}
+
+	for _, test := range tests {
+		a, _, errOut := testApp(s)
+		if status := a.run(test.args); status != test.status || !strings.Contains(errOut.String(), test.output) {
+			t.Errorf("%v exited with %d, expected %d with %q:\n%s", test.args, status, test.status, test.output, errOut)
+		}
+	}
+}
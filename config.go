@@ -0,0 +1,414 @@
+package yahoo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/muswell/yahoo/fantasy"
+	"golang.org/x/oauth2"
+)
+
+// Fantasy sports scopes, an application is granted one of them.
+const (
+	// ScopeRead grants read only access to the fantasy API.
+	ScopeRead = "fspt-r"
+	// ScopeWrite grants read and write access to the fantasy API, it is needed to edit rosters and make transactions.
+	// It is the scope fantasy.Send checks for.
+	ScopeWrite = fantasy.WriteScope
+)
+
+// OpenID Connect scopes, requested alongside ScopeOpenID.
+const (
+	// ScopeProfile adds the user's name and picture to the ID token and userinfo.
+	ScopeProfile = "profile"
+	// ScopeEmail adds the user's email address to the ID token and userinfo.
+	ScopeEmail = "email"
+)
+
+// Environment variables read by LoadSettings.
+const (
+	EnvClientID     = "YAHOO_CLIENT_ID"
+	EnvClientSecret = "YAHOO_CLIENT_SECRET"
+	EnvRedirectURL  = "YAHOO_REDIRECT_URL"
+	// EnvScopes is a comma or space separated list of scopes.
+	EnvScopes = "YAHOO_SCOPES"
+	// EnvConfigFile is the path of a config file read before the other variables.
+	EnvConfigFile = "YAHOO_CONFIG"
+)
+
+// scopeAliases lets config files name the fantasy scopes by their access.
+var scopeAliases = map[string]string{
+	"read":       ScopeRead,
+	"read-write": ScopeWrite,
+	"write":      ScopeWrite,
+}
+
+// knownScopes are the scopes accepted by Validate.
+var knownScopes = map[string]bool{
+	ScopeRead:    true,
+	ScopeWrite:   true,
+	ScopeOpenID:  true,
+	ScopeProfile: true,
+	ScopeEmail:   true,
+}
+
+// Settings are the application credentials shared by every tool, loaded from the environment or a config file.
+type Settings struct {
+	ClientID     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret"`
+	RedirectURL  string   `json:"redirect_url"`
+	Scopes       []string `json:"scopes"`
+}
+
+// ConfigError lists every problem found validating Settings.
+type ConfigError struct {
+	// Source is the file the settings were loaded from, empty for the environment.
+	Source   string
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	source := "environment"
+	if e.Source != "" {
+		source = e.Source
+	}
+	return fmt.Sprintf("Invalid Yahoo config from %s: %s", source, strings.Join(e.Problems, "; "))
+}
+
+// LoadSettings reads the file at path, or at $YAHOO_CONFIG when path is empty,
+// then overrides its values with any YAHOO_ environment variables and validates the result.
+// Without a file the settings come from the environment alone.
+func LoadSettings(path string) (*Settings, error) {
+	if path == "" {
+		path = os.Getenv(EnvConfigFile)
+	}
+
+	s := &Settings{}
+	if path != "" {
+		var err error
+		if s, err = ReadSettingsFile(path); err != nil {
+			return nil, err
+		}
+	}
+	s.applyEnv()
+
+	if err := s.Validate(); err != nil {
+		err.(*ConfigError).Source = path
+		return nil, err
+	}
+	return s, nil
+}
+
+// LoadConfig loads the settings like LoadSettings and returns their oauth2.Config.
+func LoadConfig(path string) (*oauth2.Config, error) {
+	s, err := LoadSettings(path)
+	if err != nil {
+		return nil, err
+	}
+	return s.Config(), nil
+}
+
+// ReadSettingsFile reads settings from a json, yaml or toml file, chosen by its extension.
+// Yaml and toml files may only hold flat keys with string or list values, optionally inside a yahoo table.
+// The settings are not validated.
+func ReadSettingsFile(path string) (*Settings, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Settings{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		err = json.Unmarshal(data, s)
+	case ".yaml", ".yml":
+		err = s.setValues(parseYAML(data))
+	case ".toml":
+		err = s.setValues(parseTOML(data))
+	default:
+		err = fmt.Errorf("unsupported file type %s", ext)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Could not read config file %s: %v", path, err)
+	}
+	return s, nil
+}
+
+// Validate checks the settings and returns a *ConfigError listing every problem.
+// Scope aliases (read, write) are replaced by their scope.
+func (s *Settings) Validate() error {
+	var problems []string
+
+	if s.ClientID == "" {
+		problems = append(problems, "client_id is required ("+EnvClientID+")")
+	}
+
+	if s.RedirectURL != "" && s.RedirectURL != "oob" {
+		u, err := url.Parse(s.RedirectURL)
+		if err != nil || !u.IsAbs() || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("redirect_url %q must be an absolute url or oob", s.RedirectURL))
+		}
+	}
+
+	fantasy := 0
+	for i, scope := range s.Scopes {
+		if alias, ok := scopeAliases[strings.ToLower(scope)]; ok {
+			scope = alias
+			s.Scopes[i] = alias
+		}
+		if !knownScopes[scope] {
+			problems = append(problems, fmt.Sprintf("unknown scope %q", scope))
+		}
+		if scope == ScopeRead || scope == ScopeWrite {
+			fantasy++
+		}
+	}
+	if fantasy > 1 {
+		problems = append(problems, "only one of the "+ScopeRead+" and "+ScopeWrite+" scopes may be requested")
+	}
+
+	if len(problems) > 0 {
+		return &ConfigError{Problems: problems}
+	}
+	return nil
+}
+
+// Config creates the oauth2.Config of the settings with NewConfig.
+func (s *Settings) Config() *oauth2.Config {
+	return NewConfig(s.ClientID, s.ClientSecret, s.RedirectURL, append([]string{}, s.Scopes...)...)
+}
+
+// ApplyEnv overrides the settings with every YAHOO_ environment variable which is set.
+func (s *Settings) applyEnv() {
+	if v := os.Getenv(EnvClientID); v != "" {
+		s.ClientID = v
+	}
+	if v := os.Getenv(EnvClientSecret); v != "" {
+		s.ClientSecret = v
+	}
+	if v := os.Getenv(EnvRedirectURL); v != "" {
+		s.RedirectURL = v
+	}
+	if v := os.Getenv(EnvScopes); v != "" {
+		s.Scopes = splitScopes(v)
+	}
+}
+
+// SetValues sets the settings from the values of a yaml or toml file.
+func (s *Settings) setValues(values map[string][]string, err error) error {
+	if err != nil {
+		return err
+	}
+
+	for k, v := range values {
+		switch k {
+		case "client_id":
+			s.ClientID = strings.Join(v, "")
+		case "client_secret":
+			s.ClientSecret = strings.Join(v, "")
+		case "redirect_url":
+			s.RedirectURL = strings.Join(v, "")
+		case "scopes":
+			s.Scopes = nil
+			for _, scope := range v {
+				s.Scopes = append(s.Scopes, splitScopes(scope)...)
+			}
+		default:
+			return fmt.Errorf("unknown key %s", k)
+		}
+	}
+	return nil
+}
+
+// SplitScopes splits a comma or space separated list of scopes.
+func splitScopes(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
+// ParseYAML reads a flat yaml mapping of strings, flow lists ([a, b]) and block lists (- a).
+// A top level yahoo mapping is flattened into its keys, other nested mappings are an error.
+func parseYAML(data []byte) (map[string][]string, error) {
+	values := map[string][]string{}
+	// list is the key of the block list being read and listIndent the indentation of that key.
+	list, listIndent := "", 0
+	// inYahoo is set within the yahoo mapping, its keys are indented by yahooIndent once the first is read.
+	inYahoo, yahooIndent := false, 0
+
+	for n, line := range strings.Split(string(data), "\n") {
+		line = stripComment(line)
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+
+		if strings.HasPrefix(trimmed, "- ") {
+			if list == "" || indent < listIndent {
+				return nil, fmt.Errorf("line %d: list item outside of a list", n+1)
+			}
+			v, err := unquote(strings.TrimSpace(trimmed[2:]))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", n+1, err)
+			}
+			values[list] = append(values[list], v)
+			continue
+		}
+
+		i := strings.Index(trimmed, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("line %d: expected key: value", n+1)
+		}
+		key, value := strings.TrimSpace(trimmed[:i]), strings.TrimSpace(trimmed[i+1:])
+		list = ""
+
+		if indent == 0 {
+			inYahoo = false
+			if key == "yahoo" && value == "" {
+				inYahoo, yahooIndent = true, 0
+				continue
+			}
+		} else {
+			if inYahoo && yahooIndent == 0 {
+				yahooIndent = indent
+			}
+			if !inYahoo || indent != yahooIndent {
+				return nil, fmt.Errorf("line %d: nested mapping %s is not supported", n+1, key)
+			}
+		}
+
+		if value == "" {
+			// the start of a block list.
+			list, listIndent = key, indent
+			values[key] = nil
+			continue
+		}
+
+		v, err := parseValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n+1, err)
+		}
+		values[key] = v
+	}
+	return values, nil
+}
+
+// ParseTOML reads flat toml key = value pairs of strings and arrays of strings.
+// Keys in a [yahoo] table are read as top level keys.
+func parseTOML(data []byte) (map[string][]string, error) {
+	values := map[string][]string{}
+
+	for n, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimSpace(stripComment(line))
+		if trimmed == "" {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "[") && !strings.Contains(trimmed, "=") {
+			if trimmed != "[yahoo]" {
+				return nil, fmt.Errorf("line %d: unsupported table %s", n+1, trimmed)
+			}
+			continue
+		}
+
+		i := strings.Index(trimmed, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("line %d: expected key = value", n+1)
+		}
+
+		v, err := parseValue(strings.TrimSpace(trimmed[i+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n+1, err)
+		}
+		values[strings.TrimSpace(trimmed[:i])] = v
+	}
+	return values, nil
+}
+
+// ParseValue reads a quoted or bare string or a [list, of, strings].
+func parseValue(value string) ([]string, error) {
+	if !strings.HasPrefix(value, "[") {
+		v, err := unquote(value)
+		return []string{v}, err
+	}
+
+	if !strings.HasSuffix(value, "]") {
+		return nil, fmt.Errorf("unterminated list %s", value)
+	}
+
+	var list []string
+	for _, item := range splitUnquoted(value[1:len(value)-1], ',') {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		v, err := unquote(item)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
+
+// SplitUnquoted splits s around each sep which is not inside quotes.
+func splitUnquoted(s string, sep byte) []string {
+	var parts []string
+	quote := byte(0)
+	begin := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == sep:
+			parts = append(parts, s[begin:i])
+			begin = i + 1
+		}
+	}
+	return append(parts, s[begin:])
+}
+
+// Unquote removes double or single quotes around a value.
+func unquote(value string) (string, error) {
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		return strconv.Unquote(value)
+	}
+	if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+		return value[1 : len(value)-1], nil
+	}
+	if strings.ContainsAny(value, `"'`) {
+		return "", fmt.Errorf("unbalanced quotes in %s", value)
+	}
+	return value, nil
+}
+
+// StripComment removes a # comment which is not inside quotes. Like yaml and toml a # only starts
+// a comment at the start of the line or after whitespace, so values such as abc#123 are kept whole.
+func stripComment(line string) string {
+	quote := byte(0)
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
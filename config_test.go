@@ -0,0 +1,211 @@
+package yahoo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// clearEnv unsets the YAHOO_ environment variables for the duration of the test.
+func clearEnv(t *testing.T) {
+	for _, name := range []string{EnvClientID, EnvClientSecret, EnvRedirectURL, EnvScopes, EnvConfigFile} {
+		t.Setenv(name, "")
+	}
+}
+
+func TestLoadSettingsFiles(t *testing.T) {
+	clearEnv(t)
+
+	dir, err := ioutil.TempDir("", "yahoo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"config.json": `{"client_id": "id", "client_secret": "secret", "redirect_url": "http://127.0.0.1:8080/callback", "scopes": ["fspt-w", "openid"]}`,
+		"config.yaml": `# shared tool config
+client_id: id
+client_secret: "secret"
+redirect_url: 'http://127.0.0.1:8080/callback' # loopback
+scopes:
+  - write
+  - openid
+`,
+		"config.yml": `yahoo:
+  client_id: id
+  client_secret: secret
+  redirect_url: http://127.0.0.1:8080/callback
+  scopes: [fspt-w, openid]
+`,
+		"config.toml": `[yahoo]
+client_id = "id"
+client_secret = "secret" # not shared
+redirect_url = "http://127.0.0.1:8080/callback"
+scopes = ["write", "openid"]
+`,
+	}
+
+	want := &Settings{ClientID: "id", ClientSecret: "secret", RedirectURL: "http://127.0.0.1:8080/callback", Scopes: []string{ScopeWrite, ScopeOpenID}}
+
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		s, err := LoadSettings(path)
+		if err != nil {
+			t.Errorf("Unexpected LoadSettings error for %s: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(s, want) {
+			t.Errorf("Incorrect settings from %s got %+v, expected %+v", name, s, want)
+		}
+	}
+
+	// the environment overrides the file, which can be named by YAHOO_CONFIG.
+	t.Setenv(EnvConfigFile, filepath.Join(dir, "config.toml"))
+	t.Setenv(EnvScopes, "fspt-r")
+	conf, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("Unexpected LoadConfig error: %v", err)
+	}
+	if conf.ClientID != "id" || conf.ClientSecret != "secret" || !reflect.DeepEqual(conf.Scopes, []string{ScopeRead}) {
+		t.Errorf("Incorrect config %+v", conf)
+	}
+
+	if _, err := LoadSettings(filepath.Join(dir, "config.ini")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+}
+
+func TestStripComment(t *testing.T) {
+	var tests = []struct {
+		line, want string
+	}{
+		{"# a comment", ""},
+		{"client_secret: abc#123", "client_secret: abc#123"},
+		{"client_secret: abc#123 # rotated", "client_secret: abc#123 "},
+		{"client_secret: abc\t# rotated", "client_secret: abc\t"},
+		{`client_secret = "abc # 123" # quoted`, `client_secret = "abc # 123" `},
+	}
+
+	for _, test := range tests {
+		if got := stripComment(test.line); got != test.want {
+			t.Errorf("stripComment(%q) = %q, want %q", test.line, got, test.want)
+		}
+	}
+}
+
+func TestLoadSettingsHashInValue(t *testing.T) {
+	clearEnv(t)
+
+	dir, err := ioutil.TempDir("", "yahoo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.yaml")
+	data := "client_id: id\nclient_secret: abc#123 # rotated in may\nredirect_url: oob\n"
+	if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := LoadSettings(path)
+	if err != nil {
+		t.Fatalf("Unexpected LoadSettings error: %v", err)
+	}
+	if s.ClientSecret != "abc#123" {
+		t.Errorf("LoadSettings read the client secret %q, expected abc#123", s.ClientSecret)
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	var tests = []struct {
+		value string
+		want  []string
+	}{
+		{`["a,b", c]`, []string{"a,b", "c"}},
+		{`['a, b', "c\", d"]`, []string{"a, b", `c", d`}},
+		{`[]`, nil},
+		{`"x,y"`, []string{"x,y"}},
+	}
+
+	for _, test := range tests {
+		got, err := parseValue(test.value)
+		if err != nil || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("parseValue(%s) = %q, %v, want %q", test.value, got, err, test.want)
+		}
+	}
+}
+
+func TestLoadSettingsEnv(t *testing.T) {
+	clearEnv(t)
+	t.Setenv(EnvClientID, "id")
+	t.Setenv(EnvScopes, "fspt-r, openid email")
+
+	conf, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("Unexpected LoadConfig error: %v", err)
+	}
+
+	if conf.ClientID != "id" || conf.RedirectURL != "oob" || !reflect.DeepEqual(conf.Scopes, []string{ScopeRead, ScopeOpenID, "email"}) {
+		t.Errorf("Incorrect config from the environment %+v", conf)
+	}
+}
+
+func TestSettingsValidate(t *testing.T) {
+	clearEnv(t)
+	t.Setenv(EnvRedirectURL, "callback")
+	t.Setenv(EnvScopes, "fspt-r,fspt-w,admin")
+
+	_, err := LoadSettings("")
+	cerr, ok := err.(*ConfigError)
+	if !ok {
+		t.Fatalf("Expected a *ConfigError got %v", err)
+	}
+
+	expected := []string{"client_id", "redirect_url", "admin", "only one"}
+	if len(cerr.Problems) != len(expected) {
+		t.Fatalf("Expected %d problems got %v", len(expected), cerr.Problems)
+	}
+	for i, e := range expected {
+		if !strings.Contains(cerr.Problems[i], e) {
+			t.Errorf("Problem %d %q does not mention %s", i, cerr.Problems[i], e)
+		}
+	}
+}
+
+func TestReadSettingsFileErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "yahoo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"bad.json":    `{"client_id": `,
+		"bad.yaml":    "client_id id",
+		"key.yaml":    "client: id",
+		"quote.toml":  `client_id = "id`,
+		"table.toml":  "[other]\nclient_id = \"id\"",
+		"config.conf": "client_id=id",
+		// nested mappings other than yahoo would overwrite the real keys.
+		"nested.yaml": "client_id: id\nother:\n  client_id: x\n",
+		"deep.yml":    "yahoo:\n  client_id: id\n  extra:\n    client_id: x\n",
+		"item.yaml":   "yahoo:\n  - fspt-w\n",
+	}
+
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		ioutil.WriteFile(path, []byte(data), 0600)
+		if _, err := ReadSettingsFile(path); err == nil {
+			t.Errorf("Expected a ReadSettingsFile error for %s", name)
+		}
+	}
+}
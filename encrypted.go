@@ -0,0 +1,190 @@
+package yahoo
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// TokenKeyEnv is the environment variable KeysFromEnv reads by default.
+// It holds comma separated base64 encoded AES keys, the first key is the current key.
+const TokenKeyEnv = "YAHOO_TOKEN_KEYS"
+
+// ErrDecrypt is returned when none of the keys of an EncryptedFileTokenStore can decrypt its file.
+var ErrDecrypt = errors.New("The token file could not be decrypted with any of the keys")
+
+// EncryptedFileTokenStore saves a token encrypted with AES-GCM in a file only readable by its owner.
+//
+// Keys are rotated by adding a new key to the front of Keys. The first key encrypts every save,
+// the others are only tried when decrypting. A token decrypted with an older key is immediately
+// saved again with the first key, so old keys can be dropped once every token has been loaded.
+type EncryptedFileTokenStore struct {
+	Path string
+	Keys [][]byte
+	// OnRotateError is called when a token decrypted with an older key can not be saved with the current key.
+	// The token is still returned, the rewrite is tried again on the next Load.
+	OnRotateError func(err error)
+}
+
+// NewEncryptedFileTokenStore creates an EncryptedFileTokenStore saving to path.
+// Every key must be 16, 24 or 32 bytes long to select AES-128, AES-192 or AES-256.
+func NewEncryptedFileTokenStore(path string, keys ...[]byte) (*EncryptedFileTokenStore, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("At least one encryption key is required")
+	}
+
+	for i, k := range keys {
+		if _, err := aes.NewCipher(k); err != nil {
+			return nil, fmt.Errorf("Bad encryption key %d: %v", i, err)
+		}
+	}
+
+	return &EncryptedFileTokenStore{Path: path, Keys: keys}, nil
+}
+
+// Load decrypts the token from the file, re-encrypting it with the current key when an older key was used.
+// A failed re-encryption is reported to OnRotateError and does not fail the load.
+func (s *EncryptedFileTokenStore) Load() (*oauth2.Token, error) {
+	data, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, ErrNoToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i, key := range s.Keys {
+		plain, err := decrypt(key, data)
+		if err != nil {
+			continue
+		}
+
+		t := &oauth2.Token{}
+		if err := json.Unmarshal(plain, t); err != nil {
+			return nil, fmt.Errorf("Could not read token file %s: %v", s.Path, err)
+		}
+
+		if i > 0 {
+			if err := s.Save(t); err != nil && s.OnRotateError != nil {
+				s.OnRotateError(fmt.Errorf("Could not re-encrypt token file %s: %v", s.Path, err))
+			}
+		}
+		return t, nil
+	}
+
+	return nil, ErrDecrypt
+}
+
+// Save encrypts t with the current key and atomically replaces the file.
+func (s *EncryptedFileTokenStore) Save(t *oauth2.Token) error {
+	if len(s.Keys) == 0 {
+		return errors.New("At least one encryption key is required")
+	}
+
+	plain, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	data, err := encrypt(s.Keys[0], plain)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.Path, data, 0600)
+}
+
+// Encrypt seals plain with AES-GCM, the random nonce is prepended to the result.
+func encrypt(key, plain []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+// Decrypt opens data sealed by encrypt.
+func decrypt(key, data []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+}
+
+// NewGCM creates an AES-GCM cipher for key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// KeysFromEnv reads comma separated base64 encoded keys from the environment variable name,
+// or from TokenKeyEnv when name is empty. The first key is the current key.
+func KeysFromEnv(name string) ([][]byte, error) {
+	if name == "" {
+		name = TokenKeyEnv
+	}
+
+	v := os.Getenv(name)
+	if v == "" {
+		return nil, fmt.Errorf("The environment variable %s is not set", name)
+	}
+	return parseKeys(strings.Split(v, ","))
+}
+
+// KeysFromFile reads base64 encoded keys from a file, one key per line. The first key is the current key.
+// Blank lines and lines starting with # are ignored.
+func KeysFromFile(path string) ([][]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return parseKeys(lines)
+}
+
+// ParseKeys base64 decodes every key.
+func parseKeys(encoded []string) ([][]byte, error) {
+	var keys [][]byte
+	for i, e := range encoded {
+		k, err := base64.StdEncoding.DecodeString(strings.TrimSpace(e))
+		if err != nil {
+			return nil, fmt.Errorf("Bad encryption key %d: %v", i, err)
+		}
+		keys = append(keys, k)
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("At least one encryption key is required")
+	}
+	return keys, nil
+}
@@ -0,0 +1,174 @@
+package yahoo
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+var (
+	// fixed test keys, base64 encoded they are
+	// AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8= and ICEiIyQlJicoKSorLC0uLzAxMjM0NTY3ODk6Ozw9Pj8=
+	testKey    = []byte("\x00\x01\x02\x03\x04\x05\x06\x07\x08\x09\x0a\x0b\x0c\x0d\x0e\x0f\x10\x11\x12\x13\x14\x15\x16\x17\x18\x19\x1a\x1b\x1c\x1d\x1e\x1f")
+	testOldKey = []byte(" !\"#$%&'()*+,-./0123456789:;<=>?")
+)
+
+func TestEncryptedFileTokenStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "yahoo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "token")
+	store, err := NewEncryptedFileTokenStore(path, testKey)
+	if err != nil {
+		t.Fatalf("Unexpected NewEncryptedFileTokenStore error: %v", err)
+	}
+
+	if _, err := store.Load(); err != ErrNoToken {
+		t.Errorf("Expected ErrNoToken from an empty store got %v", err)
+	}
+
+	token := &oauth2.Token{AccessToken: "access", RefreshToken: "secret-refresh", Expiry: time.Unix(1500000000, 0)}
+	if err := store.Save(token); err != nil {
+		t.Fatalf("Unexpected EncryptedFileTokenStore.Save error: %v", err)
+	}
+
+	data, _ := ioutil.ReadFile(path)
+	if bytes.Contains(data, []byte("secret-refresh")) {
+		t.Error("The refresh token was saved in plaintext")
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Unexpected EncryptedFileTokenStore.Load error: %v", err)
+	}
+	if loaded.RefreshToken != "secret-refresh" {
+		t.Errorf("EncryptedFileTokenStore.Load returned incorrect token %v", loaded)
+	}
+
+	// a different key can not read the file.
+	other, _ := NewEncryptedFileTokenStore(path, bytes.Repeat([]byte{1}, 32))
+	if _, err := other.Load(); err != ErrDecrypt {
+		t.Errorf("Expected ErrDecrypt got %v", err)
+	}
+}
+
+func TestEncryptedFileTokenStoreRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "yahoo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "token")
+	old, _ := NewEncryptedFileTokenStore(path, testOldKey)
+	if err := old.Save(&oauth2.Token{RefreshToken: "refresh"}); err != nil {
+		t.Fatal(err)
+	}
+
+	rotated, _ := NewEncryptedFileTokenStore(path, testKey, testOldKey)
+	token, err := rotated.Load()
+	if err != nil || token.RefreshToken != "refresh" {
+		t.Fatalf("Rotated store could not load the token: %v, %v", token, err)
+	}
+
+	// the file has been re-encrypted with the new key.
+	current, _ := NewEncryptedFileTokenStore(path, testKey)
+	if _, err := current.Load(); err != nil {
+		t.Errorf("Token was not re-encrypted with the new key: %v", err)
+	}
+	if _, err := old.Load(); err != ErrDecrypt {
+		t.Errorf("Expected the old key to no longer decrypt the token got %v", err)
+	}
+}
+
+func TestEncryptedFileTokenStoreRotateError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "yahoo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "token")
+	old, _ := NewEncryptedFileTokenStore(path, testOldKey)
+	if err := old.Save(&oauth2.Token{RefreshToken: "refresh"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// a current key AES rejects makes every rewrite fail, like a read-only or full disk would.
+	var rotateErrors int
+	broken := &EncryptedFileTokenStore{Path: path, Keys: [][]byte{[]byte("short"), testOldKey}}
+	broken.OnRotateError = func(err error) { rotateErrors++ }
+
+	token, err := broken.Load()
+	if err != nil || token.RefreshToken != "refresh" {
+		t.Fatalf("Store which could not re-encrypt the token returned %v, %v", token, err)
+	}
+	if rotateErrors != 1 {
+		t.Errorf("OnRotateError was called %d times, expected once", rotateErrors)
+	}
+
+	// the file is left as it was.
+	if _, err := old.Load(); err != nil {
+		t.Errorf("Unexpected error loading the token with the old key: %v", err)
+	}
+}
+
+func TestBadEncryptionKey(t *testing.T) {
+	if _, err := NewEncryptedFileTokenStore("token", []byte("short")); err == nil {
+		t.Error("Expected NewEncryptedFileTokenStore to reject a short key")
+	}
+
+	if _, err := NewEncryptedFileTokenStore("token"); err == nil {
+		t.Error("Expected NewEncryptedFileTokenStore to require a key")
+	}
+}
+
+func TestKeysFromEnv(t *testing.T) {
+	defer os.Setenv(TokenKeyEnv, os.Getenv(TokenKeyEnv))
+
+	os.Setenv(TokenKeyEnv, "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=, ICEiIyQlJicoKSorLC0uLzAxMjM0NTY3ODk6Ozw9Pj8=")
+	keys, err := KeysFromEnv("")
+	if err != nil {
+		t.Fatalf("Unexpected KeysFromEnv error: %v", err)
+	}
+	if len(keys) != 2 || !bytes.Equal(keys[0], testKey) || !bytes.Equal(keys[1], testOldKey) {
+		t.Errorf("KeysFromEnv returned incorrect keys %v", keys)
+	}
+
+	os.Setenv(TokenKeyEnv, "not base64!")
+	if _, err := KeysFromEnv(""); err == nil {
+		t.Error("Expected KeysFromEnv to return an error")
+	}
+
+	os.Unsetenv(TokenKeyEnv)
+	if _, err := KeysFromEnv(""); err == nil {
+		t.Error("Expected KeysFromEnv to return an error")
+	}
+}
+
+func TestKeysFromFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "keys")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	f.WriteString("# current key\nAAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=\n\nICEiIyQlJicoKSorLC0uLzAxMjM0NTY3ODk6Ozw9Pj8=\n")
+	f.Close()
+
+	keys, err := KeysFromFile(f.Name())
+	if err != nil {
+		t.Fatalf("Unexpected KeysFromFile error: %v", err)
+	}
+	if len(keys) != 2 || !bytes.Equal(keys[0], testKey) || !bytes.Equal(keys[1], testOldKey) {
+		t.Errorf("KeysFromFile returned incorrect keys %v", keys)
+	}
+}
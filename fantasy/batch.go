@@ -0,0 +1,165 @@
+package fantasy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MaxKeys is the largest number of keys Yahoo accepts in a single request.
+// Query builders holding more keys split them into several requests and merge the results in key order.
+const MaxKeys = 25
+
+// BatchWorkers is the default maximum number of requests a batched query sends concurrently.
+const BatchWorkers = 4
+
+// Batching controls how a query builder splits its keys into several requests.
+// The zero value requests MaxKeys keys at a time with BatchWorkers concurrent requests.
+type Batching struct {
+	// MaxKeys is the largest number of keys per request, MaxKeys when zero.
+	MaxKeys int
+	// Workers is the maximum number of concurrent requests, BatchWorkers when zero.
+	Workers int
+}
+
+// MaxKeys returns the number of keys per request.
+func (b Batching) maxKeys() int {
+	if b.MaxKeys < 1 {
+		return MaxKeys
+	}
+	return b.MaxKeys
+}
+
+// Workers returns the number of concurrent requests.
+func (b Batching) workers() int {
+	if b.Workers < 1 {
+		return BatchWorkers
+	}
+	return b.Workers
+}
+
+// ChunkError is the failure of a single request within a batched query.
+type ChunkError struct {
+	// Keys are the keys requested by the failed request.
+	Keys []string
+	// Err is the error returned by the request.
+	Err error
+}
+
+// Error formats the chunk failure.
+func (e ChunkError) Error() string {
+	return fmt.Sprintf("keys %s: %v", strings.Join(e.Keys, ","), e.Err)
+}
+
+// BatchError is returned by a batched query when some of its requests fail.
+// The results of the successful requests are still returned alongside it.
+type BatchError struct {
+	Chunks []ChunkError
+}
+
+// Error formats every chunk failure.
+func (e *BatchError) Error() string {
+	msgs := make([]string, len(e.Chunks))
+	for i, c := range e.Chunks {
+		msgs[i] = c.Error()
+	}
+	return fmt.Sprintf("%d of the batched requests failed: %s", len(e.Chunks), strings.Join(msgs, "; "))
+}
+
+// ChunkKeys splits keys into slices of at most size keys, preserving their order.
+func chunkKeys(keys []string, size int) [][]string {
+	if size < 1 {
+		size = 1
+	}
+
+	var chunks [][]string
+	for len(keys) > size {
+		chunks = append(chunks, keys[:size])
+		keys = keys[size:]
+	}
+	return append(chunks, keys)
+}
+
+// Batch calls fetch once for every chunk using at most workers goroutines.
+// Fetch receives the index of its chunk so results can be stored in order.
+// When any call fails a *BatchError listing the failed chunks in order is returned.
+func batch(chunks [][]string, workers int, fetch func(i int, keys []string) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	errs := make([]error, len(chunks))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(chunks); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				errs[i] = fetch(i, chunks[i])
+			}
+		}()
+	}
+
+	for i := range chunks {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var failed []ChunkError
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, ChunkError{Keys: chunks[i], Err: err})
+		}
+	}
+
+	if failed != nil {
+		return &BatchError{Chunks: failed}
+	}
+	return nil
+}
+
+// KeyOrder is the position of each key of a batched query, it restores the key order of the merged results.
+type keyOrder map[string]int
+
+// NewKeyOrder numbers keys in order, a repeated key keeps its first position.
+func newKeyOrder(keys []string) keyOrder {
+	o := keyOrder{}
+	for i, k := range keys {
+		if _, ok := o[k]; !ok {
+			o[k] = i
+		}
+	}
+	return o
+}
+
+// Position returns the position of the first of an item's keys which was requested,
+// items matching no key are placed after every requested key.
+func (o keyOrder) position(keys ...string) int {
+	for _, k := range keys {
+		if i, ok := o[k]; ok {
+			return i
+		}
+	}
+	return len(o)
+}
+
+// Sort stable sorts the items slice by the position of the keys of each item.
+func (o keyOrder) sort(items interface{}, keys func(i int) []string) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return o.position(keys(i)...) < o.position(keys(j)...)
+	})
+}
+
+// FirstMeta returns the first metadata of a batched query's requests which is set.
+func firstMeta(metas []ResponseMeta) ResponseMeta {
+	for _, m := range metas {
+		if m != (ResponseMeta{}) {
+			return m
+		}
+	}
+	return ResponseMeta{}
+}
@@ -0,0 +1,126 @@
+package fantasy
+
+import (
+	"fmt"
+	"github.com/muswell/gotest"
+	"strings"
+	"testing"
+)
+
+func TestChunkKeys(t *testing.T) {
+	keys := []string{"a", "b", "c", "d", "e"}
+
+	var tests = []struct {
+		size int
+		want string
+	}{
+		{2, "[[a b] [c d] [e]]"},
+		{5, "[[a b c d e]]"},
+		{10, "[[a b c d e]]"},
+		{0, "[[a] [b] [c] [d] [e]]"},
+	}
+
+	for _, test := range tests {
+		if got := fmt.Sprint(chunkKeys(keys, test.size)); got != test.want {
+			t.Errorf("chunkKeys(%d) = %s, want %s", test.size, got, test.want)
+		}
+	}
+}
+
+// leagueKeysXML builds a league collection response holding a league for every key.
+func leagueKeysXML(keys []string) []byte {
+	xml := `<?xml version="1.0" encoding="UTF-8"?>
+		<fantasy_content xmlns:yahoo="http://www.yahooapis.com/v1/base.rng" xmlns="http://fantasysports.yahooapis.com/fantasy/v2/base.rng" yahoo:uri="http://fantasysports.yahooapis.com/fantasy/v2/leagues" refresh_rate="31">
+		<leagues>`
+	for _, k := range keys {
+		xml += "<league><league_key>" + k + "</league_key></league>"
+	}
+	return []byte(xml + "</leagues></fantasy_content>")
+}
+
+func TestBatchedLeagues(t *testing.T) {
+	batching := Batching{MaxKeys: 2, Workers: 2}
+	keys := []string{"357.l.1", "357.l.2", "357.l.3", "357.l.4", "357.l.5"}
+	client := gotest.NewRegisteredClient()
+
+	// register every chunk except the second so it fails.
+	for i, chunk := range chunkKeys(keys, batching.MaxKeys) {
+		if i == 1 {
+			continue
+		}
+		q := LeagueQueryBuilder{Keys: chunk, Batching: batching}
+		client.Register(q.Url(), "get", gotest.NewSimpleRoundTrip(leagueKeysXML(chunk), nil))
+	}
+
+	q := LeagueQueryBuilder{Keys: keys, Batching: batching}
+	leagues, meta, err := q.GetWithMeta(client.Client)
+
+	batchErr, ok := err.(*BatchError)
+	if !ok {
+		t.Fatalf("Expected a *BatchError got %v", err)
+	}
+	if len(batchErr.Chunks) != 1 || strings.Join(batchErr.Chunks[0].Keys, ",") != "357.l.3,357.l.4" {
+		t.Errorf("Unexpected BatchError chunks %v", batchErr.Chunks)
+	}
+
+	var got []string
+	for _, l := range leagues {
+		got = append(got, l.Key)
+	}
+	if strings.Join(got, ",") != "357.l.1,357.l.2,357.l.5" {
+		t.Errorf("Batched leagues returned out of order %v", got)
+	}
+
+	if meta.RefreshRate != 31 {
+		t.Errorf("Batched leagues returned incorrect meta %v", meta)
+	}
+}
+
+func TestBatchingDefaults(t *testing.T) {
+	var b Batching
+	if b.maxKeys() != MaxKeys || b.workers() != BatchWorkers {
+		t.Errorf("Zero Batching uses %d keys and %d workers, expected %d and %d", b.maxKeys(), b.workers(), MaxKeys, BatchWorkers)
+	}
+
+	b = Batching{MaxKeys: 10, Workers: 1}
+	if b.maxKeys() != 10 || b.workers() != 1 {
+		t.Errorf("Batching uses %d keys and %d workers, expected 10 and 1", b.maxKeys(), b.workers())
+	}
+}
+
+func TestBatchedLeaguesKeyOrder(t *testing.T) {
+	batching := Batching{MaxKeys: 2, Workers: 2}
+	keys := []string{"357.l.5", "357.l.3", "357.l.1", "357.l.4", "357.l.2"}
+	client := gotest.NewRegisteredClient()
+
+	// every chunk comes back in the reverse order of its keys.
+	for _, chunk := range chunkKeys(keys, batching.MaxKeys) {
+		reversed := make([]string, len(chunk))
+		for i, k := range chunk {
+			reversed[len(chunk)-1-i] = k
+		}
+		q := LeagueQueryBuilder{Keys: chunk, Batching: batching}
+		client.Register(q.Url(), "get", gotest.NewSimpleRoundTrip(leagueKeysXML(reversed), nil))
+	}
+
+	q := LeagueQueryBuilder{Keys: keys, Batching: batching}
+	leagues, err := q.Get(client.Client)
+	if err != nil {
+		t.Fatalf("Unexpected batched leagues error: %v", err)
+	}
+
+	var got []string
+	for _, l := range leagues {
+		got = append(got, l.Key)
+	}
+	if strings.Join(got, ",") != strings.Join(keys, ",") {
+		t.Errorf("Batched leagues returned %v, expected the key order %v", got, keys)
+	}
+}
+
+func TestKeyOrder(t *testing.T) {
+	o := newKeyOrder([]string{"mlb", "357.l.1", "mlb"})
+	if o.position("mlb") != 0 || o.position("357.l.1") != 1 || o.position("x", "357.l.1") != 1 || o.position("nfl") != 2 {
+		t.Errorf("Incorrect key positions %v", o)
+	}
+}
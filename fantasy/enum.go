@@ -0,0 +1,238 @@
+package fantasy
+
+import "strings"
+
+// Enums are strings so values Yahoo adds later are kept rather than failing to unmarshal,
+// Known reports whether a value is one of the declared constants.
+
+// ScoringType is how a league scores its teams.
+type ScoringType string
+
+const (
+	// ScoringRoto ranks teams in each stat category over the whole season.
+	ScoringRoto ScoringType = "roto"
+	// ScoringHead is a head to head league, each category is won or lost every week.
+	ScoringHead ScoringType = "head"
+	// ScoringHeadPoint is a head to head league where teams score points.
+	ScoringHeadPoint ScoringType = "headpoint"
+	// ScoringHeadOne is a head to head league where the team winning the most categories wins the week.
+	ScoringHeadOne ScoringType = "headone"
+	// ScoringPoint ranks teams by their points over the whole season.
+	ScoringPoint ScoringType = "point"
+)
+
+var scoringTypes = map[ScoringType]bool{
+	ScoringRoto:      true,
+	ScoringHead:      true,
+	ScoringHeadPoint: true,
+	ScoringHeadOne:   true,
+	ScoringPoint:     true,
+}
+
+// String returns the value as Yahoo sends it.
+func (s ScoringType) String() string {
+	return string(s)
+}
+
+// Known reports whether s is one of the declared scoring types.
+func (s ScoringType) Known() bool {
+	return scoringTypes[s]
+}
+
+// UnmarshalText keeps any value, trimming surrounding space.
+func (s *ScoringType) UnmarshalText(b []byte) error {
+	*s = ScoringType(strings.TrimSpace(string(b)))
+	return nil
+}
+
+// LeagueType is whether anyone may join a league.
+type LeagueType string
+
+const (
+	// LeaguePublic leagues are open for anyone to join.
+	LeaguePublic LeagueType = "public"
+	// LeaguePrivate leagues are joined by invitation from the commissioner.
+	LeaguePrivate LeagueType = "private"
+)
+
+var leagueTypes = map[LeagueType]bool{
+	LeaguePublic:  true,
+	LeaguePrivate: true,
+}
+
+// String returns the value as Yahoo sends it.
+func (l LeagueType) String() string {
+	return string(l)
+}
+
+// Known reports whether l is one of the declared league types.
+func (l LeagueType) Known() bool {
+	return leagueTypes[l]
+}
+
+// UnmarshalText keeps any value, trimming surrounding space.
+func (l *LeagueType) UnmarshalText(b []byte) error {
+	*l = LeagueType(strings.TrimSpace(string(b)))
+	return nil
+}
+
+// DraftStatus is the progress of a league's draft.
+type DraftStatus string
+
+const (
+	DraftPreDraft  DraftStatus = "predraft"
+	DraftDrafting  DraftStatus = "drafting"
+	DraftPostDraft DraftStatus = "postdraft"
+)
+
+var draftStatuses = map[DraftStatus]bool{
+	DraftPreDraft:  true,
+	DraftDrafting:  true,
+	DraftPostDraft: true,
+}
+
+// String returns the value as Yahoo sends it.
+func (d DraftStatus) String() string {
+	return string(d)
+}
+
+// Known reports whether d is one of the declared draft statuses.
+func (d DraftStatus) Known() bool {
+	return draftStatuses[d]
+}
+
+// UnmarshalText keeps any value, trimming surrounding space.
+func (d *DraftStatus) UnmarshalText(b []byte) error {
+	*d = DraftStatus(strings.TrimSpace(string(b)))
+	return nil
+}
+
+// DraftType is how a league's draft is run.
+type DraftType string
+
+const (
+	// DraftLive is a live online draft.
+	DraftLive DraftType = "live"
+	// DraftSelf is an offline draft whose results are entered by the commissioner.
+	DraftSelf DraftType = "self"
+	// DraftAutopick is drafted automatically from each manager's pre-draft rankings.
+	DraftAutopick DraftType = "autopick"
+)
+
+var draftTypes = map[DraftType]bool{
+	DraftLive:     true,
+	DraftSelf:     true,
+	DraftAutopick: true,
+}
+
+// String returns the value as Yahoo sends it.
+func (d DraftType) String() string {
+	return string(d)
+}
+
+// Known reports whether d is one of the declared draft types.
+func (d DraftType) Known() bool {
+	return draftTypes[d]
+}
+
+// UnmarshalText keeps any value, trimming surrounding space.
+func (d *DraftType) UnmarshalText(b []byte) error {
+	*d = DraftType(strings.TrimSpace(string(b)))
+	return nil
+}
+
+// WaiverType is how waiver claims are prioritised.
+type WaiverType string
+
+const (
+	// WaiverRolling is a continual rolling list, a successful claim moves the team to the end of the list.
+	WaiverRolling WaiverType = "R"
+	// WaiverFAAB awards claims to the highest free agent budget bid, ties are broken by a rolling list.
+	WaiverFAAB WaiverType = "FR"
+)
+
+var waiverTypes = map[WaiverType]bool{
+	WaiverRolling: true,
+	WaiverFAAB:    true,
+}
+
+// String returns the value as Yahoo sends it.
+func (w WaiverType) String() string {
+	return string(w)
+}
+
+// Known reports whether w is one of the declared waiver types.
+func (w WaiverType) Known() bool {
+	return waiverTypes[w]
+}
+
+// UnmarshalText keeps any value, trimming surrounding space.
+func (w *WaiverType) UnmarshalText(b []byte) error {
+	*w = WaiverType(strings.TrimSpace(string(b)))
+	return nil
+}
+
+// TradeRatifyType is who may stop a trade before it is processed.
+type TradeRatifyType string
+
+const (
+	// TradeRatifyCommish lets the commissioner veto trades.
+	TradeRatifyCommish TradeRatifyType = "commish"
+	// TradeRatifyVote lets the league's managers vote against trades.
+	TradeRatifyVote TradeRatifyType = "vote"
+	// TradeRatifyNone processes trades without review.
+	TradeRatifyNone TradeRatifyType = "none"
+)
+
+var tradeRatifyTypes = map[TradeRatifyType]bool{
+	TradeRatifyCommish: true,
+	TradeRatifyVote:    true,
+	TradeRatifyNone:    true,
+}
+
+// String returns the value as Yahoo sends it.
+func (t TradeRatifyType) String() string {
+	return string(t)
+}
+
+// Known reports whether t is one of the declared trade ratify types.
+func (t TradeRatifyType) Known() bool {
+	return tradeRatifyTypes[t]
+}
+
+// UnmarshalText keeps any value, trimming surrounding space.
+func (t *TradeRatifyType) UnmarshalText(b []byte) error {
+	*t = TradeRatifyType(strings.TrimSpace(string(b)))
+	return nil
+}
+
+// PostDraftPlayers is where undrafted players go once the draft is over.
+type PostDraftPlayers string
+
+const (
+	// PostDraftWaivers puts undrafted players on waivers.
+	PostDraftWaivers PostDraftPlayers = "W"
+	// PostDraftFreeAgents makes undrafted players free agents.
+	PostDraftFreeAgents PostDraftPlayers = "FA"
+)
+
+var postDraftRules = map[PostDraftPlayers]bool{
+	PostDraftWaivers:    true,
+	PostDraftFreeAgents: true,
+}
+
+// String returns the value as Yahoo sends it.
+func (p PostDraftPlayers) String() string {
+	return string(p)
+}
+
+// Known reports whether p is one of the declared post draft player rules.
+func (p PostDraftPlayers) Known() bool {
+	return postDraftRules[p]
+}
+
+// UnmarshalText keeps any value, trimming surrounding space.
+func (p *PostDraftPlayers) UnmarshalText(b []byte) error {
+	*p = PostDraftPlayers(strings.TrimSpace(string(b)))
+	return nil
+}
@@ -0,0 +1,70 @@
+package fantasy
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+)
+
+func TestEnumUnmarshal(t *testing.T) {
+	type testObject struct {
+		XMLName     xml.Name        `xml:"league"`
+		ScoringType ScoringType     `xml:"scoring_type" json:"scoring_type"`
+		LeagueType  LeagueType      `xml:"league_type" json:"league_type"`
+		DraftStatus DraftStatus     `xml:"draft_status" json:"draft_status"`
+		Ratify      TradeRatifyType `xml:"trade_ratify_type" json:"trade_ratify_type"`
+	}
+
+	obj := testObject{}
+	input := `<league><scoring_type> headone </scoring_type><league_type>private</league_type>` +
+		`<draft_status>paused</draft_status><trade_ratify_type>vote</trade_ratify_type></league>`
+	if err := xml.Unmarshal([]byte(input), &obj); err != nil {
+		t.Fatalf("Unexpected Unmarshal error: %v", err)
+	}
+
+	if obj.ScoringType != ScoringHeadOne || !obj.ScoringType.Known() {
+		t.Errorf("ScoringType = %q, expected %q", obj.ScoringType, ScoringHeadOne)
+	}
+	if obj.LeagueType != LeaguePrivate || obj.Ratify != TradeRatifyVote {
+		t.Errorf("LeagueType = %s, TradeRatifyType = %s", obj.LeagueType, obj.Ratify)
+	}
+
+	// unknown values are kept.
+	if obj.DraftStatus.String() != "paused" || obj.DraftStatus.Known() {
+		t.Errorf("DraftStatus = %q known %t, expected an unknown paused", obj.DraftStatus, obj.DraftStatus.Known())
+	}
+
+	js := testObject{}
+	if err := json.Unmarshal([]byte(`{"scoring_type":"point","league_type":"invite"}`), &js); err != nil {
+		t.Fatalf("Unexpected json Unmarshal error: %v", err)
+	}
+	if js.ScoringType != ScoringPoint || js.LeagueType != "invite" || js.LeagueType.Known() {
+		t.Errorf("json ScoringType = %s, LeagueType = %s", js.ScoringType, js.LeagueType)
+	}
+}
+
+func TestEnumKnown(t *testing.T) {
+	known := []interface{ Known() bool }{
+		ScoringRoto, ScoringHead, ScoringHeadPoint, ScoringHeadOne, ScoringPoint,
+		LeaguePublic, LeaguePrivate,
+		DraftPreDraft, DraftDrafting, DraftPostDraft,
+		DraftLive, DraftSelf, DraftAutopick,
+		WaiverRolling, WaiverFAAB,
+		TradeRatifyCommish, TradeRatifyVote, TradeRatifyNone,
+		PostDraftWaivers, PostDraftFreeAgents,
+	}
+	for _, v := range known {
+		if !v.Known() {
+			t.Errorf("%v is not known", v)
+		}
+	}
+
+	unknown := []interface{ Known() bool }{
+		ScoringType(""), LeagueType("Public"), DraftType("offline"), WaiverType("X"), PostDraftPlayers("w"),
+	}
+	for _, v := range unknown {
+		if v.Known() {
+			t.Errorf("%v is known", v)
+		}
+	}
+}
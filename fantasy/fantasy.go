@@ -1,7 +1,60 @@
 // Fantasy wraps the Yahoo fantasy API into Go types and provides functions to generate proper request URLs.
 package fantasy
 
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+)
+
 const (
 	// BaseUrl is the root yahoo fantasy api url.
 	baseUrl = "https://fantasysports.yahooapis.com/fantasy/v2/"
 )
+
+// Format is the response format requested from the api.
+type Format string
+
+const (
+	// FormatXML requests xml responses, it is the default.
+	FormatXML Format = "xml"
+	// FormatJSON requests json responses, they are decoded into the same types as xml responses.
+	FormatJSON Format = "json"
+)
+
+// String returns the value of the format query string parameter, an empty Format is xml.
+func (f Format) String() string {
+	if f == "" {
+		return string(FormatXML)
+	}
+	return string(f)
+}
+
+// Fetch sends a GET request to url and returns a decoder reading the response body in format f.
+func fetch(client *http.Client, url string, f Format) (*xml.Decoder, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDecoder(data, f)
+}
+
+// NewDecoder returns an xml decoder reading data in format f.
+func newDecoder(data []byte, f Format) (*xml.Decoder, error) {
+	if f == FormatJSON {
+		r, err := newJSONTokenReader(data)
+		if err != nil {
+			return nil, err
+		}
+		return xml.NewTokenDecoder(r), nil
+	}
+	return xml.NewDecoder(bytes.NewReader(data)), nil
+}
@@ -0,0 +1,150 @@
+package fantasytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Yahoo's json responses are a mechanical translation of its xml responses, the server builds them
+// from the same xml so both formats always hold the same content. The translation rules are:
+//   - collections, elements with a count attribute, become objects with numeric keys ("0", "1", ...)
+//     holding a single member object per item and a count member.
+//   - other elements with children become arrays of single member objects, one per child.
+//   - elements without children become strings.
+//   - the attributes of fantasy_content become members of its object.
+
+// yahooNS is the namespace of the yahoo:uri attribute.
+const yahooNS = "http://www.yahooapis.com/v1/base.rng"
+
+// xmlNode is an element of a parsed xml document.
+type xmlNode struct {
+	name     string
+	attrs    []xml.Attr
+	children []*xmlNode
+	text     string
+}
+
+// toJSON translates an xml document into Yahoo's json format.
+func toJSON(data []byte) ([]byte, error) {
+	root, err := parseXMLNode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(`{"` + root.name + `":{`)
+	n := 0
+	for _, a := range root.attrs {
+		if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+			continue
+		}
+		name := a.Name.Local
+		if a.Name.Space == yahooNS {
+			name = "yahoo:" + name
+		}
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		writeJSONMember(buf, name, a.Value)
+		n++
+	}
+	for _, c := range root.children {
+		if n > 0 {
+			buf.WriteByte(',')
+		}
+		writeJSONString(buf, c.name)
+		buf.WriteByte(':')
+		writeJSONNode(buf, c)
+		n++
+	}
+	buf.WriteString("}}")
+	return buf.Bytes(), nil
+}
+
+// parseXMLNode parses the root element of an xml document.
+func parseXMLNode(data []byte) (*xmlNode, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	var stack []*xmlNode
+	var root *xmlNode
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			return root, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := t.(type) {
+		case xml.StartElement:
+			n := &xmlNode{name: t.Name.Local, attrs: t.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+}
+
+// writeJSONNode writes the json value of an element.
+func writeJSONNode(buf *bytes.Buffer, n *xmlNode) {
+	for _, a := range n.attrs {
+		if a.Name.Local != "count" {
+			continue
+		}
+		buf.WriteByte('{')
+		for i, c := range n.children {
+			buf.WriteString(`"` + strconv.Itoa(i) + `":{`)
+			writeJSONString(buf, c.name)
+			buf.WriteByte(':')
+			writeJSONNode(buf, c)
+			buf.WriteString("},")
+		}
+		buf.WriteString(`"count":` + a.Value + "}")
+		return
+	}
+
+	if len(n.children) == 0 {
+		writeJSONString(buf, strings.TrimSpace(n.text))
+		return
+	}
+
+	buf.WriteByte('[')
+	for i, c := range n.children {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('{')
+		writeJSONString(buf, c.name)
+		buf.WriteByte(':')
+		writeJSONNode(buf, c)
+		buf.WriteByte('}')
+	}
+	buf.WriteByte(']')
+}
+
+// writeJSONMember writes a member with a string value.
+func writeJSONMember(buf *bytes.Buffer, key, value string) {
+	writeJSONString(buf, key)
+	buf.WriteByte(':')
+	writeJSONString(buf, value)
+}
+
+// writeJSONString writes s as a json string.
+func writeJSONString(buf *bytes.Buffer, s string) {
+	b, _ := json.Marshal(s)
+	buf.Write(b)
+}
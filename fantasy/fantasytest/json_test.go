@@ -0,0 +1,90 @@
+package fantasytest_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/muswell/yahoo/fantasy"
+	"github.com/muswell/yahoo/fantasy/fantasytest"
+)
+
+func TestServeJSON(t *testing.T) {
+	s := fantasytest.NewServer(nil)
+	defer s.Close()
+
+	// every query is served the same content as xml and json.
+	queries := map[string]func(f fantasy.Format) (string, error){
+		"leagues": func(f fantasy.Format) (string, error) {
+			user := &fantasy.UserQueryBuilder{ActiveUser: true}
+			leagues, err := (&fantasy.LeagueQueryBuilder{UserQB: user, Settings: true, Format: f}).Get(s.Client())
+			if err != nil || len(leagues) != 1 || leagues[0].Settings == nil {
+				return "", err
+			}
+			l := leagues[0]
+			return fmt.Sprint(l.Key, l.Name, l.NumTeams, l.Settings.RosterPositions, l.Settings.UsesFAAB), nil
+		},
+		"roster": func(f fantasy.Format) (string, error) {
+			teams, err := (&fantasy.TeamQueryBuilder{Keys: []string{team2}, Roster: true, Format: f}).Get(s.Client())
+			if err != nil || len(teams) != 1 || teams[0].Roster == nil {
+				return "", err
+			}
+			var players []string
+			for _, p := range teams[0].Roster.Players {
+				players = append(players, p.Key+" "+p.SelectedPosition.Position+" "+strings.Join(p.EligiblePositions, ","))
+			}
+			return fmt.Sprint(teams[0].Key, teams[0].Roster.Date, players), nil
+		},
+		"standings": func(f fantasy.Format) (string, error) {
+			league := &fantasy.LeagueQueryBuilder{Keys: []string{league}}
+			teams, err := (&fantasy.TeamQueryBuilder{LeagueQB: league, Standings: true, Format: f}).Get(s.Client())
+			var standings []string
+			for _, t := range teams {
+				if t.Standings != nil {
+					standings = append(standings, fmt.Sprint(t.Key, t.Standings.Rank, t.Standings.PointsFor))
+				}
+			}
+			return fmt.Sprint(standings), err
+		},
+		"players": func(f fantasy.Format) (string, error) {
+			league := &fantasy.LeagueQueryBuilder{Keys: []string{league}}
+			players, err := (&fantasy.PlayerQueryBuilder{LeagueQB: league, Status: "A", Format: f}).Get(s.Client())
+			var keys []string
+			for _, p := range players {
+				keys = append(keys, p.Key+" "+p.Name.Full+" "+p.Status)
+			}
+			return fmt.Sprint(keys), err
+		},
+	}
+
+	for name, query := range queries {
+		x, err := query(fantasy.FormatXML)
+		if err != nil || x == "" {
+			t.Errorf("Unexpected xml %s result %q, %v", name, x, err)
+			continue
+		}
+		j, err := query(fantasy.FormatJSON)
+		if err != nil {
+			t.Errorf("Unexpected json %s error: %v", name, err)
+			continue
+		}
+		if j != x {
+			t.Errorf("Served different %s as json %s, expected %s", name, j, x)
+		}
+	}
+}
+
+func TestServeJSONError(t *testing.T) {
+	s := fantasytest.NewServer(nil)
+	defer s.Close()
+
+	resp, err := s.Client().Get("https://fantasysports.yahooapis.com/fantasy/v2/league/357.l.1?format=json")
+	if err != nil {
+		t.Fatalf("Unexpected request error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound || !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		t.Errorf("Served %d %s for an unknown league, expected a 404 json error", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+}
@@ -0,0 +1,256 @@
+package fantasytest
+
+import (
+	"strings"
+
+	"github.com/muswell/yahoo/fantasy"
+)
+
+// Model is the in-memory data served by a Server, write requests change it.
+// Lock the server with Server.Lock before reading or changing a model which is being served.
+type Model struct {
+	// Guid is the guid of the logged in user, the user's leagues are those with a team they manage.
+	Guid string
+	// Games are the games of the leagues.
+	Games []fantasy.Game
+	// Leagues are every league, each belongs to a game by its GameCode and league key.
+	Leagues []*League
+}
+
+// League is a league of a Model with its teams, player pool and transactions.
+type League struct {
+	fantasy.League
+	Teams []*Team
+	// Players is the pool of players which may be rostered, a player on no team is available.
+	Players []fantasy.Player
+	// Waivers holds the keys of available players on waivers, other available players are free agents.
+	// Adding a player on waivers makes a pending waiver claim instead of adding the player.
+	Waivers map[string]bool
+	// Transactions are the league's transactions, newest first as Yahoo returns them.
+	Transactions []fantasy.Transaction
+	// Commissioner is the guid of the user who may allow or disallow trades.
+	Commissioner string
+}
+
+// Team is a team of a League with its roster.
+type Team struct {
+	fantasy.Team
+	// Players are the players on the team and their positions.
+	Players []fantasy.RosterPlayer
+}
+
+// NewModel returns a baseball game with one league of two teams managed by users GUID1 and GUID2,
+// GUID1 is logged in and the commissioner. Each team rosters two players and two more are available, one on waivers.
+// Team 1 leads the roto standings.
+func NewModel() *Model {
+	game := fantasy.Game{Key: 357, ID: 357, Name: "Baseball", Code: "mlb", Season: 2016}
+
+	player := func(id int64, name, team, position string) fantasy.Player {
+		first, last := name, ""
+		if i := strings.Index(name, " "); i > 0 {
+			first, last = name[:i], name[i+1:]
+		}
+		return fantasy.Player{
+			Key:               "357.p." + itoa(id),
+			ID:                id,
+			Name:              fantasy.PlayerName{Full: name, First: first, Last: last, ASCIIFirst: first, ASCIILast: last},
+			EditorialTeamAbbr: team,
+			DisplayPosition:   position,
+			PositionType:      positionType(position),
+			EligiblePositions: append(strings.Split(position, ","), eligibleExtra(position)...),
+		}
+	}
+
+	players := []fantasy.Player{
+		player(8967, "Buster Posey", "SF", "C,1B"),
+		player(9105, "Madison Bumgarner", "SF", "SP"),
+		player(8658, "Mike Trout", "LAA", "OF"),
+		player(9116, "Clayton Kershaw", "LAD", "SP"),
+		player(9573, "Mookie Betts", "BOS", "OF"),
+		player(8875, "Kenley Jansen", "LAD", "RP"),
+	}
+
+	team := func(id int64, name, guid string, points float64, rostered ...fantasy.Player) *Team {
+		t := &Team{Team: fantasy.Team{
+			Key:            "357.l.86753.t." + itoa(id),
+			ID:             id,
+			Name:           name,
+			URL:            "https://baseball.fantasysports.yahoo.com/b1/86753/" + itoa(id),
+			WaiverPriority: id,
+			FAABBalance:    100,
+			Managers:       []fantasy.Manager{{Guid: guid, ManagerID: itoa(id), Name: name + " Manager"}},
+			Standings:      &fantasy.TeamStandings{Rank: fantasy.NewInt(id), PointsFor: fantasy.NewFloat(points)},
+		}}
+		for _, p := range rostered {
+			t.Players = append(t.Players, fantasy.RosterPlayer{Player: p, SelectedPosition: fantasy.SelectedPosition{Position: p.EligiblePositions[0]}})
+		}
+		return t
+	}
+
+	league := &League{
+		League: fantasy.League{
+			ID:          86753,
+			Key:         "357.l.86753",
+			Name:        "Fantasytest League",
+			URL:         "https://baseball.fantasysports.yahoo.com/b1/86753",
+			DraftStatus: fantasy.DraftPostDraft,
+			NumTeams:    2,
+			ScoringType: fantasy.ScoringRoto,
+			LeagueType:  fantasy.LeaguePrivate,
+			GameCode:    "mlb",
+			Season:      2016,
+			Settings: &fantasy.Settings{
+				DraftType:        fantasy.DraftLive,
+				ScoringType:      fantasy.ScoringRoto,
+				WaiverType:       fantasy.WaiverRolling,
+				UsesFAAB:         true,
+				PostDraftPlayers: fantasy.PostDraftWaivers,
+				TradeRatifyType:  fantasy.TradeRatifyNone,
+				MaxTeams:         fantasy.NewInt(12),
+				RosterPositions: []fantasy.LeagueRosterPosition{
+					{Position: "C", PositionType: "B", Count: 1},
+					{Position: "1B", PositionType: "B", Count: 1},
+					{Position: "OF", PositionType: "B", Count: 3},
+					{Position: "SP", PositionType: "P", Count: 2},
+					{Position: "RP", PositionType: "P", Count: 2},
+					{Position: "BN", Count: 5},
+					{Position: "DL", Count: 2},
+				},
+			},
+		},
+		Teams: []*Team{
+			team(1, "Bochy's Bullpen", "GUID1", 15, players[0], players[1]),
+			team(2, "Halo Effect", "GUID2", 9, players[2], players[3]),
+		},
+		Players:      players,
+		Waivers:      map[string]bool{players[5].Key: true},
+		Commissioner: "GUID1",
+	}
+
+	return &Model{Guid: "GUID1", Games: []fantasy.Game{game}, Leagues: []*League{league}}
+}
+
+// PositionType returns P for pitching positions and B for every other position.
+func positionType(position string) string {
+	if strings.Contains(position, "P") {
+		return "P"
+	}
+	return "B"
+}
+
+// EligibleExtra returns the utility and bench positions every player may fill.
+func eligibleExtra(position string) []string {
+	if positionType(position) == "P" {
+		return []string{"P", "BN"}
+	}
+	return []string{"Util", "BN"}
+}
+
+// league returns the league with key.
+func (m *Model) league(key string) *League {
+	for _, l := range m.Leagues {
+		if l.Key == key {
+			return l
+		}
+	}
+	return nil
+}
+
+// team returns the team with key and its league.
+func (m *Model) team(key string) (*League, *Team) {
+	for _, l := range m.Leagues {
+		for _, t := range l.Teams {
+			if t.Key == key {
+				return l, t
+			}
+		}
+	}
+	return nil, nil
+}
+
+// gameOf returns the game a league belongs to.
+func (m *Model) gameOf(l *League) *fantasy.Game {
+	for i, g := range m.Games {
+		if g.Code == l.GameCode && strings.HasPrefix(l.Key, itoa(g.Key)+".") {
+			return &m.Games[i]
+		}
+	}
+	return nil
+}
+
+// team returns the league's team with key.
+func (l *League) team(key string) *Team {
+	for _, t := range l.Teams {
+		if t.Key == key {
+			return t
+		}
+	}
+	return nil
+}
+
+// player returns a player of the league's pool.
+func (l *League) player(key string) (fantasy.Player, bool) {
+	for _, p := range l.Players {
+		if p.Key == key {
+			return p, true
+		}
+	}
+	return fantasy.Player{}, false
+}
+
+// owner returns the team which rosters the player, nil for an available player.
+func (l *League) owner(playerKey string) *Team {
+	for _, t := range l.Teams {
+		if t.has(playerKey) {
+			return t
+		}
+	}
+	return nil
+}
+
+// transaction returns the transaction with key.
+func (l *League) transaction(key string) *fantasy.Transaction {
+	for i := range l.Transactions {
+		if l.Transactions[i].Key == key {
+			return &l.Transactions[i]
+		}
+	}
+	return nil
+}
+
+// managedBy reports whether the user with guid manages the team.
+func (t *Team) managedBy(guid string) bool {
+	for _, m := range t.Managers {
+		if m.Guid == guid {
+			return true
+		}
+	}
+	return false
+}
+
+// has reports whether the player is on the team.
+func (t *Team) has(playerKey string) bool {
+	return t.index(playerKey) >= 0
+}
+
+// index returns the roster index of the player, -1 when the player is not on the team.
+func (t *Team) index(playerKey string) int {
+	for i, p := range t.Players {
+		if p.Key == playerKey {
+			return i
+		}
+	}
+	return -1
+}
+
+// remove takes the player off the team.
+func (t *Team) remove(playerKey string) {
+	if i := t.index(playerKey); i >= 0 {
+		t.Players = append(t.Players[:i], t.Players[i+1:]...)
+	}
+}
+
+// add puts the player on the team's bench.
+func (t *Team) add(p fantasy.Player) {
+	t.Players = append(t.Players, fantasy.RosterPlayer{Player: p, SelectedPosition: fantasy.SelectedPosition{Position: "BN"}})
+}
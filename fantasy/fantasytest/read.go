@@ -0,0 +1,401 @@
+package fantasytest
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/muswell/yahoo/fantasy"
+)
+
+// cursor holds the resources selected by the segments read so far, later segments are nested in them.
+type cursor struct {
+	user    *userXML
+	games   []*gameXML
+	leagues []*leagueXML
+	teams   []*fantasy.Team
+}
+
+// read builds the response to a GET of the resources in segs.
+func (s *Server) read(segs []segment) (*content, error) {
+	c := &content{}
+	var cur cursor
+
+	for i, seg := range segs {
+		switch seg.name {
+		case "users":
+			if i != 0 || seg.params["use_login"] != "1" {
+				return nil, errorf(http.StatusBadRequest, "fantasytest only serves users;use_login=1")
+			}
+			cur.user = &userXML{Guid: s.Model.Guid}
+			c.Users = &usersXML{Count: 1, Users: []*userXML{cur.user}}
+
+		case "games":
+			games := s.games(seg, cur.user != nil)
+			list := &gamesXML{Count: len(games), Games: games}
+			switch {
+			case cur.user != nil && cur.games == nil:
+				cur.user.Games = list
+			case i == 0:
+				c.Games = list
+			default:
+				return nil, errorf(http.StatusBadRequest, "games must follow users or start the path")
+			}
+			cur.games = games
+
+		case "leagues":
+			switch {
+			case cur.games != nil:
+				for _, g := range cur.games {
+					leagues := s.leagues(seg, &g.Game, cur.user != nil)
+					g.Leagues = &leaguesXML{Count: len(leagues), Leagues: leagues}
+					cur.leagues = append(cur.leagues, leagues...)
+				}
+			case i == 0:
+				if seg.params["league_keys"] == "" {
+					return nil, errorf(http.StatusBadRequest, "leagues requires league_keys")
+				}
+				cur.leagues = s.leagues(seg, nil, false)
+				c.Leagues = &leaguesXML{Count: len(cur.leagues), Leagues: cur.leagues}
+			default:
+				return nil, errorf(http.StatusBadRequest, "leagues must follow games or start the path")
+			}
+			if len(cur.leagues) == 0 && seg.params["league_keys"] != "" {
+				return nil, errorf(http.StatusNotFound, "League %s not found", seg.params["league_keys"])
+			}
+
+		case "teams":
+			switch {
+			case cur.leagues != nil:
+				for _, l := range cur.leagues {
+					teams := s.teams(seg, l.model.Teams, cur.user != nil)
+					l.Teams = &teamsXML{Count: len(teams), Teams: teams}
+					for i := range l.Teams.Teams {
+						cur.teams = append(cur.teams, &l.Teams.Teams[i])
+					}
+				}
+			case i == 0:
+				if seg.params["team_keys"] == "" {
+					return nil, errorf(http.StatusBadRequest, "teams requires team_keys")
+				}
+				var all []*Team
+				for _, l := range s.Model.Leagues {
+					all = append(all, l.Teams...)
+				}
+				c.Teams = &teamsXML{Teams: s.teams(seg, all, false)}
+				c.Teams.Count = len(c.Teams.Teams)
+				for i := range c.Teams.Teams {
+					cur.teams = append(cur.teams, &c.Teams.Teams[i])
+				}
+			default:
+				return nil, errorf(http.StatusBadRequest, "teams must follow leagues or start the path")
+			}
+			if len(cur.teams) == 0 && seg.params["team_keys"] != "" {
+				return nil, errorf(http.StatusNotFound, "Team %s not found", seg.params["team_keys"])
+			}
+
+		case "players":
+			switch {
+			case cur.leagues != nil:
+				for _, l := range cur.leagues {
+					players := page(s.players(seg, l.model), seg)
+					l.Players = &playersXML{Count: len(players), Players: players}
+				}
+			case cur.games != nil:
+				for _, g := range cur.games {
+					var players []fantasy.Player
+					for _, l := range s.Model.Leagues {
+						if gl := s.Model.gameOf(l); gl != nil && gl.Key == g.Key {
+							players = appendNew(players, s.players(seg, l))
+						}
+					}
+					players = page(players, seg)
+					g.Players = &playersXML{Count: len(players), Players: players}
+				}
+			case i == 0:
+				var players []fantasy.Player
+				for _, l := range s.Model.Leagues {
+					players = appendNew(players, s.players(seg, l))
+				}
+				players = page(players, seg)
+				c.Players = &playersXML{Count: len(players), Players: players}
+			default:
+				return nil, errorf(http.StatusBadRequest, "players must follow leagues, games or start the path")
+			}
+
+		case "transactions":
+			switch {
+			case cur.leagues != nil:
+				for _, l := range cur.leagues {
+					transactions := transactionsOf(seg, l.model)
+					l.Transactions = &transactionsXML{Count: len(transactions), Transactions: transactions}
+				}
+			case i == 0:
+				var transactions []fantasy.Transaction
+				for _, l := range s.Model.Leagues {
+					transactions = append(transactions, transactionsOf(seg, l)...)
+				}
+				if len(transactions) == 0 && seg.key != "" {
+					return nil, errorf(http.StatusNotFound, "Transaction %s not found", seg.key)
+				}
+				c.Transactions = &transactionsXML{Count: len(transactions), Transactions: transactions}
+			default:
+				return nil, errorf(http.StatusBadRequest, "transactions must follow leagues or start the path")
+			}
+
+		case "settings":
+			for _, l := range cur.leagues {
+				l.Settings = l.model.Settings
+			}
+
+		case "roster":
+			for _, t := range cur.teams {
+				t.Roster = s.roster(t.Key)
+			}
+
+		case "standings":
+			for _, t := range cur.teams {
+				_, mt := s.Model.team(t.Key)
+				t.Standings = mt.Standings
+			}
+
+		case "metadata":
+
+		default:
+			return nil, errorf(http.StatusBadRequest, "fantasytest does not serve %s", seg.name)
+		}
+	}
+	return c, nil
+}
+
+// games returns the games matching the game_keys, game_codes and seasons parameters,
+// only those with a league of the logged in user when userOnly is true.
+func (s *Server) games(seg segment, userOnly bool) []*gameXML {
+	var games []*gameXML
+	for _, g := range s.Model.Games {
+		if !matchGame(g, seg.keys("game_keys"), seg.keys("game_codes"), seg.keys("seasons")) {
+			continue
+		}
+		if userOnly && len(s.leagues(segment{}, &g, true)) == 0 {
+			continue
+		}
+		games = append(games, &gameXML{Game: g})
+	}
+	return games
+}
+
+// matchGame reports whether the game matches every non empty filter, keys are game ids or codes.
+func matchGame(g fantasy.Game, keys, codes, seasons []string) bool {
+	if keys != nil && !contains(keys, itoa(g.Key)) && !contains(keys, g.Code) {
+		return false
+	}
+	if codes != nil && !contains(codes, g.Code) {
+		return false
+	}
+	return seasons == nil || contains(seasons, itoa(g.Season))
+}
+
+// leagues returns the leagues matching the league_keys parameter within game, or every game when game is nil,
+// only those with a team of the logged in user when userOnly is true.
+func (s *Server) leagues(seg segment, game *fantasy.Game, userOnly bool) []*leagueXML {
+	keys := seg.keys("league_keys")
+	var leagues []*leagueXML
+	for _, l := range s.Model.Leagues {
+		if keys != nil && !contains(keys, l.Key) {
+			continue
+		}
+		if game != nil {
+			if g := s.Model.gameOf(l); g == nil || g.Key != game.Key {
+				continue
+			}
+		}
+		if userOnly && s.userTeam(l) == nil {
+			continue
+		}
+
+		out := &leagueXML{League: l.League, model: l}
+		if !seg.out("settings") {
+			out.Settings = nil
+		}
+		leagues = append(leagues, out)
+	}
+	return leagues
+}
+
+// userTeam returns the league's team managed by the logged in user.
+func (s *Server) userTeam(l *League) *Team {
+	for _, t := range l.Teams {
+		if t.managedBy(s.Model.Guid) {
+			return t
+		}
+	}
+	return nil
+}
+
+// teams returns the teams matching the team_keys parameter, only the logged in user's when userOnly is true.
+func (s *Server) teams(seg segment, teams []*Team, userOnly bool) []fantasy.Team {
+	keys := seg.keys("team_keys")
+	var out []fantasy.Team
+	for _, t := range teams {
+		if keys != nil && !contains(keys, t.Key) {
+			continue
+		}
+		owned := t.managedBy(s.Model.Guid)
+		if userOnly && !owned {
+			continue
+		}
+
+		team := t.Team
+		team.IsOwnedByActiveUser = fantasy.Bool(owned)
+		team.Managers = append([]fantasy.Manager{}, t.Managers...)
+		for i := range team.Managers {
+			team.Managers[i].IsActiveUser = fantasy.Bool(team.Managers[i].Guid == s.Model.Guid)
+		}
+		if seg.out("roster") {
+			team.Roster = s.roster(t.Key)
+		}
+		if !seg.out("standings") {
+			team.Standings = nil
+		}
+		out = append(out, team)
+	}
+	return out
+}
+
+// roster returns the current roster of the team with key.
+func (s *Server) roster(key string) *fantasy.Roster {
+	_, t := s.Model.team(key)
+	if t == nil {
+		return nil
+	}
+
+	today, _ := fantasy.ParseDate(s.now().Format(fantasy.DateFormat))
+	r := &fantasy.Roster{CoverageType: "date", Date: today, IsEditable: true}
+	for _, p := range t.Players {
+		p.SelectedPosition.CoverageType, p.SelectedPosition.Date = "date", today
+		r.Players = append(r.Players, p)
+	}
+	return r
+}
+
+// players returns the league's players matching the player_keys, status, position and search parameters.
+func (s *Server) players(seg segment, l *League) []fantasy.Player {
+	keys := seg.keys("player_keys")
+	status := seg.params["status"]
+	position := seg.params["position"]
+	search := strings.ToLower(seg.params["search"])
+
+	var players []fantasy.Player
+	for _, p := range l.Players {
+		if keys != nil && !contains(keys, p.Key) {
+			continue
+		}
+		if position != "" && !contains(p.EligiblePositions, position) {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(p.Name.Full), search) {
+			continue
+		}
+
+		taken, waivers := l.owner(p.Key) != nil, l.Waivers[p.Key]
+		switch status {
+		case "A":
+			if taken {
+				continue
+			}
+		case "FA":
+			if taken || waivers {
+				continue
+			}
+		case "W":
+			if taken || !waivers {
+				continue
+			}
+		case "T":
+			if !taken {
+				continue
+			}
+		}
+		players = append(players, p)
+	}
+	return players
+}
+
+// transactionsOf returns the league's transactions matching the transaction_keys, types and team_key parameters,
+// paged by start and count.
+func transactionsOf(seg segment, l *League) []fantasy.Transaction {
+	keys := seg.keys("transaction_keys")
+	types := seg.keys("types")
+	team := seg.params["team_key"]
+
+	var out []fantasy.Transaction
+	for _, t := range l.Transactions {
+		if keys != nil && !contains(keys, t.Key) {
+			continue
+		}
+		// like Yahoo, the add and drop types include add/drops.
+		if types != nil && !contains(types, t.Type) && !(t.Type == "add/drop" && (contains(types, "add") || contains(types, "drop"))) {
+			continue
+		}
+		if team != "" && !involves(t, team) {
+			continue
+		}
+		out = append(out, t)
+	}
+
+	lo, hi := pageRange(len(out), seg)
+	return out[lo:hi]
+}
+
+// involves reports whether a transaction moves players to or from the team.
+func involves(t fantasy.Transaction, teamKey string) bool {
+	if t.TraderTeamKey == teamKey || t.TradeeTeamKey == teamKey {
+		return true
+	}
+	for _, p := range t.Players {
+		if p.TransactionData.SourceTeamKey == teamKey || p.TransactionData.DestinationTeamKey == teamKey {
+			return true
+		}
+	}
+	return false
+}
+
+// page returns the players selected by the start and count parameters.
+func page(players []fantasy.Player, seg segment) []fantasy.Player {
+	lo, hi := pageRange(len(players), seg)
+	return players[lo:hi]
+}
+
+// pageRange returns the bounds of count items from start out of n, every item from start when count is zero.
+func pageRange(n int, seg segment) (int, int) {
+	start, count := seg.int("start"), seg.int("count")
+	if start > n {
+		start = n
+	}
+	if count <= 0 || start+count > n {
+		return start, n
+	}
+	return start, start + count
+}
+
+// appendNew appends the players which are not already in list.
+func appendNew(list, players []fantasy.Player) []fantasy.Player {
+	for _, p := range players {
+		found := false
+		for _, q := range list {
+			found = found || q.Key == p.Key
+		}
+		if !found {
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
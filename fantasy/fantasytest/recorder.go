@@ -0,0 +1,278 @@
+package fantasytest
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Mode is whether a Recorder saves or serves fixtures.
+type Mode int
+
+const (
+	// Replay serves saved fixtures and fails requests which have none.
+	Replay Mode = iota
+	// Record sends requests through the base transport and saves each response as a fixture.
+	Record
+)
+
+// Recorder is an http.RoundTripper which records fantasy api responses into a fixtures directory
+// and replays them, so tests captured from real leagues run without Yahoo.
+//
+// Each fixture is the response body alone, like the files of fantasy/test, named after the request
+// e.g. get_league_357.l.86753_players;status=FA.xml. Responses other than 200 keep their status in the name
+// e.g. get_league_357.l.1.404.xml, and requests with a body add a hash of it. The request line and body
+// are saved next to the fixture with a .request extension, they are not needed to replay it.
+// Tokens, emails and user guids are scrubbed from fixtures, requests and fixture names.
+//
+// Only requests to the fantasy api are recorded and replayed, requests to other hosts such as token
+// refreshes are sent through Base in both modes.
+type Recorder struct {
+	// Dir is the fixtures directory, it is created when recording.
+	Dir string
+	// Mode is Record or Replay.
+	Mode Mode
+	// Base sends requests when recording, http.DefaultTransport when nil.
+	// It must authorize requests e.g. an oauth2.Transport.
+	Base http.RoundTripper
+	// Scrub optionally rewrites fixtures and requests after the built-in scrubbing e.g. to hide team names.
+	Scrub func([]byte) []byte
+}
+
+// NewRecorder creates a recorder of the fixtures in dir.
+func NewRecorder(dir string, mode Mode) *Recorder {
+	return &Recorder{Dir: dir, Mode: mode}
+}
+
+// RoundTrip records or replays the response to r.
+func (rec *Recorder) RoundTrip(r *http.Request) (*http.Response, error) {
+	base := rec.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if r.URL.Host != apiHost {
+		return base.RoundTrip(r)
+	}
+
+	var body []byte
+	if r.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(r.Body); err != nil {
+			return nil, err
+		}
+		r.Body.Close()
+	}
+	name := fixtureName(r, body)
+
+	if rec.Mode == Replay {
+		return rec.replay(r, name)
+	}
+
+	r2 := new(http.Request)
+	*r2 = *r
+	if body != nil {
+		r2.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	resp, err := base.RoundTrip(r2)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+	request := append([]byte(r.Method+" "+r.URL.String()+"\n\n"), body...)
+	if err := rec.save(name, resp.StatusCode, data, request); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// save writes a scrubbed response body as a fixture, and the request next to it.
+func (rec *Recorder) save(name string, status int, data, request []byte) error {
+	if err := os.MkdirAll(rec.Dir, 0755); err != nil {
+		return err
+	}
+
+	data, request = scrub(data), scrub(request)
+	if rec.Scrub != nil {
+		data, request = rec.Scrub(data), rec.Scrub(request)
+	}
+
+	base, ext := splitExt(name)
+	if err := ioutil.WriteFile(filepath.Join(rec.Dir, base+".request"), request, 0644); err != nil {
+		return err
+	}
+	for _, old := range fixtureFiles(rec.Dir, name) {
+		os.Remove(old)
+	}
+	if status != http.StatusOK {
+		base += "." + strconv.Itoa(status)
+	}
+	return ioutil.WriteFile(filepath.Join(rec.Dir, base+ext), data, 0644)
+}
+
+// replay serves the fixture named name, it errors when there is none.
+func (rec *Recorder) replay(r *http.Request, name string) (*http.Response, error) {
+	files := fixtureFiles(rec.Dir, name)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("No fixture for %s %s, expected %s", r.Method, r.URL, filepath.Join(rec.Dir, name))
+	}
+
+	data, err := ioutil.ReadFile(files[0])
+	if err != nil {
+		return nil, err
+	}
+
+	status := http.StatusOK
+	base, ext := splitExt(name)
+	if saved := strings.TrimSuffix(filepath.Base(files[0]), ext); saved != base {
+		status, _ = strconv.Atoi(strings.TrimPrefix(saved, base+"."))
+	}
+
+	contentType := "application/xml; charset=UTF-8"
+	if ext == ".json" {
+		contentType = "application/json; charset=UTF-8"
+	}
+	return &http.Response{
+		Status:        strconv.Itoa(status) + " " + http.StatusText(status),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {contentType}},
+		Body:          ioutil.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       r,
+	}, nil
+}
+
+// fixtureFiles returns the fixtures saved for name with any status.
+func fixtureFiles(dir, name string) []string {
+	var files []string
+	if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+		files = append(files, filepath.Join(dir, name))
+	}
+	base, ext := splitExt(name)
+	statuses, _ := filepath.Glob(filepath.Join(dir, globEscape(base)+".[0-9][0-9][0-9]"+ext))
+	return append(files, statuses...)
+}
+
+// maxFixtureName keeps fixture names within the limits of common file systems.
+const maxFixtureName = 200
+
+// fixtureName names the fixture of a request from its method, api path and query, and a hash of its body.
+// Guids, emails and tokens in the path and query are scrubbed like fixtures.
+func fixtureName(r *http.Request, body []byte) string {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/fantasy/v2"), "/")
+	path = string(scrub([]byte(path)))
+	name := strings.ToLower(r.Method) + "_" + strings.Replace(path, "/", "_", -1)
+
+	ext := ".xml"
+	query := r.URL.Query()
+	if query.Get("format") == "json" {
+		ext = ".json"
+	}
+	query.Del("format")
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		name += "_" + string(scrub([]byte(k+"="+strings.Join(query[k], ","))))
+	}
+
+	if len(body) > 0 {
+		name += "_" + shortHash(body)
+	}
+	if len(name) > maxFixtureName {
+		name = name[:maxFixtureName-9] + "_" + shortHash([]byte(name))
+	}
+	return strings.NewReplacer(":", "-", "\\", "-", "*", "-", "?", "-", "\"", "-", "<", "-", ">", "-", "|", "-").Replace(name) + ext
+}
+
+// shortHash returns the first 8 hex digits of the sha1 of b.
+func shortHash(b []byte) string {
+	sum := sha1.Sum(b)
+	return hex.EncodeToString(sum[:4])
+}
+
+// splitExt splits a fixture name into its base and extension.
+func splitExt(name string) (string, string) {
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext), ext
+}
+
+// globEscape escapes the glob meta characters of a file name.
+func globEscape(s string) string {
+	return strings.NewReplacer("[", `\[`, "]", `\]`).Replace(s)
+}
+
+var (
+	// tokenPattern matches OAuth tokens and signatures in query strings, json and xml.
+	tokenPattern = regexp.MustCompile(`(?i)((?:access_token|refresh_token|id_token|oauth_token|oauth_signature|oauth_session_handle|xoauth_yahoo_guid)(?:"\s*:\s*"|=|>))[^&"<\s]+`)
+	// bearerPattern matches bearer authorizations.
+	bearerPattern = regexp.MustCompile(`(Bearer\s+)[^\s"<]+`)
+	// guidPattern matches the guids of users in xml and json.
+	guidPattern = regexp.MustCompile(`(<guid>|"guid"\s*:\s*")([^<"]+)`)
+	// guidParamPattern matches the guids of users in api paths e.g. users;guids=A,B.
+	guidParamPattern = regexp.MustCompile(`(guids?=)([^;&/_]+)`)
+	// emailPattern matches email addresses.
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// pseudonymPattern matches the pseudonyms of guids and emails.
+	pseudonymPattern = regexp.MustCompile(`^(GUID[0-9A-F]{8}|manager-[0-9a-f]{8}@example\.com)$`)
+)
+
+// scrub replaces tokens, guids and emails. Each guid and email is replaced by a pseudonym derived from a hash
+// of it, so a user keeps the same pseudonym in every fixture, including fixtures recorded by other processes.
+func scrub(data []byte) []byte {
+	data = tokenPattern.ReplaceAll(data, []byte("${1}SCRUBBED"))
+	data = bearerPattern.ReplaceAll(data, []byte("${1}SCRUBBED"))
+	data = guidPattern.ReplaceAllFunc(data, func(m []byte) []byte {
+		sub := guidPattern.FindSubmatch(m)
+		return []byte(string(sub[1]) + GuidPseudonym(string(sub[2])))
+	})
+	data = guidParamPattern.ReplaceAllFunc(data, func(m []byte) []byte {
+		sub := guidParamPattern.FindSubmatch(m)
+		guids := strings.Split(string(sub[2]), ",")
+		for i, g := range guids {
+			guids[i] = GuidPseudonym(g)
+		}
+		return []byte(string(sub[1]) + strings.Join(guids, ","))
+	})
+	return emailPattern.ReplaceAllFunc(data, func(m []byte) []byte {
+		return []byte(EmailPseudonym(string(m)))
+	})
+}
+
+// GuidPseudonym returns the guid a Recorder saves in place of guid, GUID and a hash of guid.
+// Pseudonyms are kept as they are.
+func GuidPseudonym(guid string) string {
+	if pseudonymPattern.MatchString(guid) {
+		return guid
+	}
+	return "GUID" + strings.ToUpper(shortHash([]byte(guid)))
+}
+
+// EmailPseudonym returns the email address a Recorder saves in place of email, manager- and a hash of email
+// at example.com. Pseudonyms are kept as they are.
+func EmailPseudonym(email string) string {
+	if pseudonymPattern.MatchString(email) {
+		return email
+	}
+	return "manager-" + shortHash([]byte(strings.ToLower(email))) + "@example.com"
+}
@@ -0,0 +1,119 @@
+package fantasytest_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/muswell/yahoo/fantasy"
+	"github.com/muswell/yahoo/fantasy/fantasytest"
+)
+
+func TestRecordReplay(t *testing.T) {
+	model := fantasytest.NewModel()
+	model.Guid = "KE6UKP7ZRQHMJFQXWAG7UYUKWE"
+	for _, team := range model.Leagues[0].Teams {
+		team.Managers[0].Guid = strings.Replace(team.Managers[0].Guid, "GUID", "REALGUID", 1)
+		team.Managers[0].Email = "manager" + team.Managers[0].ManagerID + "@yahoo.com"
+	}
+	model.Leagues[0].Teams[0].Managers[0].Guid = model.Guid
+	s := fantasytest.NewServer(model)
+	defer s.Close()
+
+	dir, err := ioutil.TempDir("", "fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	rec := fantasytest.NewRecorder(dir, fantasytest.Record)
+	rec.Base = s.Client().Transport
+	client := &http.Client{Transport: rec}
+
+	query := &fantasy.TeamQueryBuilder{LeagueQB: &fantasy.LeagueQueryBuilder{Keys: []string{"357.l.86753"}}}
+	recorded, err := query.Get(client)
+	if err != nil {
+		t.Fatalf("Unexpected record error: %v", err)
+	}
+	if recorded[0].Managers[0].Guid != model.Guid {
+		t.Errorf("Recording changed the response, got guid %s", recorded[0].Managers[0].Guid)
+	}
+	if _, err := (&fantasy.LeagueQueryBuilder{Keys: []string{"357.l.1"}}).Get(client); err == nil {
+		t.Errorf("Expected an error recording an unknown league")
+	}
+	guidURL := "https://fantasysports.yahooapis.com/fantasy/v2/users;guids=" + model.Guid + "/games"
+	resp, err := client.Get(guidURL)
+	if err != nil {
+		t.Fatalf("Unexpected record error: %v", err)
+	}
+	resp.Body.Close()
+	// requests to other hosts are not recorded.
+	resp, err = client.Get(s.URL + "/fantasy/v2/league/357.l.86753")
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected response to another host %v, %v", resp, err)
+	}
+	resp.Body.Close()
+
+	guid := fantasytest.GuidPseudonym(model.Guid)
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	var names []string
+	for _, f := range files {
+		names = append(names, filepath.Base(f))
+	}
+	expected := []string{
+		"get_leagues;league_keys=357.l.1.404.xml",
+		"get_leagues;league_keys=357.l.1.request",
+		"get_leagues;league_keys=357.l.86753_teams.request",
+		"get_leagues;league_keys=357.l.86753_teams.xml",
+		"get_users;guids=" + guid + "_games.400.xml",
+		"get_users;guids=" + guid + "_games.request",
+	}
+	if strings.Join(names, " ") != strings.Join(expected, " ") {
+		t.Fatalf("Recorded fixtures %v, expected %v", names, expected)
+	}
+
+	request, _ := ioutil.ReadFile(filepath.Join(dir, expected[5]))
+	if string(request) != "GET https://fantasysports.yahooapis.com/fantasy/v2/users;guids="+guid+"/games\n\n" {
+		t.Errorf("Recorded incorrect request %q", request)
+	}
+	fixture, _ := ioutil.ReadFile(filepath.Join(dir, expected[3]))
+	for _, secret := range []string{model.Guid, "REALGUID2", "@yahoo.com"} {
+		if strings.Contains(string(fixture), secret) {
+			t.Errorf("Fixture was not scrubbed of %s:\n%s", secret, fixture)
+		}
+	}
+
+	client = &http.Client{Transport: fantasytest.NewRecorder(dir, fantasytest.Replay)}
+	replayed, err := query.Get(client)
+	if err != nil {
+		t.Fatalf("Unexpected replay error: %v", err)
+	}
+	if len(replayed) != 2 || replayed[0].Key != recorded[0].Key {
+		t.Errorf("Replayed incorrect teams %+v", replayed)
+	}
+	// pseudonyms are derived from the guid and email so every recording gives a user the same one.
+	if m := replayed[0].Managers[0]; m.Guid != guid || m.Email != fantasytest.EmailPseudonym("manager1@yahoo.com") {
+		t.Errorf("Replayed incorrect scrubbed manager %+v", m)
+	}
+	if m := replayed[1].Managers[0]; m.Guid != fantasytest.GuidPseudonym("REALGUID2") || m.Email != fantasytest.EmailPseudonym("manager2@yahoo.com") {
+		t.Errorf("Replayed incorrect scrubbed manager %+v", m)
+	}
+	if guid != "GUID"+strings.ToUpper(guid[4:]) || len(guid) != 12 || guid == fantasytest.GuidPseudonym("REALGUID2") {
+		t.Errorf("Incorrect guid pseudonym %s", guid)
+	}
+	if _, err := client.Get(guidURL); err != nil {
+		t.Errorf("Unexpected replay error for a guid in the url: %v", err)
+	}
+
+	_, err = (&fantasy.LeagueQueryBuilder{Keys: []string{"357.l.1"}}).Get(client)
+	if err == nil || strings.Contains(err.Error(), "No fixture") {
+		t.Errorf("Expected the recorded error, got %v", err)
+	}
+	_, err = (&fantasy.LeagueQueryBuilder{Keys: []string{"357.l.2"}}).Get(client)
+	if err == nil || !strings.Contains(err.Error(), "No fixture") {
+		t.Errorf("Expected an unmatched request error, got %v", err)
+	}
+}
@@ -0,0 +1,397 @@
+// Package fantasytest provides a fake Yahoo fantasy API server for tests.
+//
+// The server answers the read requests of the fantasy query builders and the write requests of fantasy.Send
+// from an in-memory Model, write requests change the model so tests can check their effect with later reads.
+// Responses are xml, or Yahoo's json layout for requests with format=json e.g. from query builders with
+// FormatJSON. Every request must carry the server's bearer token.
+package fantasytest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/muswell/yahoo/fantasy"
+)
+
+// DefaultToken is the bearer token of servers created by NewServer.
+const DefaultToken = "fantasytest-token"
+
+// apiHost is the host of the Yahoo fantasy api, requests to it are sent to the fake server by Server.Transport.
+const apiHost = "fantasysports.yahooapis.com"
+
+// Server is a fake Yahoo fantasy API serving a Model.
+type Server struct {
+	*httptest.Server
+	// Token is the bearer token every request must carry.
+	Token string
+	// Model is the data served, lock the server while reading or changing it.
+	Model *Model
+	// Now returns the time of new transactions and rosters, time.Now when nil.
+	Now func() time.Time
+
+	mu sync.Mutex
+}
+
+// NewServer starts a server for model, NewModel() when model is nil. Close the server when done.
+func NewServer(model *Model) *Server {
+	if model == nil {
+		model = NewModel()
+	}
+	s := &Server{Token: DefaultToken, Model: model}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// Lock locks the model against concurrent requests.
+func (s *Server) Lock() {
+	s.mu.Lock()
+}
+
+// Unlock unlocks the model.
+func (s *Server) Unlock() {
+	s.mu.Unlock()
+}
+
+// Transport returns a RoundTripper which sends Yahoo fantasy api requests to the server through base,
+// http.DefaultTransport when base is nil. It does not add the bearer token, wrap it with an oauth2.Transport
+// or use Client for that.
+func (s *Server) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	target, _ := url.Parse(s.URL)
+	return &rewriteTransport{target: target, base: base}
+}
+
+// Client returns a client which sends Yahoo fantasy api requests to the server with its token.
+func (s *Server) Client() *http.Client {
+	return &http.Client{Transport: &bearerTransport{token: s.Token, base: s.Transport(nil)}}
+}
+
+// ServeHTTP checks the bearer token and serves the request from the model.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+s.Token {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="yahooapis.com", error="invalid_token"`)
+		writeError(w, r, http.StatusUnauthorized, `Please provide valid credentials. OAuth oauth_problem="token_rejected", realm="yahooapis.com"`)
+		return
+	}
+
+	if f := r.URL.Query().Get("format"); f != "" && f != "xml" && f != "json" {
+		writeError(w, r, http.StatusBadRequest, "Unknown format "+f)
+		return
+	}
+
+	segs, err := parsePath(r.URL.Path)
+	if err != nil {
+		writeError(w, r, errorStatus(err), err.Error())
+		return
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	var c *content
+	status := http.StatusOK
+	switch r.Method {
+	case "GET":
+		c, err = s.read(segs)
+	case "PUT", "POST":
+		body, _ := ioutil.ReadAll(r.Body)
+		c, status, err = s.write(r.Method, segs, body)
+	default:
+		err = errorf(http.StatusMethodNotAllowed, "Method %s is not allowed", r.Method)
+	}
+
+	if err != nil {
+		writeError(w, r, errorStatus(err), err.Error())
+		return
+	}
+	writeContent(w, r, status, c)
+}
+
+// now returns the current time of the server.
+func (s *Server) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// statusError is an error with the http status it is served with.
+type statusError struct {
+	status int
+	msg    string
+}
+
+func (e *statusError) Error() string {
+	return e.msg
+}
+
+// errorf creates an error served with status.
+func errorf(status int, format string, a ...interface{}) error {
+	return &statusError{status, fmt.Sprintf(format, a...)}
+}
+
+// errorStatus returns the http status of an error, 400 for errors without one.
+func errorStatus(err error) int {
+	if e, ok := err.(*statusError); ok {
+		return e.status
+	}
+	return http.StatusBadRequest
+}
+
+// segment is a resource of an api path e.g. leagues;league_keys=357.l.86753 or league/357.l.86753
+type segment struct {
+	name string
+	// key is the key of a singular resource, it is added to params as the resource's keys parameter.
+	key    string
+	params map[string]string
+}
+
+// keys returns the comma separated values of a parameter.
+func (s segment) keys(param string) []string {
+	if s.params[param] == "" {
+		return nil
+	}
+	return strings.Split(s.params[param], ",")
+}
+
+// int returns the integer value of a parameter, zero when it is missing.
+func (s segment) int(param string) int {
+	i, _ := strconv.Atoi(s.params[param])
+	return i
+}
+
+// out reports whether the out parameter includes the subresource.
+func (s segment) out(subresource string) bool {
+	for _, o := range s.keys("out") {
+		if o == subresource {
+			return true
+		}
+	}
+	return false
+}
+
+// singular maps singular resources to their collection and keys parameter.
+var singular = map[string][2]string{
+	"game":        {"games", "game_keys"},
+	"league":      {"leagues", "league_keys"},
+	"team":        {"teams", "team_keys"},
+	"player":      {"players", "player_keys"},
+	"transaction": {"transactions", "transaction_keys"},
+}
+
+// parsePath splits an api path into its resources, singular resources become their collection.
+func parsePath(path string) ([]segment, error) {
+	path = strings.Trim(strings.TrimPrefix(path, "/fantasy/v2"), "/")
+	if path == "" {
+		return nil, errorf(http.StatusNotFound, "Missing fantasy api resource")
+	}
+
+	parts := strings.Split(path, "/")
+	var segs []segment
+	for i := 0; i < len(parts); i++ {
+		fields := strings.Split(parts[i], ";")
+		seg := segment{name: fields[0], params: map[string]string{}}
+		for _, f := range fields[1:] {
+			kv := strings.SplitN(f, "=", 2)
+			if len(kv) == 2 {
+				seg.params[kv[0]] = kv[1]
+			}
+		}
+
+		for _, p := range []string{"start", "count"} {
+			if v, ok := seg.params[p]; ok {
+				if i, err := strconv.Atoi(v); err != nil || i < 0 {
+					return nil, errorf(http.StatusBadRequest, "Bad %s %s", p, v)
+				}
+			}
+		}
+
+		if s, ok := singular[seg.name]; ok {
+			if i+1 >= len(parts) {
+				return nil, errorf(http.StatusBadRequest, "Missing %s key", seg.name)
+			}
+			i++
+			seg.name, seg.key = s[0], parts[i]
+			seg.params[s[1]] = seg.key
+		}
+		segs = append(segs, seg)
+	}
+	return segs, nil
+}
+
+// content is the fantasy_content node of every response.
+type content struct {
+	XMLName      xml.Name             `xml:"fantasy_content"`
+	XMLNS        string               `xml:"xmlns,attr"`
+	YahooNS      string               `xml:"xmlns:yahoo,attr"`
+	URI          string               `xml:"yahoo:uri,attr"`
+	Time         string               `xml:"time,attr"`
+	Copyright    string               `xml:"copyright,attr"`
+	RefreshRate  int                  `xml:"refresh_rate,attr"`
+	Users        *usersXML            `xml:"users"`
+	Games        *gamesXML            `xml:"games"`
+	Leagues      *leaguesXML          `xml:"leagues"`
+	Teams        *teamsXML            `xml:"teams"`
+	Players      *playersXML          `xml:"players"`
+	Transactions *transactionsXML     `xml:"transactions"`
+	Transaction  *fantasy.Transaction `xml:"transaction"`
+}
+
+type usersXML struct {
+	Count int        `xml:"count,attr"`
+	Users []*userXML `xml:"user"`
+}
+
+type userXML struct {
+	Guid  string    `xml:"guid"`
+	Games *gamesXML `xml:"games"`
+}
+
+type gamesXML struct {
+	Count int        `xml:"count,attr"`
+	Games []*gameXML `xml:"game"`
+}
+
+type gameXML struct {
+	fantasy.Game
+	Leagues *leaguesXML `xml:"leagues"`
+	Players *playersXML `xml:"players"`
+}
+
+type leaguesXML struct {
+	Count   int          `xml:"count,attr"`
+	Leagues []*leagueXML `xml:"league"`
+}
+
+type leagueXML struct {
+	fantasy.League
+	Teams        *teamsXML        `xml:"teams"`
+	Players      *playersXML      `xml:"players"`
+	Transactions *transactionsXML `xml:"transactions"`
+
+	model *League
+}
+
+type teamsXML struct {
+	Count int            `xml:"count,attr"`
+	Teams []fantasy.Team `xml:"team"`
+}
+
+type playersXML struct {
+	Count   int              `xml:"count,attr"`
+	Players []fantasy.Player `xml:"player"`
+}
+
+type transactionsXML struct {
+	Count        int                   `xml:"count,attr"`
+	Transactions []fantasy.Transaction `xml:"transaction"`
+}
+
+// writeContent encodes c as the response.
+func writeContent(w http.ResponseWriter, r *http.Request, status int, c *content) {
+	c.XMLNS = "http://fantasysports.yahooapis.com/fantasy/v2/base.rng"
+	c.YahooNS = "http://www.yahooapis.com/v1/base.rng"
+	c.URI = "http://" + apiHost + r.URL.Path
+	c.Time = "1ms"
+	c.Copyright = "Data provided by fantasytest"
+	c.RefreshRate = 60
+
+	body, err := xml.MarshalIndent(c, "", "  ")
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeBody(w, r, status, body)
+}
+
+// writeError writes a Yahoo error response.
+func writeError(w http.ResponseWriter, r *http.Request, status int, description string) {
+	e := struct {
+		XMLName     xml.Name `xml:"error"`
+		XMLNS       string   `xml:"xmlns,attr"`
+		YahooNS     string   `xml:"xmlns:yahoo,attr"`
+		URI         string   `xml:"yahoo:uri,attr"`
+		Description string   `xml:"description"`
+		Detail      string   `xml:"detail"`
+	}{
+		XMLNS:       "http://www.yahooapis.com/v1/base.rng",
+		YahooNS:     "http://www.yahooapis.com/v1/base.rng",
+		URI:         "http://" + apiHost + r.URL.Path,
+		Description: description,
+	}
+
+	body, _ := xml.MarshalIndent(e, "", "  ")
+	writeBody(w, r, status, body)
+}
+
+// writeBody writes an xml response body, translated to json when the request asks for format=json.
+func writeBody(w http.ResponseWriter, r *http.Request, status int, body []byte) {
+	if r.URL.Query().Get("format") == "json" {
+		data, err := toJSON(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(status)
+		w.Write(data)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=UTF-8")
+	w.WriteHeader(status)
+	w.Write([]byte(xml.Header))
+	w.Write(body)
+}
+
+// rewriteTransport sends requests for the Yahoo fantasy api to target.
+type rewriteTransport struct {
+	target *url.URL
+	base   http.RoundTripper
+}
+
+func (t *rewriteTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.URL.Host != apiHost {
+		return t.base.RoundTrip(r)
+	}
+
+	u := *r.URL
+	u.Scheme, u.Host = t.target.Scheme, t.target.Host
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = &u
+	r2.Host = ""
+	return t.base.RoundTrip(r2)
+}
+
+// bearerTransport adds a bearer token to every request.
+type bearerTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+func (t *bearerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.Header = make(http.Header, len(r.Header)+1)
+	for k, v := range r.Header {
+		r2.Header[k] = v
+	}
+	r2.Header.Set("Authorization", "Bearer "+t.token)
+	return t.base.RoundTrip(r2)
+}
+
+// itoa formats an id.
+func itoa(i int64) string {
+	return strconv.FormatInt(i, 10)
+}
@@ -0,0 +1,184 @@
+package fantasytest_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/muswell/yahoo/fantasy"
+	"github.com/muswell/yahoo/fantasy/fantasytest"
+)
+
+func TestServeUserLeagues(t *testing.T) {
+	s := fantasytest.NewServer(nil)
+	defer s.Close()
+
+	user := &fantasy.UserQueryBuilder{ActiveUser: true}
+	games, err := (&fantasy.GameQueryBuilder{UserQB: user}).Get(s.Client())
+	if err != nil {
+		t.Fatalf("Unexpected games error: %v", err)
+	}
+	if len(games) != 1 || games[0].Code != "mlb" {
+		t.Errorf("Served incorrect games %+v", games)
+	}
+
+	leagues, err := (&fantasy.LeagueQueryBuilder{UserQB: user, Settings: true}).Get(s.Client())
+	if err != nil {
+		t.Fatalf("Unexpected leagues error: %v", err)
+	}
+	if len(leagues) != 1 || leagues[0].Key != "357.l.86753" || leagues[0].Settings == nil || len(leagues[0].Settings.RosterPositions) != 7 {
+		t.Fatalf("Served incorrect leagues %+v", leagues)
+	}
+
+	teams, err := (&fantasy.TeamQueryBuilder{LeagueQB: &fantasy.LeagueQueryBuilder{UserQB: user}}).Get(s.Client())
+	if err != nil {
+		t.Fatalf("Unexpected teams error: %v", err)
+	}
+	if len(teams) != 1 || teams[0].Key != "357.l.86753.t.1" || !bool(teams[0].IsOwnedByActiveUser) {
+		t.Errorf("Served incorrect user teams %+v", teams)
+	}
+}
+
+func TestServeRoster(t *testing.T) {
+	s := fantasytest.NewServer(nil)
+	defer s.Close()
+
+	teams, err := (&fantasy.TeamQueryBuilder{Keys: []string{"357.l.86753.t.2"}, Roster: true}).Get(s.Client())
+	if err != nil {
+		t.Fatalf("Unexpected roster error: %v", err)
+	}
+	if len(teams) != 1 || teams[0].Roster == nil {
+		t.Fatalf("Served no roster %+v", teams)
+	}
+
+	r := teams[0].Roster
+	if r.CoverageType != "date" || r.Date.IsZero() || len(r.Players) != 2 {
+		t.Fatalf("Served incorrect roster %+v", r)
+	}
+	if p := r.Players[0]; p.Key != "357.p.8658" || p.SelectedPosition.Position != "OF" {
+		t.Errorf("Served incorrect roster player %+v", p)
+	}
+	if bool(teams[0].IsOwnedByActiveUser) {
+		t.Errorf("Team 2 is not managed by the logged in user")
+	}
+}
+
+func TestServeStandings(t *testing.T) {
+	s := fantasytest.NewServer(nil)
+	defer s.Close()
+
+	league := &fantasy.LeagueQueryBuilder{Keys: []string{"357.l.86753"}}
+	teams, err := (&fantasy.TeamQueryBuilder{LeagueQB: league}).Get(s.Client())
+	if err != nil {
+		t.Fatalf("Unexpected teams error: %v", err)
+	}
+	if teams[0].Standings != nil {
+		t.Errorf("Served standings which were not requested %+v", teams[0].Standings)
+	}
+
+	teams, err = (&fantasy.TeamQueryBuilder{LeagueQB: league, Standings: true}).Get(s.Client())
+	if err != nil {
+		t.Fatalf("Unexpected standings error: %v", err)
+	}
+	if len(teams) != 2 || teams[1].Standings == nil || teams[1].Standings.Rank.Value != 2 || teams[1].Standings.PointsFor.Value != 9 {
+		t.Errorf("Served incorrect standings %+v", teams)
+	}
+}
+
+func TestServePlayers(t *testing.T) {
+	s := fantasytest.NewServer(nil)
+	defer s.Close()
+
+	league := &fantasy.LeagueQueryBuilder{Keys: []string{"357.l.86753"}}
+	tests := []struct {
+		query    fantasy.PlayerQueryBuilder
+		expected []string
+	}{
+		{fantasy.PlayerQueryBuilder{LeagueQB: league}, []string{"357.p.8967", "357.p.9105", "357.p.8658", "357.p.9116", "357.p.9573", "357.p.8875"}},
+		{fantasy.PlayerQueryBuilder{LeagueQB: league, Status: "A"}, []string{"357.p.9573", "357.p.8875"}},
+		{fantasy.PlayerQueryBuilder{LeagueQB: league, Status: "FA"}, []string{"357.p.9573"}},
+		{fantasy.PlayerQueryBuilder{LeagueQB: league, Status: "W"}, []string{"357.p.8875"}},
+		{fantasy.PlayerQueryBuilder{LeagueQB: league, Position: "SP"}, []string{"357.p.9105", "357.p.9116"}},
+		{fantasy.PlayerQueryBuilder{LeagueQB: league, Search: "trout"}, []string{"357.p.8658"}},
+		{fantasy.PlayerQueryBuilder{LeagueQB: league, Start: 4, Count: 1}, []string{"357.p.9573"}},
+	}
+
+	for _, test := range tests {
+		players, err := test.query.Get(s.Client())
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %v", test.query.Url(), err)
+			continue
+		}
+		var keys []string
+		for _, p := range players {
+			keys = append(keys, p.Key)
+		}
+		if len(keys) != len(test.expected) {
+			t.Errorf("%s served %v, expected %v", test.query.Url(), keys, test.expected)
+			continue
+		}
+		for i := range keys {
+			if keys[i] != test.expected[i] {
+				t.Errorf("%s served %v, expected %v", test.query.Url(), keys, test.expected)
+				break
+			}
+		}
+	}
+}
+
+func TestServeErrors(t *testing.T) {
+	s := fantasytest.NewServer(nil)
+	defer s.Close()
+
+	_, err := (&fantasy.LeagueQueryBuilder{Keys: []string{"357.l.86753"}}).Get(&http.Client{Transport: s.Transport(nil)})
+	if err == nil {
+		t.Errorf("Expected an error without a token")
+	}
+
+	_, err = (&fantasy.LeagueQueryBuilder{Keys: []string{"357.l.1"}}).Get(s.Client())
+	if err == nil {
+		t.Errorf("Expected an error for an unknown league")
+	}
+
+	// a negative start is a bad request rather than a dropped connection.
+	resp, err := s.Client().Get("https://fantasysports.yahooapis.com/fantasy/v2/league/357.l.86753/players;start=-1")
+	if err != nil {
+		t.Fatalf("Unexpected request error for a negative start: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Served %d for a negative start, expected %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
+
+func TestServeBatchedLeaguesInKeyOrder(t *testing.T) {
+	model := fantasytest.NewModel()
+	for _, id := range []string{"2", "3", "4"} {
+		l := *model.Leagues[0]
+		l.Key = "357.l." + id
+		model.Leagues = append(model.Leagues, &l)
+	}
+	s := fantasytest.NewServer(model)
+	defer s.Close()
+
+	// the server returns leagues in model order, so every chunk comes back reversed.
+	keys := []string{"357.l.4", "357.l.3", "357.l.2", "357.l.86753"}
+	q := &fantasy.LeagueQueryBuilder{Keys: keys, Batching: fantasy.Batching{MaxKeys: 2}}
+	leagues, err := q.Get(s.Client())
+	if err != nil {
+		t.Fatalf("Unexpected batched leagues error: %v", err)
+	}
+
+	var got []string
+	for _, l := range leagues {
+		got = append(got, l.Key)
+	}
+	if len(got) != len(keys) {
+		t.Fatalf("Served leagues %v, expected %v", got, keys)
+	}
+	for i := range keys {
+		if got[i] != keys[i] {
+			t.Errorf("Served leagues %v, expected the key order %v", got, keys)
+			break
+		}
+	}
+}
@@ -0,0 +1,466 @@
+package fantasytest
+
+import (
+	"encoding/xml"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/muswell/yahoo/fantasy"
+)
+
+// rosterRequest is the body of a roster edit.
+type rosterRequest struct {
+	XMLName xml.Name `xml:"fantasy_content"`
+	Roster  struct {
+		CoverageType string                   `xml:"coverage_type"`
+		Week         int64                    `xml:"week"`
+		Date         string                   `xml:"date"`
+		Players      []fantasy.RosterPosition `xml:"players>player"`
+	} `xml:"roster"`
+}
+
+// transactionRequest is the body of a new transaction or a trade response.
+type transactionRequest struct {
+	XMLName     xml.Name `xml:"fantasy_content"`
+	Transaction struct {
+		Key           string       `xml:"transaction_key"`
+		Type          string       `xml:"type"`
+		Action        string       `xml:"action"`
+		FAABBid       *int64       `xml:"faab_bid"`
+		TraderTeamKey string       `xml:"trader_team_key"`
+		TradeeTeamKey string       `xml:"tradee_team_key"`
+		Player        *playerMove  `xml:"player"`
+		Players       []playerMove `xml:"players>player"`
+	} `xml:"transaction"`
+}
+
+// playerMove is a player of a transaction request.
+type playerMove struct {
+	PlayerKey string `xml:"player_key"`
+	Data      struct {
+		Type               string `xml:"type"`
+		SourceTeamKey      string `xml:"source_team_key"`
+		DestinationTeamKey string `xml:"destination_team_key"`
+	} `xml:"transaction_data"`
+}
+
+// write applies a PUT or POST of the resources in segs to the model.
+func (s *Server) write(method string, segs []segment, body []byte) (*content, int, error) {
+	switch {
+	case method == "PUT" && len(segs) == 2 && segs[0].name == "teams" && segs[0].key != "" && segs[1].name == "roster":
+		var r rosterRequest
+		if err := xml.Unmarshal(body, &r); err != nil {
+			return nil, 0, errorf(http.StatusBadRequest, "Invalid roster: %v", err)
+		}
+		if err := s.editRoster(segs[0].key, r); err != nil {
+			return nil, 0, err
+		}
+		return &content{}, http.StatusOK, nil
+
+	case method == "POST" && len(segs) == 2 && segs[0].name == "leagues" && segs[0].key != "" && segs[1].name == "transactions":
+		var r transactionRequest
+		if err := xml.Unmarshal(body, &r); err != nil {
+			return nil, 0, errorf(http.StatusBadRequest, "Invalid transaction: %v", err)
+		}
+		t, err := s.addTransaction(segs[0].key, r)
+		if err != nil {
+			return nil, 0, err
+		}
+		return &content{Transaction: t}, http.StatusCreated, nil
+
+	case method == "PUT" && len(segs) == 1 && segs[0].name == "transactions" && segs[0].key != "":
+		var r transactionRequest
+		if err := xml.Unmarshal(body, &r); err != nil {
+			return nil, 0, errorf(http.StatusBadRequest, "Invalid transaction: %v", err)
+		}
+		t, err := s.respondTrade(segs[0].key, r)
+		if err != nil {
+			return nil, 0, err
+		}
+		return &content{Transaction: t}, http.StatusOK, nil
+	}
+	return nil, 0, errorf(http.StatusMethodNotAllowed, "fantasytest does not serve %s of this resource", method)
+}
+
+// editRoster moves players of a team managed by the logged in user to new positions.
+// Each position must be one the player is eligible for and the league's roster positions must not overflow.
+func (s *Server) editRoster(key string, r rosterRequest) error {
+	l, t := s.Model.team(key)
+	if t == nil {
+		return errorf(http.StatusNotFound, "Team %s not found", key)
+	}
+	if !t.managedBy(s.Model.Guid) {
+		return errorf(http.StatusForbidden, "You cannot edit the roster of team %s", key)
+	}
+
+	players := append([]fantasy.RosterPlayer{}, t.Players...)
+	for _, p := range r.Roster.Players {
+		i := t.index(p.PlayerKey)
+		if i < 0 {
+			return errorf(http.StatusBadRequest, "Player %s is not on team %s", p.PlayerKey, key)
+		}
+		if !contains(players[i].EligiblePositions, p.Position) {
+			return errorf(http.StatusBadRequest, "Player %s cannot play %s", p.PlayerKey, p.Position)
+		}
+		players[i].SelectedPosition.Position = p.Position
+	}
+
+	if l.Settings != nil && len(l.Settings.RosterPositions) > 0 {
+		used := map[string]int64{}
+		for _, p := range players {
+			used[p.SelectedPosition.Position]++
+		}
+		for _, rp := range l.Settings.RosterPositions {
+			if used[rp.Position] > rp.Count {
+				return errorf(http.StatusBadRequest, "Too many players at %s, the league has %d", rp.Position, rp.Count)
+			}
+			delete(used, rp.Position)
+		}
+		for position := range used {
+			return errorf(http.StatusBadRequest, "The league has no %s position", position)
+		}
+	}
+
+	t.Players = players
+	return nil
+}
+
+// addTransaction creates an add, drop, add/drop or pending trade in the league with key.
+func (s *Server) addTransaction(key string, r transactionRequest) (*fantasy.Transaction, error) {
+	l := s.Model.league(key)
+	if l == nil {
+		return nil, errorf(http.StatusNotFound, "League %s not found", key)
+	}
+
+	moves := r.Transaction.Players
+	if r.Transaction.Player != nil {
+		moves = append(moves, *r.Transaction.Player)
+	}
+
+	var t fantasy.Transaction
+	var kind string
+	var err error
+	switch r.Transaction.Type {
+	case "add", "drop", "add/drop":
+		t, err = s.addDrop(l, r.Transaction.Type, r.Transaction.FAABBid, moves)
+		kind = "tr"
+		if t.Type == "waiver" {
+			kind = "w.c"
+		}
+	case "pending_trade":
+		t, err = s.proposeTrade(l, r.Transaction.TraderTeamKey, r.Transaction.TradeeTeamKey, moves)
+		kind = "pt"
+	default:
+		return nil, errorf(http.StatusBadRequest, "Unknown transaction type %q", r.Transaction.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t.ID = int64(len(l.Transactions) + 1)
+	t.Key = l.Key + "." + kind + "." + itoa(t.ID)
+	t.Timestamp = fantasy.Timestamp(s.now().Truncate(time.Second))
+	l.Transactions = append([]fantasy.Transaction{t}, l.Transactions...)
+	return &t, nil
+}
+
+// addDrop checks an add, drop or add/drop of a team managed by the logged in user and applies it,
+// adding a player on waivers makes a pending waiver claim instead.
+func (s *Server) addDrop(l *League, typ string, bid *int64, moves []playerMove) (fantasy.Transaction, error) {
+	var add, drop *playerMove
+	for i := range moves {
+		switch moves[i].Data.Type {
+		case "add":
+			add = &moves[i]
+		case "drop":
+			drop = &moves[i]
+		}
+	}
+	if (add != nil) != (typ != "drop") || (drop != nil) != (typ != "add") {
+		return fantasy.Transaction{}, errorf(http.StatusBadRequest, "A %s transaction needs matching players", typ)
+	}
+
+	var teamKey string
+	if add != nil {
+		teamKey = add.Data.DestinationTeamKey
+	}
+	if drop != nil {
+		if teamKey != "" && teamKey != drop.Data.SourceTeamKey {
+			return fantasy.Transaction{}, errorf(http.StatusBadRequest, "An add/drop must be for a single team")
+		}
+		teamKey = drop.Data.SourceTeamKey
+	}
+	team := l.team(teamKey)
+	if team == nil {
+		return fantasy.Transaction{}, errorf(http.StatusNotFound, "Team %s not found", teamKey)
+	}
+	if !team.managedBy(s.Model.Guid) {
+		return fantasy.Transaction{}, errorf(http.StatusForbidden, "You cannot make moves for team %s", teamKey)
+	}
+
+	t := fantasy.Transaction{Type: typ, Status: "successful"}
+	var added, dropped fantasy.Player
+	if drop != nil {
+		i := team.index(drop.PlayerKey)
+		if i < 0 {
+			return fantasy.Transaction{}, errorf(http.StatusBadRequest, "Player %s is not on team %s", drop.PlayerKey, teamKey)
+		}
+		dropped = team.Players[i].Player
+	}
+	if add != nil {
+		p, ok := l.player(add.PlayerKey)
+		if !ok {
+			return fantasy.Transaction{}, errorf(http.StatusNotFound, "Player %s not found", add.PlayerKey)
+		}
+		if l.owner(p.Key) != nil {
+			return fantasy.Transaction{}, errorf(http.StatusBadRequest, "Player %s is already on a team", p.Key)
+		}
+		added = p
+
+		source := "freeagents"
+		if l.Waivers[p.Key] {
+			source = "waivers"
+			if bid != nil {
+				if l.Settings == nil || !l.Settings.UsesFAAB {
+					return fantasy.Transaction{}, errorf(http.StatusBadRequest, "League %s does not use FAAB", l.Key)
+				}
+				if *bid < 0 || *bid > team.FAABBalance {
+					return fantasy.Transaction{}, errorf(http.StatusBadRequest, "Bid %d exceeds the FAAB balance of %d", *bid, team.FAABBalance)
+				}
+				t.FAABBid = *bid
+			}
+			t.Type, t.Status = "waiver", "pending"
+		}
+		t.Players = append(t.Players, fantasy.TransactionPlayer{Player: p, TransactionData: fantasy.TransactionData{
+			Type: "add", SourceType: source, DestinationType: "team", DestinationTeamKey: teamKey,
+		}})
+	}
+	if drop != nil {
+		t.Players = append(t.Players, fantasy.TransactionPlayer{Player: dropped, TransactionData: fantasy.TransactionData{
+			Type: "drop", SourceType: "team", SourceTeamKey: teamKey, DestinationType: "waivers",
+		}})
+	}
+
+	if t.Status == "successful" {
+		l.move(team, added, dropped)
+	}
+	return t, nil
+}
+
+// move drops and adds players of a team, either may be the zero Player. Dropped players go on waivers.
+func (l *League) move(team *Team, added, dropped fantasy.Player) {
+	if dropped.Key != "" {
+		team.remove(dropped.Key)
+		if l.Waivers == nil {
+			l.Waivers = map[string]bool{}
+		}
+		l.Waivers[dropped.Key] = true
+	}
+	if added.Key != "" {
+		team.add(added)
+		delete(l.Waivers, added.Key)
+	}
+	team.NumberOfMoves++
+}
+
+// proposeTrade checks a trade proposed by a team managed by the logged in user and makes it pending.
+func (s *Server) proposeTrade(l *League, traderKey, tradeeKey string, moves []playerMove) (fantasy.Transaction, error) {
+	trader, tradee := l.team(traderKey), l.team(tradeeKey)
+	if trader == nil || tradee == nil || trader == tradee {
+		return fantasy.Transaction{}, errorf(http.StatusBadRequest, "A trade needs two teams of league %s", l.Key)
+	}
+	if !trader.managedBy(s.Model.Guid) {
+		return fantasy.Transaction{}, errorf(http.StatusForbidden, "You cannot propose trades for team %s", traderKey)
+	}
+	if len(moves) == 0 {
+		return fantasy.Transaction{}, errorf(http.StatusBadRequest, "A trade needs players")
+	}
+
+	t := fantasy.Transaction{Type: "pending_trade", Status: "proposed", TraderTeamKey: traderKey, TradeeTeamKey: tradeeKey}
+	for _, m := range moves {
+		from, to := l.team(m.Data.SourceTeamKey), l.team(m.Data.DestinationTeamKey)
+		if from == nil || to == nil || !(from == trader && to == tradee || from == tradee && to == trader) {
+			return fantasy.Transaction{}, errorf(http.StatusBadRequest, "Player %s must move between the trading teams", m.PlayerKey)
+		}
+		i := from.index(m.PlayerKey)
+		if i < 0 {
+			return fantasy.Transaction{}, errorf(http.StatusBadRequest, "Player %s is not on team %s", m.PlayerKey, from.Key)
+		}
+		t.Players = append(t.Players, fantasy.TransactionPlayer{Player: from.Players[i].Player, TransactionData: fantasy.TransactionData{
+			Type: "pending_trade", SourceType: "team", SourceTeamKey: from.Key, DestinationType: "team", DestinationTeamKey: to.Key,
+		}})
+	}
+	return t, nil
+}
+
+// respondTrade applies a trade response to the pending trade with key.
+// A trade accepted by the tradee is processed at once unless the league ratifies trades, then the commissioner
+// allows or disallows it.
+func (s *Server) respondTrade(key string, r transactionRequest) (*fantasy.Transaction, error) {
+	var l *League
+	var t *fantasy.Transaction
+	for _, league := range s.Model.Leagues {
+		if t = league.transaction(key); t != nil {
+			l = league
+			break
+		}
+	}
+	if t == nil {
+		return nil, errorf(http.StatusNotFound, "Transaction %s not found", key)
+	}
+	if t.Type != "pending_trade" {
+		return nil, errorf(http.StatusBadRequest, "Transaction %s is not a pending trade", key)
+	}
+
+	tradee := l.team(t.TradeeTeamKey)
+	action := r.Transaction.Action
+	switch action {
+	case fantasy.TradeAccept, fantasy.TradeReject:
+		if t.Status != "proposed" {
+			return nil, errorf(http.StatusBadRequest, "Trade %s is %s", key, t.Status)
+		}
+		if tradee == nil || !tradee.managedBy(s.Model.Guid) {
+			return nil, errorf(http.StatusForbidden, "Only the manager of team %s may %s trade %s", t.TradeeTeamKey, action, key)
+		}
+	case fantasy.TradeAllow, fantasy.TradeDisallow:
+		if t.Status != "accepted" {
+			return nil, errorf(http.StatusBadRequest, "Trade %s is %s", key, t.Status)
+		}
+		if l.Commissioner != s.Model.Guid {
+			return nil, errorf(http.StatusForbidden, "Only the commissioner may %s trade %s", action, key)
+		}
+	case fantasy.TradeVoteAgainst:
+		if t.Status != "accepted" {
+			return nil, errorf(http.StatusBadRequest, "Trade %s is %s", key, t.Status)
+		}
+	default:
+		return nil, errorf(http.StatusBadRequest, "Unknown trade action %q", action)
+	}
+
+	switch action {
+	case fantasy.TradeAccept:
+		if l.Settings != nil && (l.Settings.TradeRatifyType == fantasy.TradeRatifyCommish || l.Settings.TradeRatifyType == fantasy.TradeRatifyVote) {
+			t.Status = "accepted"
+			break
+		}
+		if err := l.trade(t); err != nil {
+			return nil, err
+		}
+	case fantasy.TradeAllow:
+		if err := l.trade(t); err != nil {
+			return nil, err
+		}
+	case fantasy.TradeReject:
+		t.Status = "rejected"
+	case fantasy.TradeDisallow:
+		t.Status = "vetoed"
+	}
+
+	result := *t
+	return &result, nil
+}
+
+// trade moves the players of a pending trade and marks it a successful trade.
+func (l *League) trade(t *fantasy.Transaction) error {
+	for _, p := range t.Players {
+		if from := l.team(p.TransactionData.SourceTeamKey); from == nil || !from.has(p.Key) {
+			return errorf(http.StatusConflict, "Player %s is no longer on team %s", p.Key, p.TransactionData.SourceTeamKey)
+		}
+	}
+	for _, p := range t.Players {
+		l.team(p.TransactionData.SourceTeamKey).remove(p.Key)
+		l.team(p.TransactionData.DestinationTeamKey).add(p.Player)
+	}
+	l.team(t.TraderTeamKey).NumberOfTrades++
+	l.team(t.TradeeTeamKey).NumberOfTrades++
+	t.Type, t.Status = "trade", "successful"
+	return nil
+}
+
+// ProcessWaivers settles the pending waiver claims of every league as Yahoo does once waivers clear.
+// Claims are awarded to the highest FAAB bid, ties and leagues without FAAB go by waiver priority,
+// and a successful claim moves the team to the end of the waiver order. Claims for players already awarded fail.
+func (s *Server) ProcessWaivers() {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, l := range s.Model.Leagues {
+		var claims []*fantasy.Transaction
+		for i := range l.Transactions {
+			t := &l.Transactions[i]
+			if t.Type != "waiver" || t.Status != "pending" {
+				continue
+			}
+			// a claim without an added player on a team of the league can never be awarded.
+			if claimTeam(l, t) == nil {
+				t.Status = "failed"
+				continue
+			}
+			claims = append(claims, t)
+		}
+
+		for len(claims) > 0 {
+			sort.SliceStable(claims, func(i, j int) bool {
+				if claims[i].FAABBid != claims[j].FAABBid {
+					return claims[i].FAABBid > claims[j].FAABBid
+				}
+				return claimTeam(l, claims[i]).WaiverPriority < claimTeam(l, claims[j]).WaiverPriority
+			})
+
+			t := claims[0]
+			claims = claims[1:]
+			if !l.claim(t) {
+				t.Status = "failed"
+			}
+		}
+	}
+}
+
+// claimTeam returns the team making a waiver claim.
+func claimTeam(l *League, t *fantasy.Transaction) *Team {
+	for _, p := range t.Players {
+		if p.TransactionData.Type == "add" {
+			return l.team(p.TransactionData.DestinationTeamKey)
+		}
+	}
+	return nil
+}
+
+// claim applies a waiver claim, it reports false when the player was taken or the dropped player is gone.
+func (l *League) claim(t *fantasy.Transaction) bool {
+	team := claimTeam(l, t)
+	if team == nil || (l.Settings != nil && l.Settings.UsesFAAB && t.FAABBid > team.FAABBalance) {
+		return false
+	}
+
+	var added, dropped fantasy.Player
+	for _, p := range t.Players {
+		switch p.TransactionData.Type {
+		case "add":
+			if l.owner(p.Key) != nil {
+				return false
+			}
+			added = p.Player
+		case "drop":
+			if !team.has(p.Key) {
+				return false
+			}
+			dropped = p.Player
+		}
+	}
+
+	l.move(team, added, dropped)
+	team.FAABBalance -= t.FAABBid
+	t.Status = "successful"
+
+	if l.Settings == nil || l.Settings.WaiverType == fantasy.WaiverRolling {
+		for _, other := range l.Teams {
+			if other.WaiverPriority > team.WaiverPriority {
+				other.WaiverPriority--
+			}
+		}
+		team.WaiverPriority = int64(len(l.Teams))
+	}
+	return true
+}
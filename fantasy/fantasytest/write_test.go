@@ -0,0 +1,260 @@
+package fantasytest_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/muswell/yahoo/fantasy"
+	"github.com/muswell/yahoo/fantasy/fantasytest"
+)
+
+const (
+	league = "357.l.86753"
+	team1  = "357.l.86753.t.1"
+	team2  = "357.l.86753.t.2"
+)
+
+// apiStatus returns the status code of an *fantasy.APIError, zero for other errors.
+func apiStatus(err error) int {
+	if e, ok := err.(*fantasy.APIError); ok {
+		return e.StatusCode
+	}
+	return 0
+}
+
+func TestRosterEdit(t *testing.T) {
+	s := fantasytest.NewServer(nil)
+	defer s.Close()
+
+	edit := &fantasy.RosterEdit{TeamKey: team1, Date: "2016-04-10", Players: []fantasy.RosterPosition{{PlayerKey: "357.p.8967", Position: "1B"}}}
+	result, err := fantasy.Send(s.Client(), edit)
+	if err != nil {
+		t.Fatalf("Unexpected roster edit error: %v", err)
+	}
+	if result.Transaction != nil {
+		t.Errorf("A roster edit returned transaction %+v", result.Transaction)
+	}
+
+	teams, err := (&fantasy.TeamQueryBuilder{Keys: []string{team1}, Roster: true}).Get(s.Client())
+	if err != nil {
+		t.Fatalf("Unexpected roster error: %v", err)
+	}
+	if p := teams[0].Roster.Players[0]; p.Key != "357.p.8967" || p.SelectedPosition.Position != "1B" {
+		t.Errorf("Roster edit was not applied, got %+v", p)
+	}
+
+	rejected := []struct {
+		edit   *fantasy.RosterEdit
+		status int
+	}{
+		{&fantasy.RosterEdit{TeamKey: team2, Week: 1, Players: []fantasy.RosterPosition{{PlayerKey: "357.p.8658", Position: "BN"}}}, http.StatusForbidden},
+		{&fantasy.RosterEdit{TeamKey: team1, Week: 1, Players: []fantasy.RosterPosition{{PlayerKey: "357.p.8658", Position: "BN"}}}, http.StatusBadRequest},
+		{&fantasy.RosterEdit{TeamKey: team1, Week: 1, Players: []fantasy.RosterPosition{{PlayerKey: "357.p.9105", Position: "C"}}}, http.StatusBadRequest},
+		{&fantasy.RosterEdit{TeamKey: team1, Week: 1, Players: []fantasy.RosterPosition{{PlayerKey: "357.p.8967", Position: "Util"}}}, http.StatusBadRequest},
+		{&fantasy.RosterEdit{TeamKey: "357.l.86753.t.9", Week: 1, Players: []fantasy.RosterPosition{{PlayerKey: "357.p.8967", Position: "C"}}}, http.StatusNotFound},
+	}
+	for _, r := range rejected {
+		if _, err := fantasy.Send(s.Client(), r.edit); apiStatus(err) != r.status {
+			t.Errorf("Roster edit %+v returned %v, expected status %d", r.edit, err, r.status)
+		}
+	}
+}
+
+func TestAddDrop(t *testing.T) {
+	s := fantasytest.NewServer(nil)
+	defer s.Close()
+	now := time.Date(2016, 4, 10, 12, 0, 0, 0, time.UTC)
+	s.Now = func() time.Time { return now }
+
+	result, err := fantasy.Send(s.Client(), &fantasy.AddDrop{LeagueKey: league, TeamKey: team1, AddPlayerKey: "357.p.9573", DropPlayerKey: "357.p.9105"})
+	if err != nil {
+		t.Fatalf("Unexpected add/drop error: %v", err)
+	}
+	tr := result.Transaction
+	if tr == nil || tr.Key != league+".tr.1" || tr.Type != "add/drop" || tr.Status != "successful" || !tr.Timestamp.Time().Equal(now) || len(tr.Players) != 2 {
+		t.Fatalf("Add/drop returned incorrect transaction %+v", tr)
+	}
+	if d := tr.Players[0].TransactionData; d.SourceType != "freeagents" || d.DestinationTeamKey != team1 {
+		t.Errorf("Add/drop returned incorrect added player data %+v", d)
+	}
+
+	waivers, err := (&fantasy.PlayerQueryBuilder{LeagueQB: &fantasy.LeagueQueryBuilder{Keys: []string{league}}, Status: "W"}).Get(s.Client())
+	if err != nil {
+		t.Fatalf("Unexpected players error: %v", err)
+	}
+	if len(waivers) != 2 || waivers[0].Key != "357.p.9105" {
+		t.Errorf("The dropped player is not on waivers, got %+v", waivers)
+	}
+
+	s.Lock()
+	if team := s.Model.Leagues[0].Teams[0]; len(team.Players) != 2 || team.Players[1].Key != "357.p.9573" || team.Players[1].SelectedPosition.Position != "BN" || team.NumberOfMoves != 1 {
+		t.Errorf("Add/drop was not applied to the model, got %+v", team)
+	}
+	s.Unlock()
+
+	transactions, err := (&fantasy.TransactionQueryBuilder{LeagueQB: &fantasy.LeagueQueryBuilder{Keys: []string{league}}}).Get(s.Client())
+	if err != nil {
+		t.Fatalf("Unexpected transactions error: %v", err)
+	}
+	if len(transactions) != 1 || transactions[0].Key != tr.Key {
+		t.Errorf("Served incorrect transactions %+v", transactions)
+	}
+
+	rejected := []struct {
+		add    *fantasy.AddDrop
+		status int
+	}{
+		{&fantasy.AddDrop{LeagueKey: league, TeamKey: team2, DropPlayerKey: "357.p.8658"}, http.StatusForbidden},
+		{&fantasy.AddDrop{LeagueKey: league, TeamKey: team1, AddPlayerKey: "357.p.8658"}, http.StatusBadRequest},
+		{&fantasy.AddDrop{LeagueKey: league, TeamKey: team1, DropPlayerKey: "357.p.8658"}, http.StatusBadRequest},
+		{&fantasy.AddDrop{LeagueKey: league, TeamKey: team1, AddPlayerKey: "357.p.1"}, http.StatusNotFound},
+	}
+	for _, r := range rejected {
+		if _, err := fantasy.Send(s.Client(), r.add); apiStatus(err) != r.status {
+			t.Errorf("Add/drop %+v returned %v, expected status %d", r.add, err, r.status)
+		}
+	}
+}
+
+func TestWaiverClaims(t *testing.T) {
+	model := fantasytest.NewModel()
+	model.Leagues[0].Teams[1].Managers = append(model.Leagues[0].Teams[1].Managers, fantasy.Manager{Guid: model.Guid})
+	s := fantasytest.NewServer(model)
+	defer s.Close()
+
+	low, high, tooHigh := 5, 20, 101
+	if _, err := fantasy.Send(s.Client(), &fantasy.AddDrop{LeagueKey: league, TeamKey: team1, AddPlayerKey: "357.p.8875", FAABBid: &tooHigh}); apiStatus(err) != http.StatusBadRequest {
+		t.Errorf("A bid over the FAAB balance returned %v", err)
+	}
+
+	claim, err := fantasy.Send(s.Client(), &fantasy.AddDrop{LeagueKey: league, TeamKey: team1, AddPlayerKey: "357.p.8875", FAABBid: &low})
+	if err != nil {
+		t.Fatalf("Unexpected claim error: %v", err)
+	}
+	if tr := claim.Transaction; tr.Type != "waiver" || tr.Status != "pending" || tr.FAABBid != 5 || tr.Players[0].TransactionData.SourceType != "waivers" {
+		t.Errorf("Claim returned incorrect transaction %+v", tr)
+	}
+	if _, err := fantasy.Send(s.Client(), &fantasy.AddDrop{LeagueKey: league, TeamKey: team2, AddPlayerKey: "357.p.8875", FAABBid: &high}); err != nil {
+		t.Fatalf("Unexpected claim error: %v", err)
+	}
+
+	s.ProcessWaivers()
+
+	s.Lock()
+	defer s.Unlock()
+	l := s.Model.Leagues[0]
+	if l.Transactions[0].Status != "successful" || l.Transactions[1].Status != "failed" {
+		t.Errorf("The highest bid did not win, got %s and %s", l.Transactions[0].Status, l.Transactions[1].Status)
+	}
+	if winner := l.Teams[1]; len(winner.Players) != 3 || winner.FAABBalance != 80 || winner.WaiverPriority != 2 {
+		t.Errorf("The claim was not applied to the winner %+v", winner)
+	}
+	if l.Teams[0].WaiverPriority != 1 || len(l.Teams[0].Players) != 2 {
+		t.Errorf("The losing claim changed team %+v", l.Teams[0])
+	}
+}
+
+func TestWaiverClaimWithoutAdd(t *testing.T) {
+	model := fantasytest.NewModel()
+	l := model.Leagues[0]
+	bad := fantasy.Transaction{Key: "357.l.86753.w.c.9", Type: "waiver", Status: "pending"}
+	bad.Players = append(bad.Players, fantasy.TransactionPlayer{})
+	bad.Players[0].TransactionData.Type = "drop"
+	l.Transactions = append(l.Transactions, bad)
+	s := fantasytest.NewServer(model)
+	defer s.Close()
+
+	if _, err := fantasy.Send(s.Client(), &fantasy.AddDrop{LeagueKey: league, TeamKey: team1, AddPlayerKey: "357.p.8875"}); err != nil {
+		t.Fatalf("Unexpected claim error: %v", err)
+	}
+	s.ProcessWaivers()
+
+	s.Lock()
+	defer s.Unlock()
+	for _, tr := range l.Transactions {
+		if tr.Key == bad.Key && tr.Status != "failed" {
+			t.Errorf("A claim without an added player was %s, expected failed", tr.Status)
+		}
+		if tr.Key != bad.Key && tr.Status != "successful" {
+			t.Errorf("Claim %s was %s, expected successful", tr.Key, tr.Status)
+		}
+	}
+}
+
+func TestTrade(t *testing.T) {
+	model := fantasytest.NewModel()
+	s := fantasytest.NewServer(model)
+	defer s.Close()
+
+	proposal := &fantasy.TradeProposal{LeagueKey: league, TraderTeamKey: team1, TradeeTeamKey: team2, TraderPlayerKeys: []string{"357.p.9105"}, TradeePlayerKeys: []string{"357.p.9116"}}
+	result, err := fantasy.Send(s.Client(), proposal)
+	if err != nil {
+		t.Fatalf("Unexpected proposal error: %v", err)
+	}
+	pending := result.Transaction
+	if pending.Key != league+".pt.1" || pending.Type != "pending_trade" || pending.Status != "proposed" || len(pending.Players) != 2 {
+		t.Fatalf("Proposal returned incorrect transaction %+v", pending)
+	}
+
+	accept := &fantasy.TradeResponse{TransactionKey: pending.Key, Action: fantasy.TradeAccept}
+	if _, err := fantasy.Send(s.Client(), accept); apiStatus(err) != http.StatusForbidden {
+		t.Errorf("The trader accepted their own trade: %v", err)
+	}
+
+	// log in as the tradee.
+	s.Lock()
+	model.Guid = "GUID2"
+	s.Unlock()
+
+	result, err = fantasy.Send(s.Client(), accept)
+	if err != nil {
+		t.Fatalf("Unexpected accept error: %v", err)
+	}
+	if tr := result.Transaction; tr.Type != "trade" || tr.Status != "successful" {
+		t.Errorf("Accept returned incorrect transaction %+v", tr)
+	}
+	if _, err := fantasy.Send(s.Client(), &fantasy.TradeResponse{TransactionKey: pending.Key, Action: fantasy.TradeReject}); apiStatus(err) != http.StatusBadRequest {
+		t.Errorf("A completed trade was rejected: %v", err)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	l := model.Leagues[0]
+	if l.Teams[0].Players[1].Key != "357.p.9116" || l.Teams[1].Players[1].Key != "357.p.9105" || l.Teams[0].NumberOfTrades != 1 {
+		t.Errorf("The trade was not applied, got %+v and %+v", l.Teams[0].Players, l.Teams[1].Players)
+	}
+}
+
+func TestRatifiedTrade(t *testing.T) {
+	model := fantasytest.NewModel()
+	model.Leagues[0].Settings.TradeRatifyType = fantasy.TradeRatifyCommish
+	model.Leagues[0].Teams[1].Managers = append(model.Leagues[0].Teams[1].Managers, fantasy.Manager{Guid: model.Guid})
+	s := fantasytest.NewServer(model)
+	defer s.Close()
+
+	proposal := &fantasy.TradeProposal{LeagueKey: league, TraderTeamKey: team1, TradeeTeamKey: team2, TraderPlayerKeys: []string{"357.p.9105"}, TradeePlayerKeys: []string{"357.p.9116"}}
+	result, err := fantasy.Send(s.Client(), proposal)
+	if err != nil {
+		t.Fatalf("Unexpected proposal error: %v", err)
+	}
+	key := result.Transaction.Key
+
+	for _, r := range []struct {
+		action, status string
+	}{{fantasy.TradeAccept, "accepted"}, {fantasy.TradeDisallow, "vetoed"}} {
+		result, err := fantasy.Send(s.Client(), &fantasy.TradeResponse{TransactionKey: key, Action: r.action})
+		if err != nil {
+			t.Fatalf("Unexpected %s error: %v", r.action, err)
+		}
+		if result.Transaction.Status != r.status {
+			t.Errorf("%s left the trade %s, expected %s", r.action, result.Transaction.Status, r.status)
+		}
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	if model.Leagues[0].Teams[0].Players[1].Key != "357.p.9105" {
+		t.Errorf("A vetoed trade moved players")
+	}
+}
@@ -2,8 +2,8 @@ package fantasy
 
 import (
 	"encoding/xml"
-	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
@@ -24,7 +24,7 @@ type Game struct {
 	// Season is a 4 digit year in which the season is played.
 	Season int64 `xml:"season"`
 	//IsRegistrationOver determines if the game is still accepting new signups.
-	IsRegistrationOver intAsBool `xml:"is_registration_over"`
+	IsRegistrationOver Bool `xml:"is_registration_over"`
 }
 
 //GameQueryBuilder contains properties which are used to generate yahoo api game requests.
@@ -34,6 +34,16 @@ type GameQueryBuilder struct {
 	UserQB *UserQueryBuilder
 	// Available sets the query builder to only return available games.
 	Available bool
+	// Add Game Keys to return specific games, a game key is either a game id or a game code e.g. mlb.
+	Keys []string
+	// Codes filters games by game code e.g. mlb, nfl.
+	Codes []string
+	// Seasons filters games by season, use it with Codes to find the game of a past season.
+	Seasons []int64
+	// Format is the response format to request, xml by default.
+	Format Format
+	// Batching controls how a query for more keys than Yahoo accepts is split into requests.
+	Batching Batching
 }
 
 //Path returns the yahoo api path for the query excluding the host and query string.
@@ -47,17 +57,33 @@ func (q *GameQueryBuilder) Path() string {
 	if q.Available {
 		path += ";is_available=1"
 	}
+
+	if q.Keys != nil {
+		path += ";game_keys=" + strings.Join(q.Keys, ",")
+	}
+
+	if q.Codes != nil {
+		path += ";game_codes=" + strings.Join(q.Codes, ",")
+	}
+
+	if q.Seasons != nil {
+		seasons := make([]string, len(q.Seasons))
+		for i, s := range q.Seasons {
+			seasons[i] = strconv.FormatInt(s, 10)
+		}
+		path += ";seasons=" + strings.Join(seasons, ",")
+	}
 	return strings.TrimLeft(path, "/")
 }
 
 // Url generates the url needed for a request of the query builder's settings.
 func (q *GameQueryBuilder) Url() string {
-	return baseUrl + q.Path() + "?format=xml"
+	return baseUrl + q.Path() + "?format=" + q.Format.String()
 }
 
-// XmlGameParser must be able to parse a byte slice of xml data and return a slice of Games.
+// XmlGameParser must be able to decode an xml token stream and return a slice of Games.
 type xmlGameParser interface {
-	parseXML([]byte) ([]Game, error)
+	parseXML(*xml.Decoder) ([]Game, ResponseMeta, error)
 }
 
 // DefaultXMLGameParser parses xml with a games node as a direct child of the fantasy_content node.
@@ -65,18 +91,19 @@ type defaultXMLGameParser struct {
 	result struct {
 		// XMLName fantasy_content is the main wrapper tag in a Yahoo api response.
 		XMLName xml.Name `xml:"fantasy_content"`
+		responseAttrs
 		//Games is where a GameBuilderQuery stores its results.
 		Games []Game `xml:"games>game"`
 	}
 }
 
 // ParseXML actually does the transformation from xml to Game slice.
-func (p defaultXMLGameParser) parseXML(data []byte) ([]Game, error) {
-	err := xml.Unmarshal(data, &p.result)
+func (p defaultXMLGameParser) parseXML(d *xml.Decoder) ([]Game, ResponseMeta, error) {
+	err := d.Decode(&p.result)
 	if err != nil {
-		return []Game{}, err
+		return []Game{}, ResponseMeta{}, err
 	}
-	return p.result.Games, nil
+	return p.result.Games, p.result.meta(), nil
 }
 
 //UserXMLGameParser parses xml with a structure fantasy_conent>users>user>games>game.
@@ -84,6 +111,7 @@ type userXMLGameParser struct {
 	result struct {
 		// XMLName fantasy_content is the main wrapper tag in a Yahoo api response.
 		XMLName xml.Name `xml:"fantasy_content"`
+		responseAttrs
 		//Games is where a GameBuilderQuery stores its results.
 		Games []Game `xml:"users>user>games>game"`
 	}
@@ -92,14 +120,14 @@ type userXMLGameParser struct {
 // todo create generic function for parseXML.
 
 // ParseXML actually does the transformation from xml to Game slice.
-func (p userXMLGameParser) parseXML(data []byte) ([]Game, error) {
-	err := xml.Unmarshal(data, &p.result)
+func (p userXMLGameParser) parseXML(d *xml.Decoder) ([]Game, ResponseMeta, error) {
+	err := d.Decode(&p.result)
 
 	if err != nil {
-		return []Game{}, err
+		return []Game{}, ResponseMeta{}, err
 	}
 
-	return p.result.Games, nil
+	return p.result.Games, p.result.meta(), nil
 }
 
 // XmlParser returns the appropriate xml parser based on the query builder settings.
@@ -113,18 +141,62 @@ func (q *GameQueryBuilder) xmlParser() xmlGameParser {
 // Get sends a request to the appropriate url based on the query builder settings.
 // It then sends that response to a parser and returns the resulting Game slice.
 func (q *GameQueryBuilder) Get(client *http.Client) ([]Game, error) {
-	url := q.Url()
+	games, _, err := q.GetWithMeta(client)
+	return games, err
+}
 
-	resp, err := client.Get(url)
-	if err != nil {
-		return []Game{}, err
+// Validate checks Keys are well formed game keys, along with the keys of nested query builders.
+func (q *GameQueryBuilder) Validate() error {
+	if q.UserQB != nil {
+		if err := q.UserQB.Validate(); err != nil {
+			return err
+		}
+	}
+	return validateKeys(q.Keys, validGameKey)
+}
+
+// GetWithMeta works like Get but also returns the metadata of the response.
+// When Keys holds more keys than Batching allows the games are requested in batches,
+// the metadata is then that of the first successful batch.
+func (q *GameQueryBuilder) GetWithMeta(client *http.Client) ([]Game, ResponseMeta, error) {
+	if err := q.Validate(); err != nil {
+		return []Game{}, ResponseMeta{}, err
+	}
+
+	if len(q.Keys) > q.Batching.maxKeys() {
+		return q.getBatched(client)
 	}
-	defer resp.Body.Close()
 
-	data, err := ioutil.ReadAll(resp.Body)
+	d, err := fetch(client, q.Url(), q.Format)
 	if err != nil {
-		return []Game{}, err
+		return []Game{}, ResponseMeta{}, err
 	}
 
-	return q.xmlParser().parseXML(data)
+	return q.xmlParser().parseXML(d)
+}
+
+// GetBatched requests the games in chunks of keys and merges the results in key order.
+func (q *GameQueryBuilder) getBatched(client *http.Client) ([]Game, ResponseMeta, error) {
+	chunks := chunkKeys(q.Keys, q.Batching.maxKeys())
+	results := make([][]Game, len(chunks))
+	metas := make([]ResponseMeta, len(chunks))
+
+	err := batch(chunks, q.Batching.workers(), func(i int, keys []string) error {
+		chunk := *q
+		chunk.Keys = keys
+
+		var err error
+		results[i], metas[i], err = chunk.GetWithMeta(client)
+		return err
+	})
+
+	games := []Game{}
+	for _, r := range results {
+		games = append(games, r...)
+	}
+
+	// yahoo does not return a chunk in the order of its keys.
+	newKeyOrder(q.Keys).sort(games, func(i int) []string { return []string{strconv.FormatInt(games[i].Key, 10), games[i].Code} })
+
+	return games, firstMeta(metas), err
 }
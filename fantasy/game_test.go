@@ -24,6 +24,10 @@ func TestGameQueryBuilderURL(t *testing.T) {
 			GameQueryBuilder{Available: true, UserQB: &UserQueryBuilder{ActiveUser: true}},
 			baseUrl + "users;use_login=1/games;is_available=1?format=xml",
 		},
+		{
+			GameQueryBuilder{Codes: []string{"mlb"}, Seasons: []int64{2015, 2016}},
+			baseUrl + "games;game_codes=mlb;seasons=2015,2016?format=xml",
+		},
 	}
 
 	for _, test := range tests {
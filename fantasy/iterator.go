@@ -0,0 +1,90 @@
+package fantasy
+
+// PageSize is the number of items an iterator requests per page when its query sets no Count,
+// and the most it requests when Count is larger. Yahoo returns at most 25 players per request.
+const PageSize = 25
+
+// Page is a single page of results, items holds a typed slice.
+type page struct {
+	items interface{}
+	err   error
+}
+
+// Pager requests consecutive pages of a collection until a page comes back short.
+// With prefetching enabled pages are requested ahead of the consumer in a separate goroutine.
+type pager struct {
+	// Fetch requests count items starting at offset start and returns them along with how many there were.
+	fetch func(start, count int) (interface{}, int, error)
+	start int
+	count int
+	done  bool
+	// Pages is only used when prefetching.
+	pages chan page
+	stop  chan struct{}
+}
+
+// NewPager creates a pager beginning at start which fetches up to prefetch pages in advance.
+func newPager(start, count, prefetch int, fetch func(start, count int) (interface{}, int, error)) *pager {
+	// a larger page would come back short of count and end the iteration early.
+	if count < 1 || count > PageSize {
+		count = PageSize
+	}
+
+	p := &pager{fetch: fetch, start: start, count: count}
+	if prefetch > 0 {
+		p.pages = make(chan page, prefetch)
+		p.stop = make(chan struct{})
+		go p.prefetch()
+	}
+	return p
+}
+
+// Next returns the next page, ok is false once the collection is exhausted.
+func (p *pager) next() (pg page, ok bool) {
+	if p.pages != nil {
+		pg, ok = <-p.pages
+		return pg, ok
+	}
+
+	if p.done {
+		return page{}, false
+	}
+	return p.fetchPage(), true
+}
+
+// FetchPage requests the page at the current offset and advances it.
+func (p *pager) fetchPage() page {
+	items, n, err := p.fetch(p.start, p.count)
+	if err != nil || n < p.count {
+		p.done = true
+	}
+	p.start += n
+	return page{items: items, err: err}
+}
+
+// Prefetch sends pages to the pages channel until the collection is exhausted or the pager is closed.
+func (p *pager) prefetch() {
+	defer close(p.pages)
+
+	for !p.done {
+		pg := p.fetchPage()
+		select {
+		case p.pages <- pg:
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// Close stops a prefetching pager, it is safe to call more than once.
+func (p *pager) close() {
+	if p.stop == nil {
+		return
+	}
+
+	select {
+	case <-p.stop:
+	default:
+		close(p.stop)
+	}
+}
@@ -0,0 +1,134 @@
+package fantasy
+
+import (
+	"errors"
+	"fmt"
+	"github.com/muswell/gotest"
+	"strings"
+	"testing"
+)
+
+// playerPageXML builds a league player collection response holding the players numbered from start to end.
+func playerPageXML(start, end int) []byte {
+	xml := `<?xml version="1.0" encoding="UTF-8"?>
+		<fantasy_content><leagues><league><players>`
+	for i := start; i < end; i++ {
+		xml += fmt.Sprintf("<player><player_key>357.p.%d</player_key></player>", i)
+	}
+	return []byte(xml + "</players></league></leagues></fantasy_content>")
+}
+
+// pagedPlayerClient registers pages of two players for a collection of five players.
+func pagedPlayerClient(q PlayerQueryBuilder) *gotest.RegisteredClient {
+	client := gotest.NewRegisteredClient()
+	for start := 0; start <= 4; start += 2 {
+		end := start + 2
+		if end > 5 {
+			end = 5
+		}
+
+		pq := q
+		pq.Start, pq.Count = start, 2
+		client.Register(pq.Url(), "get", gotest.NewSimpleRoundTrip(playerPageXML(start, end), nil))
+	}
+	return client
+}
+
+func TestPlayerIterator(t *testing.T) {
+	q := PlayerQueryBuilder{LeagueQB: &LeagueQueryBuilder{Keys: []string{"357.l.86753"}}, Status: "FA", Count: 2}
+	client := pagedPlayerClient(q)
+
+	for _, prefetch := range []int{0, 1, 3} {
+		it := q.Iter(client.Client, prefetch)
+
+		var keys []string
+		for it.Next() {
+			keys = append(keys, it.Player().Key)
+		}
+		it.Close()
+
+		if it.Err() != nil {
+			t.Errorf("Unexpected PlayerIterator error with prefetch %d: %s", prefetch, it.Err())
+		}
+
+		want := "357.p.0,357.p.1,357.p.2,357.p.3,357.p.4"
+		if got := strings.Join(keys, ","); got != want {
+			t.Errorf("PlayerIterator with prefetch %d returned %s, expected %s", prefetch, got, want)
+		}
+	}
+}
+
+func TestPlayerIteratorError(t *testing.T) {
+	q := PlayerQueryBuilder{LeagueQB: &LeagueQueryBuilder{Keys: []string{"357.l.86753"}}, Count: 2}
+
+	// only the first page is registered so the second request fails.
+	client := gotest.NewRegisteredClient()
+	client.Register(q.Url(), "get", gotest.NewSimpleRoundTrip(playerPageXML(0, 2), nil))
+
+	it := q.Iter(client.Client, 0)
+	n := 0
+	for it.Next() {
+		n++
+	}
+
+	if n != 2 {
+		t.Errorf("PlayerIterator returned %d players before failing, expected %d", n, 2)
+	}
+	if it.Err() == nil {
+		t.Error("Expected PlayerIterator to return an error")
+	}
+	if it.Next() {
+		t.Error("Expected PlayerIterator to stop after an error")
+	}
+}
+
+func TestPagerClose(t *testing.T) {
+	fetch := func(start, count int) (interface{}, int, error) {
+		if start > 1000 {
+			return nil, 0, errors.New("pager was not stopped")
+		}
+		return []Player{{}}, 1, nil
+	}
+
+	p := newPager(0, 1, 2, fetch)
+	if _, ok := p.next(); !ok {
+		t.Fatal("Expected a page from the pager")
+	}
+	p.close()
+	p.close()
+
+	// the prefetching goroutine exits and closes the channel once stopped.
+	for range p.pages {
+	}
+}
+
+func TestPlayerIteratorCountAbovePageSize(t *testing.T) {
+	q := PlayerQueryBuilder{LeagueQB: &LeagueQueryBuilder{Keys: []string{"357.l.86753"}}, Status: "FA", Count: 50}
+
+	// yahoo caps pages at PageSize players so only pages of 25 are registered, 60 players in all.
+	client := gotest.NewRegisteredClient()
+	for start := 0; start < 60; start += PageSize {
+		end := start + PageSize
+		if end > 60 {
+			end = 60
+		}
+
+		pq := q
+		pq.Start, pq.Count = start, PageSize
+		client.Register(pq.Url(), "get", gotest.NewSimpleRoundTrip(playerPageXML(start, end), nil))
+	}
+
+	it := q.Iter(client.Client, 0)
+	defer it.Close()
+	n := 0
+	for it.Next() {
+		n++
+	}
+
+	if it.Err() != nil {
+		t.Errorf("Unexpected PlayerIterator error: %s", it.Err())
+	}
+	if n != 60 {
+		t.Errorf("PlayerIterator with Count 50 returned %d players, expected %d", n, 60)
+	}
+}
@@ -0,0 +1,218 @@
+package fantasy
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Yahoo's JSON responses are a mechanical translation of its XML responses.
+// Rather than maintaining a second set of parsers, the JSON is translated back into
+// the xml token stream it came from so every xml parser and type works with both formats.
+//
+// The translation rules are:
+//   - collections are objects with numeric keys ("0", "1", ...) and a count member,
+//     the numeric members are the collection items and count becomes an attribute.
+//   - resources are arrays whose items are objects or further arrays of single key objects,
+//     the members of every item are merged into the resource's element.
+//   - scalar members of fantasy_content become attributes of the root element.
+
+// YahooNS is the namespace of the yahoo:uri attribute.
+const yahooNS = "http://www.yahooapis.com/v1/base.rng"
+
+// JsonMember is a single key value pair of a json object.
+type jsonMember struct {
+	key   string
+	value interface{}
+}
+
+// JsonObject is a json object which preserves the order of its members.
+type jsonObject []jsonMember
+
+// JsonArray is a json array.
+type jsonArray []interface{}
+
+// JsonTokenReader implements xml.TokenReader over a Yahoo json document.
+type jsonTokenReader struct {
+	tokens []xml.Token
+}
+
+// NewJSONTokenReader decodes a Yahoo json document and translates it into xml tokens.
+func newJSONTokenReader(data []byte) (*jsonTokenReader, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	v, err := readJSONValue(d)
+	if err != nil {
+		return nil, err
+	}
+
+	root, ok := v.(jsonObject)
+	if !ok {
+		return nil, fmt.Errorf("Bad json document, expected an object got %T", v)
+	}
+
+	r := &jsonTokenReader{}
+	for _, m := range root {
+		r.element(m.key, m.value)
+	}
+	return r, nil
+}
+
+// Token returns the next xml token of the document.
+func (r *jsonTokenReader) Token() (xml.Token, error) {
+	if len(r.tokens) == 0 {
+		return nil, io.EOF
+	}
+	t := r.tokens[0]
+	r.tokens = r.tokens[1:]
+	return t, nil
+}
+
+// Element adds the tokens for an element named name holding v.
+func (r *jsonTokenReader) element(name string, v interface{}) {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	switch v := v.(type) {
+	case jsonObject:
+		var content jsonObject
+		for _, m := range v {
+			switch {
+			case name == "fantasy_content" && isJSONScalar(m.value):
+				start.Attr = append(start.Attr, xml.Attr{Name: jsonAttrName(m.key), Value: jsonScalar(m.value)})
+			case m.key == "count" && isJSONCollection(v):
+				start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: m.key}, Value: jsonScalar(m.value)})
+			default:
+				content = append(content, m)
+			}
+		}
+		r.tokens = append(r.tokens, start)
+		r.content(content)
+	case jsonArray:
+		r.tokens = append(r.tokens, start)
+		r.content(v)
+	default:
+		r.tokens = append(r.tokens, start)
+		if s := jsonScalar(v); s != "" {
+			r.tokens = append(r.tokens, xml.CharData(s))
+		}
+	}
+
+	r.tokens = append(r.tokens, start.End())
+}
+
+// Content adds the tokens for the children of an element.
+func (r *jsonTokenReader) content(v interface{}) {
+	switch v := v.(type) {
+	case jsonObject:
+		for _, m := range v {
+			if isJSONIndex(m.key) {
+				r.content(m.value)
+				continue
+			}
+			r.element(m.key, m.value)
+		}
+	case jsonArray:
+		for _, item := range v {
+			r.content(item)
+		}
+	default:
+		if s := jsonScalar(v); s != "" {
+			r.tokens = append(r.tokens, xml.CharData(s))
+		}
+	}
+}
+
+// ReadJSONValue reads the next value from d keeping the member order of objects.
+func readJSONValue(d *json.Decoder) (interface{}, error) {
+	t, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t {
+	case json.Delim('{'):
+		obj := jsonObject{}
+		for d.More() {
+			k, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := readJSONValue(d)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, jsonMember{key: k.(string), value: v})
+		}
+		_, err = d.Token()
+		return obj, err
+	case json.Delim('['):
+		arr := jsonArray{}
+		for d.More() {
+			v, err := readJSONValue(d)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		}
+		_, err = d.Token()
+		return arr, err
+	}
+
+	return t, nil
+}
+
+// IsJSONIndex determines if an object key is a collection index.
+func isJSONIndex(key string) bool {
+	_, err := strconv.Atoi(key)
+	return err == nil
+}
+
+// IsJSONCollection determines if an object is a Yahoo collection, meaning it has indexed members.
+func isJSONCollection(obj jsonObject) bool {
+	for _, m := range obj {
+		if isJSONIndex(m.key) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsJSONScalar determines if v is a string, number, bool or null.
+func isJSONScalar(v interface{}) bool {
+	switch v.(type) {
+	case jsonObject, jsonArray:
+		return false
+	}
+	return true
+}
+
+// JsonScalar formats a scalar the way it would appear in xml, booleans become 0 or 1.
+func jsonScalar(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	}
+	return ""
+}
+
+// JsonAttrName converts a prefixed json key such as yahoo:uri into an xml attribute name.
+func jsonAttrName(key string) xml.Name {
+	switch key {
+	case "yahoo:uri":
+		return xml.Name{Space: yahooNS, Local: "uri"}
+	case "xml:lang":
+		return xml.Name{Space: "xml", Local: "lang"}
+	}
+	return xml.Name{Local: key}
+}
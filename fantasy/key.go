@@ -0,0 +1,264 @@
+package fantasy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// KeyError is returned for a key which is not in the format Yahoo expects.
+type KeyError struct {
+	// Kind is the kind of key e.g. league.
+	Kind string
+	Key  string
+}
+
+func (e *KeyError) Error() string {
+	return fmt.Sprintf("Malformed %s key %q", e.Kind, e.Key)
+}
+
+// GameKey identifies a Game, it is either a game id e.g. 357 or a game code e.g. mlb.
+// A game code refers to the current season of the game.
+type GameKey struct {
+	// ID is the game id, it is zero when the key is a game code.
+	ID int64
+	// Code is the game code, it is empty when the key is a game id.
+	Code string
+}
+
+// ParseGameKey parses a game id or game code.
+func ParseGameKey(s string) (GameKey, error) {
+	k, ok := parseGame(s)
+	if !ok {
+		return GameKey{}, &KeyError{"game", s}
+	}
+	return k, nil
+}
+
+// String formats the key e.g. 357.
+func (k GameKey) String() string {
+	if k.Code != "" {
+		return k.Code
+	}
+	return strconv.FormatInt(k.ID, 10)
+}
+
+// League returns the key of league id within the game.
+func (k GameKey) League(id int64) LeagueKey {
+	return LeagueKey{Game: k, ID: id}
+}
+
+// Player returns the key of player id within the game.
+func (k GameKey) Player(id int64) PlayerKey {
+	return PlayerKey{Game: k, ID: id}
+}
+
+// MarshalText formats the key.
+func (k GameKey) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText parses the key.
+func (k *GameKey) UnmarshalText(b []byte) error {
+	p, err := ParseGameKey(string(b))
+	*k = p
+	return err
+}
+
+// LeagueKey identifies a League e.g. 357.l.86753
+type LeagueKey struct {
+	Game GameKey
+	// ID is the league id within the game.
+	ID int64
+}
+
+// ParseLeagueKey parses a league key e.g. 357.l.86753
+func ParseLeagueKey(s string) (LeagueKey, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 || parts[1] != "l" {
+		return LeagueKey{}, &KeyError{"league", s}
+	}
+
+	g, ok := parseGame(parts[0])
+	id, idOk := parseID(parts[2])
+	if !ok || !idOk {
+		return LeagueKey{}, &KeyError{"league", s}
+	}
+	return LeagueKey{Game: g, ID: id}, nil
+}
+
+// String formats the key e.g. 357.l.86753
+func (k LeagueKey) String() string {
+	return k.Game.String() + ".l." + strconv.FormatInt(k.ID, 10)
+}
+
+// GameKey returns the key of the game the league belongs to.
+func (k LeagueKey) GameKey() GameKey {
+	return k.Game
+}
+
+// Team returns the key of team id within the league.
+func (k LeagueKey) Team(id int64) TeamKey {
+	return TeamKey{League: k, ID: id}
+}
+
+// MarshalText formats the key.
+func (k LeagueKey) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText parses the key.
+func (k *LeagueKey) UnmarshalText(b []byte) error {
+	p, err := ParseLeagueKey(string(b))
+	*k = p
+	return err
+}
+
+// TeamKey identifies a Team e.g. 357.l.86753.t.4
+type TeamKey struct {
+	League LeagueKey
+	// ID is the team id within the league.
+	ID int64
+}
+
+// ParseTeamKey parses a team key e.g. 357.l.86753.t.4
+func ParseTeamKey(s string) (TeamKey, error) {
+	i := strings.LastIndex(s, ".t.")
+	if i < 0 {
+		return TeamKey{}, &KeyError{"team", s}
+	}
+
+	l, err := ParseLeagueKey(s[:i])
+	id, ok := parseID(s[i+3:])
+	if err != nil || !ok {
+		return TeamKey{}, &KeyError{"team", s}
+	}
+	return TeamKey{League: l, ID: id}, nil
+}
+
+// String formats the key e.g. 357.l.86753.t.4
+func (k TeamKey) String() string {
+	return k.League.String() + ".t." + strconv.FormatInt(k.ID, 10)
+}
+
+// LeagueKey returns the key of the league the team belongs to.
+func (k TeamKey) LeagueKey() LeagueKey {
+	return k.League
+}
+
+// GameKey returns the key of the game the team belongs to.
+func (k TeamKey) GameKey() GameKey {
+	return k.League.Game
+}
+
+// MarshalText formats the key.
+func (k TeamKey) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText parses the key.
+func (k *TeamKey) UnmarshalText(b []byte) error {
+	p, err := ParseTeamKey(string(b))
+	*k = p
+	return err
+}
+
+// PlayerKey identifies a Player e.g. 357.p.8967
+type PlayerKey struct {
+	Game GameKey
+	// ID is the player id within the game.
+	ID int64
+}
+
+// ParsePlayerKey parses a player key e.g. 357.p.8967
+func ParsePlayerKey(s string) (PlayerKey, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 || parts[1] != "p" {
+		return PlayerKey{}, &KeyError{"player", s}
+	}
+
+	g, ok := parseGame(parts[0])
+	id, idOk := parseID(parts[2])
+	if !ok || !idOk {
+		return PlayerKey{}, &KeyError{"player", s}
+	}
+	return PlayerKey{Game: g, ID: id}, nil
+}
+
+// String formats the key e.g. 357.p.8967
+func (k PlayerKey) String() string {
+	return k.Game.String() + ".p." + strconv.FormatInt(k.ID, 10)
+}
+
+// GameKey returns the key of the game the player belongs to.
+func (k PlayerKey) GameKey() GameKey {
+	return k.Game
+}
+
+// MarshalText formats the key.
+func (k PlayerKey) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText parses the key.
+func (k *PlayerKey) UnmarshalText(b []byte) error {
+	p, err := ParsePlayerKey(string(b))
+	*k = p
+	return err
+}
+
+// ParseGame parses a game id or a lower case game code.
+func parseGame(s string) (GameKey, bool) {
+	if id, ok := parseID(s); ok {
+		return GameKey{ID: id}, true
+	}
+
+	if s == "" {
+		return GameKey{}, false
+	}
+	for _, c := range s {
+		if c < 'a' || c > 'z' {
+			return GameKey{}, false
+		}
+	}
+	return GameKey{Code: s}, true
+}
+
+// ParseID parses a positive decimal id without sign or leading zeros.
+func parseID(s string) (int64, bool) {
+	if s == "" || s[0] == '0' || s[0] == '+' || s[0] == '-' {
+		return 0, false
+	}
+	id, err := strconv.ParseInt(s, 10, 64)
+	return id, err == nil
+}
+
+// ValidateKeys returns the first error from parsing each key.
+func validateKeys(keys []string, parse func(string) error) error {
+	for _, k := range keys {
+		if err := parse(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validGameKey(s string) error {
+	_, err := ParseGameKey(s)
+	return err
+}
+
+func validLeagueKey(s string) error {
+	_, err := ParseLeagueKey(s)
+	return err
+}
+
+func validTeamKey(s string) error {
+	_, err := ParseTeamKey(s)
+	return err
+}
+
+func validPlayerKey(s string) error {
+	_, err := ParsePlayerKey(s)
+	return err
+}
@@ -0,0 +1,132 @@
+package fantasy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/muswell/gotest"
+)
+
+func TestParseKeys(t *testing.T) {
+	game, err := ParseGameKey("357")
+	if err != nil || game.ID != 357 || game.Code != "" || game.String() != "357" {
+		t.Errorf("ParseGameKey(357) = %+v, %v", game, err)
+	}
+
+	code, err := ParseGameKey("mlb")
+	if err != nil || code.ID != 0 || code.Code != "mlb" || code.String() != "mlb" {
+		t.Errorf("ParseGameKey(mlb) = %+v, %v", code, err)
+	}
+
+	league, err := ParseLeagueKey("357.l.86753")
+	if err != nil || league.ID != 86753 || league.GameKey() != game || league.String() != "357.l.86753" {
+		t.Errorf("ParseLeagueKey(357.l.86753) = %+v, %v", league, err)
+	}
+
+	team, err := ParseTeamKey("357.l.86753.t.4")
+	if err != nil || team.ID != 4 || team.LeagueKey() != league || team.GameKey() != game || team.String() != "357.l.86753.t.4" {
+		t.Errorf("ParseTeamKey(357.l.86753.t.4) = %+v, %v", team, err)
+	}
+
+	player, err := ParsePlayerKey("357.p.8967")
+	if err != nil || player.ID != 8967 || player.GameKey() != game || player.String() != "357.p.8967" {
+		t.Errorf("ParsePlayerKey(357.p.8967) = %+v, %v", player, err)
+	}
+
+	// keys are built from their parents.
+	if k := game.League(86753).Team(4); k != team {
+		t.Errorf("GameKey.League.Team = %v, expected %v", k, team)
+	}
+	if k := game.Player(8967); k != player {
+		t.Errorf("GameKey.Player = %v, expected %v", k, player)
+	}
+	if k := code.League(1).String(); k != "mlb.l.1" {
+		t.Errorf("Game code league key = %s, expected mlb.l.1", k)
+	}
+}
+
+func TestParseKeyErrors(t *testing.T) {
+	tests := map[string]func(string) error{
+		"":                  validGameKey,
+		"abc1":              validGameKey,
+		"MLB":               validGameKey,
+		"-357":              validGameKey,
+		"abc":               validLeagueKey,
+		"357.l.":            validLeagueKey,
+		"357.t.86753":       validLeagueKey,
+		"357.l.86753.t.4":   validLeagueKey,
+		"357.l.0":           validLeagueKey,
+		"357.l.86753":       validTeamKey,
+		"357.l.86753.t.x":   validTeamKey,
+		"357.l.86753.t.4.5": validTeamKey,
+		"357.p.":            validPlayerKey,
+		"357.l.8967":        validPlayerKey,
+		"357.p.8967x":       validPlayerKey,
+	}
+
+	for key, valid := range tests {
+		err := valid(key)
+		if _, ok := err.(*KeyError); !ok {
+			t.Errorf("Expected a KeyError for %q got %v", key, err)
+		}
+	}
+}
+
+func TestKeyText(t *testing.T) {
+	var v struct {
+		Team   TeamKey   `json:"team"`
+		Player PlayerKey `json:"player"`
+	}
+
+	if err := json.Unmarshal([]byte(`{"team":"357.l.86753.t.4","player":"357.p.8967"}`), &v); err != nil {
+		t.Fatalf("Unexpected json.Unmarshal error: %v", err)
+	}
+	if v.Team.ID != 4 || v.Player.ID != 8967 {
+		t.Errorf("Keys unmarshaled incorrectly %+v", v)
+	}
+
+	data, _ := json.Marshal(v)
+	if string(data) != `{"team":"357.l.86753.t.4","player":"357.p.8967"}` {
+		t.Errorf("Keys marshaled incorrectly %s", data)
+	}
+
+	if err := json.Unmarshal([]byte(`{"team":"357.l.86753"}`), &v); err == nil {
+		t.Error("Expected an error unmarshaling a malformed team key")
+	}
+}
+
+func TestQueryBuilderValidate(t *testing.T) {
+	client := gotest.NewRegisteredClient()
+
+	tests := []interface {
+		Validate() error
+	}{
+		&GameQueryBuilder{Keys: []string{"357", "mlb", "357.l.1"}},
+		&LeagueQueryBuilder{Keys: []string{"357.l.86753", "357.t.1"}},
+		&TeamQueryBuilder{Keys: []string{"357.l.86753"}},
+		&TeamQueryBuilder{LeagueQB: &LeagueQueryBuilder{Keys: []string{"86753"}}},
+		&PlayerQueryBuilder{Keys: []string{"357.p.8967", "8967"}},
+		&PlayerQueryBuilder{GameQB: &GameQueryBuilder{Keys: []string{"MLB"}}},
+		&TransactionQueryBuilder{LeagueQB: &LeagueQueryBuilder{Keys: []string{"357.l.86753"}}, TeamKey: "4"},
+		&UserQueryBuilder{GameQB: &GameQueryBuilder{Keys: []string{"357.p.1"}}},
+	}
+
+	for _, q := range tests {
+		if _, ok := q.Validate().(*KeyError); !ok {
+			t.Errorf("Expected a KeyError validating %+v", q)
+		}
+	}
+
+	// no request is sent for malformed keys.
+	if _, err := (&TeamQueryBuilder{Keys: []string{"357.l.86753"}}).Get(client.Client); err == nil {
+		t.Error("Expected TeamQueryBuilder.Get to return an error")
+	}
+	if err := (&PlayerQueryBuilder{Keys: []string{"8967"}}).Stream(client.Client, func(Player) error { return nil }); err == nil {
+		t.Error("Expected PlayerQueryBuilder.Stream to return an error")
+	}
+
+	valid := &PlayerQueryBuilder{LeagueQB: &LeagueQueryBuilder{Keys: []string{"357.l.86753"}}, Keys: []string{"357.p.8967"}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Unexpected Validate error: %v", err)
+	}
+}
@@ -3,7 +3,7 @@ package fantasy
 
 import (
 	"encoding/xml"
-	"io/ioutil"
+	"fmt"
 	"net/http"
 	"strings"
 )
@@ -30,31 +30,53 @@ type League struct {
 	// The id needed to initiate chat within the league
 	ChatID string `xml:"league_chat_id"`
 	// What status the draft for this league currently has.
-	DraftStatus string `xml:"dratf_status"`
+	DraftStatus DraftStatus `xml:"draft_status"`
 	// The number of teams signed up for this league.
 	NumTeams int64 `xml:"num_teams"`
+	// EditKey is the date or week of the rosters which can currently be edited.
+	EditKey string `xml:"edit_key"`
+	// WeeklyDeadline is when weekly roster changes lock e.g. intraday, empty for daily leagues.
+	WeeklyDeadline string `xml:"weekly_deadline"`
+	// UpdateTimestamp is when the league's stats were last updated.
+	UpdateTimestamp Timestamp `xml:"league_update_timestamp"`
 	// The stlye of scoring the league uses.
-	ScoringType string `xml:"scoring_type"`
+	ScoringType ScoringType `xml:"scoring_type"`
 	// Public or private.
-	LeagueType string `xml:"league_type"`
+	LeagueType LeagueType `xml:"league_type"`
+	// Renew is the previous season's league this league was renewed from, in the format gameid_leagueid, empty for a new league.
+	Renew string `xml:"renew"`
+	// Renewed is the next season's league renewed from this league, in the format gameid_leagueid, empty until it is renewed.
+	Renewed string `xml:"renewed"`
+	// ShortInvitationURL is the link used to invite managers to the league.
+	ShortInvitationURL string `xml:"short_invitation_url"`
+	// IsProLeague is true for Yahoo's paid Pro leagues.
+	IsProLeague Bool `xml:"is_pro_league"`
+	// IsCashLeague is true for leagues with an entry fee and cash prizes.
+	IsCashLeague Bool `xml:"is_cash_league"`
+	// CurrentWeek is the current scoring week, empty for leagues without weeks.
+	CurrentWeek Int `xml:"current_week"`
+	// StartWeek is the first scoring week of the season.
+	StartWeek Int `xml:"start_week"`
+	// EndWeek is the last scoring week of the season.
+	EndWeek Int `xml:"end_week"`
+	// IsFinished is true once the season is over.
+	IsFinished Bool `xml:"is_finished"`
 	// The beginning date of the season
-	StartDate calendarDate `xml:"start_date"`
+	StartDate Date `xml:"start_date"`
 	// The end date of the season
-	EndDate calendarDate `xml:"end_date"`
+	EndDate Date `xml:"end_date"`
 	//The code of the associated game type.
 	GameCode string `xml:"game_code"`
 	// 4 digit year
 	Season int64 `xml:"season"`
-	// A pointer to the League Settings.
-	//*Settings `xml:"settings"`
+	// Settings are the rules of the league, nil unless requested with LeagueQueryBuilder.Settings.
+	Settings *Settings `xml:"settings"`
 	// A pointer to the League Standings.
 	//*Standings `xml:"standings"`
 	// A pointer to the League Scoreboard.
 	//*Scoreboard `xml:"scoreboard"`
 	// A pointer to the League Teams.
 	//*Teams `xml:"teams"`
-	// A pointer to the League Settings.
-	//*Settings `xml:"settings"`
 	// A pointer to the League's eligible Players.
 	//*Players `xml:"players"`
 	// A pointer to the League Draft.
@@ -63,13 +85,109 @@ type League struct {
 	//*Transactions `xml:"transactions"`
 }
 
+// RenewKey returns the key of the previous season's league, ok is false when the league was not renewed from another.
+func (l League) RenewKey() (key LeagueKey, ok bool, err error) {
+	return parseRenew(l.Renew)
+}
+
+// RenewedKey returns the key of the next season's league, ok is false when the league has not been renewed.
+func (l League) RenewedKey() (key LeagueKey, ok bool, err error) {
+	return parseRenew(l.Renewed)
+}
+
+// ParseRenew parses a renew or renewed value e.g. 346_86753 into a league key.
+func parseRenew(s string) (LeagueKey, bool, error) {
+	if s == "" {
+		return LeagueKey{}, false, nil
+	}
+
+	parts := strings.Split(s, "_")
+	if len(parts) != 2 {
+		return LeagueKey{}, false, fmt.Errorf("Malformed league renew value %q", s)
+	}
+
+	game, gameOk := parseID(parts[0])
+	id, idOk := parseID(parts[1])
+	if !gameOk || !idOk {
+		return LeagueKey{}, false, fmt.Errorf("Malformed league renew value %q", s)
+	}
+	return LeagueKey{Game: GameKey{ID: game}, ID: id}, true, nil
+}
+
+// LeagueHistory returns every season of the league with key, oldest first,
+// by following the Renew links to earlier seasons and the Renewed links to later ones.
+// Each season is a separate request.
+func LeagueHistory(client *http.Client, key string) ([]League, error) {
+	league, err := getLeague(client, key)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{league.Key: true}
+	history := []League{league}
+
+	// previous seasons, prepended so the oldest comes first.
+	for l := league; ; {
+		k, ok, err := l.RenewKey()
+		if err != nil {
+			return history, err
+		}
+		if !ok || seen[k.String()] {
+			break
+		}
+
+		if l, err = getLeague(client, k.String()); err != nil {
+			return history, err
+		}
+		seen[l.Key] = true
+		history = append([]League{l}, history...)
+	}
+
+	// later seasons.
+	for l := league; ; {
+		k, ok, err := l.RenewedKey()
+		if err != nil {
+			return history, err
+		}
+		if !ok || seen[k.String()] {
+			break
+		}
+
+		if l, err = getLeague(client, k.String()); err != nil {
+			return history, err
+		}
+		seen[l.Key] = true
+		history = append(history, l)
+	}
+
+	return history, nil
+}
+
+// GetLeague requests the metadata of a single league.
+func getLeague(client *http.Client, key string) (League, error) {
+	q := LeagueQueryBuilder{Keys: []string{key}}
+	leagues, err := q.Get(client)
+	if err != nil {
+		return League{}, err
+	}
+	if len(leagues) == 0 {
+		return League{}, fmt.Errorf("League %s not found", key)
+	}
+	return leagues[0], nil
+}
+
 //LeagueQueryBuilder contains properties which are used to generate yahoo api league requests.
 type LeagueQueryBuilder struct {
 	// Add a UserQueryBuilder to filter results by user info.
 	UserQB *UserQueryBuilder
 	// Add League Keys to return specific leagues.
 	Keys []string
-	// todo include settings, standings...
+	// Settings set to true includes each league's Settings.
+	Settings bool
+	// Format is the response format to request, xml by default.
+	Format Format
+	// Batching controls how a query for more keys than Yahoo accepts is split into requests.
+	Batching Batching
 }
 
 //Path returns the yahoo api path for the query excluding the host and query string.
@@ -90,17 +208,21 @@ func (q *LeagueQueryBuilder) Path() string {
 		path += ";league_keys=" + strings.Join(q.Keys, ",")
 	}
 
+	if q.Settings {
+		path += ";out=settings"
+	}
+
 	return strings.TrimLeft(path, "/")
 }
 
 // Url generates the url needed for a request of the query builder's settings.
 func (q *LeagueQueryBuilder) Url() string {
-	return baseUrl + q.Path() + "?format=xml"
+	return baseUrl + q.Path() + "?format=" + q.Format.String()
 }
 
-// XmlLeagueParser must be able to parse a byte slice of xml data and return a slice of Leagues.
+// XmlLeagueParser must be able to decode an xml token stream and return a slice of Leagues.
 type xmlLeagueParser interface {
-	parseXML([]byte) ([]League, error)
+	parseXML(*xml.Decoder) ([]League, ResponseMeta, error)
 }
 
 // DefaultXMLLeagueParser parses xml with a leagues node as a direct child of the fantasy_content node.
@@ -108,18 +230,19 @@ type defaultXMLLeagueParser struct {
 	result struct {
 		// XMLName fantasy_content is the main wrapper tag in a Yahoo api response.
 		XMLName xml.Name `xml:"fantasy_content"`
+		responseAttrs
 		//Games is where a GameBuilderQuery stores its results.
 		Leagues []League `xml:"leagues>league"`
 	}
 }
 
 // ParseXML actually does the transformation from xml to League slice.
-func (p defaultXMLLeagueParser) parseXML(data []byte) ([]League, error) {
-	err := xml.Unmarshal(data, &p.result)
+func (p defaultXMLLeagueParser) parseXML(d *xml.Decoder) ([]League, ResponseMeta, error) {
+	err := d.Decode(&p.result)
 	if err != nil {
-		return []League{}, err
+		return []League{}, ResponseMeta{}, err
 	}
-	return p.result.Leagues, nil
+	return p.result.Leagues, p.result.meta(), nil
 }
 
 //UserXMLLeagueParser parses xml with a structure fantasy_conent>users>user>games>game.
@@ -127,6 +250,7 @@ type userXMLLeagueParser struct {
 	result struct {
 		// XMLName fantasy_content is the main wrapper tag in a Yahoo api response.
 		XMLName xml.Name `xml:"fantasy_content"`
+		responseAttrs
 		//Games is where a GameBuilderQuery stores its results.
 		Leagues []League `xml:"users>user>games>game>leagues>league"`
 	}
@@ -134,14 +258,14 @@ type userXMLLeagueParser struct {
 
 // todo create generic function for parseXML.
 // ParseXML actually does the transformation from xml to League slice.
-func (p userXMLLeagueParser) parseXML(data []byte) ([]League, error) {
-	err := xml.Unmarshal(data, &p.result)
+func (p userXMLLeagueParser) parseXML(d *xml.Decoder) ([]League, ResponseMeta, error) {
+	err := d.Decode(&p.result)
 
 	if err != nil {
-		return []League{}, err
+		return []League{}, ResponseMeta{}, err
 	}
 
-	return p.result.Leagues, nil
+	return p.result.Leagues, p.result.meta(), nil
 }
 
 // XmlParser returns the appropriate xml parser based on the query builder settings.
@@ -155,18 +279,62 @@ func (q *LeagueQueryBuilder) xmlParser() xmlLeagueParser {
 // Get sends a request to the appropriate url based on the query builder settings.
 // It then sends that response to a parser and returns the resulting League slice.
 func (q *LeagueQueryBuilder) Get(client *http.Client) ([]League, error) {
-	url := q.Url()
+	leagues, _, err := q.GetWithMeta(client)
+	return leagues, err
+}
 
-	resp, err := client.Get(url)
-	if err != nil {
-		return []League{}, err
+// Validate checks Keys are well formed league keys, along with the keys of nested query builders.
+func (q *LeagueQueryBuilder) Validate() error {
+	if q.UserQB != nil {
+		if err := q.UserQB.Validate(); err != nil {
+			return err
+		}
+	}
+	return validateKeys(q.Keys, validLeagueKey)
+}
+
+// GetWithMeta works like Get but also returns the metadata of the response.
+// When Keys holds more keys than Batching allows the leagues are requested in batches,
+// the metadata is then that of the first successful batch.
+func (q *LeagueQueryBuilder) GetWithMeta(client *http.Client) ([]League, ResponseMeta, error) {
+	if err := q.Validate(); err != nil {
+		return []League{}, ResponseMeta{}, err
 	}
-	defer resp.Body.Close()
 
-	data, err := ioutil.ReadAll(resp.Body)
+	if len(q.Keys) > q.Batching.maxKeys() {
+		return q.getBatched(client)
+	}
+
+	d, err := fetch(client, q.Url(), q.Format)
 	if err != nil {
-		return []League{}, err
+		return []League{}, ResponseMeta{}, err
 	}
 
-	return q.xmlParser().parseXML(data)
+	return q.xmlParser().parseXML(d)
+}
+
+// GetBatched requests the leagues in chunks of keys and merges the results in key order.
+func (q *LeagueQueryBuilder) getBatched(client *http.Client) ([]League, ResponseMeta, error) {
+	chunks := chunkKeys(q.Keys, q.Batching.maxKeys())
+	results := make([][]League, len(chunks))
+	metas := make([]ResponseMeta, len(chunks))
+
+	err := batch(chunks, q.Batching.workers(), func(i int, keys []string) error {
+		chunk := *q
+		chunk.Keys = keys
+
+		var err error
+		results[i], metas[i], err = chunk.GetWithMeta(client)
+		return err
+	})
+
+	leagues := []League{}
+	for _, r := range results {
+		leagues = append(leagues, r...)
+	}
+
+	// yahoo does not return a chunk in the order of its keys.
+	newKeyOrder(q.Keys).sort(leagues, func(i int) []string { return []string{leagues[i].Key} })
+
+	return leagues, firstMeta(metas), err
 }
@@ -1,6 +1,7 @@
 package fantasy
 
 import (
+	"fmt"
 	"github.com/muswell/gotest"
 	"io/ioutil"
 	"os"
@@ -21,6 +22,14 @@ func TestLeagueQueryBuilderURL(t *testing.T) {
 			LeagueQueryBuilder{Keys: []string{"357.l.37903", "357.l.37825"}},
 			baseUrl + "leagues;league_keys=357.l.37903,357.l.37825?format=xml",
 		},
+		{
+			LeagueQueryBuilder{Keys: []string{"357.l.37903"}, Settings: true},
+			baseUrl + "leagues;league_keys=357.l.37903;out=settings?format=xml",
+		},
+		{
+			LeagueQueryBuilder{Keys: []string{"357.l.37903"}, Format: FormatJSON},
+			baseUrl + "leagues;league_keys=357.l.37903?format=json",
+		},
 		{
 			LeagueQueryBuilder{
 				UserQB: &UserQueryBuilder{
@@ -55,10 +64,18 @@ func TestLeagueQueryBuilderURL(t *testing.T) {
 func TestQueryLeagueErrors(t *testing.T) {
 	qb := LeagueQueryBuilder{Keys: []string{"abc"}}
 	client := gotest.NewRegisteredClient()
+
+	// test malformed key, no request is sent.
+	_, err := qb.Get(client.Client)
+	if _, ok := err.(*KeyError); !ok {
+		t.Errorf("Expected QueryBuilder.Get to return a KeyError got %v", err)
+	}
+
+	qb.Keys = []string{"357.l.86753"}
 	url := qb.Url()
 
 	// test bad client request
-	_, err := qb.Get(client.Client)
+	_, err = qb.Get(client.Client)
 	if err == nil {
 		t.Error("Expected QueryBuilder.Get to return an error")
 	}
@@ -82,6 +99,8 @@ func TestLeagueQueries(t *testing.T) {
 	var tests = []queryTest{
 		getSingleMetaTestSet(t),
 		getUserSingleMetaTestSet(t),
+		getSingleMetaJSONTestSet(t),
+		getUserSingleMetaJSONTestSet(t),
 	}
 
 	for _, test := range tests {
@@ -118,6 +137,12 @@ func getSingleMetaTestSet(t *testing.T) queryTest {
 			if start != "2016-03-04" {
 				t.Errorf("League unmarshaled incorrectley. StartDate: %s, expected %s", start, "2016-03-04")
 			}
+			if league.EditKey != "2016-02-22" || league.ShortInvitationURL != "https://yho.com/mlb?l=86753&ikey=f66d591b945611b5" {
+				t.Errorf("League unmarshaled incorrectley. EditKey: %s, ShortInvitationURL: %s", league.EditKey, league.ShortInvitationURL)
+			}
+			if league.Renew != "" || league.Renewed != "" || !league.UpdateTimestamp.IsZero() || league.CurrentWeek.Valid || bool(league.IsCashLeague) {
+				t.Errorf("League unmarshaled incorrectley, expected empty renew, timestamp and weeks: %+v", league)
+			}
 		},
 	}
 }
@@ -133,6 +158,47 @@ func getUserSingleMetaTestSet(t *testing.T) queryTest {
 	}
 }
 
+func getSingleMetaJSONTestSet(t *testing.T) queryTest {
+	q := LeagueQueryBuilder{Keys: []string{"357.l.86753"}, Format: FormatJSON}
+	url := q.Url()
+
+	return queryTest{
+		qb:     &q,
+		client: getXMLClient(url, "single-league-meta.json", t),
+		want:   1,
+		next: func(l []League, t *testing.T) {
+			league := l[0]
+
+			if league.Key != "357.l.86753" {
+				t.Errorf("League unmarshaled incorrectley. Key: %s, expected %s", league.Key, "357.l.86753")
+			}
+			if league.NumTeams != 10 {
+				t.Errorf("League unmarshaled incorrectley. NumTeams: %d, expected %d", league.NumTeams, 10)
+			}
+			start := time.Time(league.StartDate).Format("2006-01-02")
+			if start != "2016-04-03" {
+				t.Errorf("League unmarshaled incorrectley. StartDate: %s, expected %s", start, "2016-04-03")
+			}
+		},
+	}
+}
+
+func getUserSingleMetaJSONTestSet(t *testing.T) queryTest {
+	q := LeagueQueryBuilder{UserQB: &UserQueryBuilder{ActiveUser: true}, Format: FormatJSON}
+	url := q.Url()
+
+	return queryTest{
+		qb:     &q,
+		client: getXMLClient(url, "user-leagues-meta.json", t),
+		want:   1,
+		next: func(l []League, t *testing.T) {
+			if l[0].Name != "Ashtray League Bochyball" {
+				t.Errorf("League unmarshaled incorrectley. Name: %s, expected %s", l[0].Name, "Ashtray League Bochyball")
+			}
+		},
+	}
+}
+
 func getXMLClient(url, filename string, t *testing.T) *gotest.RegisteredClient {
 	client := gotest.NewRegisteredClient()
 
@@ -152,3 +218,52 @@ func getXMLClient(url, filename string, t *testing.T) *gotest.RegisteredClient {
 
 	return client
 }
+
+func TestLeagueHistory(t *testing.T) {
+	seasons := []struct {
+		key, renew, renewed string
+	}{
+		{"328.l.111", "", "346_222"},
+		{"346.l.222", "328_111", "357_86753"},
+		{"357.l.86753", "346_222", ""},
+	}
+
+	client := gotest.NewRegisteredClient()
+	for _, s := range seasons {
+		q := LeagueQueryBuilder{Keys: []string{s.key}}
+		body := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<fantasy_content xmlns:yahoo="http://www.yahooapis.com/v1/base.rng" xmlns="http://fantasysports.yahooapis.com/fantasy/v2/base.rng">
+  <leagues count="1"><league><league_key>%s</league_key><renew>%s</renew><renewed>%s</renewed><current_week>3</current_week><is_finished>1</is_finished></league></leagues>
+</fantasy_content>`, s.key, s.renew, s.renewed)
+		client.Register(q.Url(), "get", gotest.NewSimpleRoundTrip([]byte(body), nil))
+	}
+
+	history, err := LeagueHistory(client.Client, "346.l.222")
+	if err != nil {
+		t.Fatalf("Unexpected LeagueHistory error: %v", err)
+	}
+
+	if len(history) != len(seasons) {
+		t.Fatalf("LeagueHistory returned %d leagues, expected %d", len(history), len(seasons))
+	}
+	for i, s := range seasons {
+		if history[i].Key != s.key {
+			t.Errorf("LeagueHistory[%d] = %s, expected %s", i, history[i].Key, s.key)
+		}
+	}
+	if history[0].CurrentWeek != NewInt(3) || !history[0].IsFinished {
+		t.Errorf("League unmarshaled incorrectley. CurrentWeek: %v, IsFinished: %t", history[0].CurrentWeek, history[0].IsFinished)
+	}
+
+	prev, ok, err := history[2].RenewKey()
+	if err != nil || !ok || prev.String() != "346.l.222" {
+		t.Errorf("RenewKey = %s, %t, %v, expected 346.l.222", prev, ok, err)
+	}
+	if _, ok, err := history[2].RenewedKey(); ok || err != nil {
+		t.Errorf("RenewedKey = %t, %v, expected no key", ok, err)
+	}
+
+	if _, _, err := (League{Renew: "346-222"}).RenewKey(); err == nil {
+		t.Error("Expected a RenewKey error for 346-222")
+	}
+}
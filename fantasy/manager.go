@@ -4,15 +4,15 @@ package fantasy
 // Manager type represents a single Yahoo fantasy team manager.
 type Manager struct {
 	// Guid is the unique ID of the user
-	Guid string
+	Guid string `xml:"guid"`
 	// ManagerID is the id of the manager within the League.
 	ManagerID string `xml:"manager_id"`
 	// Name is the nickname the manager is using within the League.
 	Name string `xml:"nickname"`
 	// Email is the email address the manager is using within the League.
-	Email string
+	Email string `xml:"email"`
 	// ImageURL is the address of the manager's avatar
 	ImageURL string `xml:"image_url"`
 	// IsCurrentLogin is a bool value indicating if this manager is the logged in user
-	IsActiveUser intAsBool `xml:"is_current_login"`
+	IsActiveUser Bool `xml:"is_current_login"`
 }
@@ -1,11 +1,11 @@
 package fantasy
 
-/*import (
-	"encoding/json"
+import (
+	"encoding/xml"
 	"testing"
-)*/
+)
 
-/*func TestManagerUnmarshal(t *testing.T) {
+func TestManagerUnmarshal(t *testing.T) {
 	j := []byte(`{
 		"manager": {
 			"manager_id": "4",
@@ -18,7 +18,10 @@ package fantasy
 	}`)
 
 	manager := Manager{}
-	err := json.Unmarshal(j, &manager)
+	d, err := newDecoder(j, FormatJSON)
+	if err == nil {
+		err = d.Decode(&manager)
+	}
 
 	if err != nil {
 		t.Errorf("Could not unmarshal manager: %v", err)
@@ -33,9 +36,9 @@ package fantasy
 	if manager.Guid != "ABC" {
 		t.Errorf("Manager unmarshal produced incorrect Guid: got %s expected %s", manager.Guid, "ABC")
 	}
-	if !manager.IsCurrentLogin {
-		t.Errorf("Manager unmarshal produced incorrect IsCurrentLogin: got %t expected %t",
-			manager.IsCurrentLogin, true)
+	if !manager.IsActiveUser {
+		t.Errorf("Manager unmarshal produced incorrect IsActiveUser: got %t expected %t",
+			manager.IsActiveUser, true)
 	}
 	if manager.Email != "slickrickjamesbrown@gmail.com" {
 		t.Errorf("Manager unmarshal produced incorrect Email: got %s expected %s", manager.Email, "slickrickjamesbrown@gmail.com")
@@ -44,9 +47,10 @@ package fantasy
 		t.Errorf("Manager unmarshal produced incorrect ImageURL: got %s expected %s",
 			manager.ImageURL, "https://s.yimg.com/dh/ap/social/profile/profile_b64.png")
 	}
-}*/
+}
 
-/*func UnmarshalManagerList(t *testing.T) {
+func TestUnmarshalManagerList(t *testing.T) {
+	// managers is a list of single key objects in Yahoo json.
 	j := []byte(`{
 		"managers": [
 			{
@@ -72,11 +76,25 @@ package fantasy
 		]
 	}`)
 
-	managers := []Manager{}
+	result := struct {
+		XMLName  xml.Name  `xml:"managers"`
+		Managers []Manager `xml:"manager"`
+	}{}
 
-	err := json.Unmarshal(j, &managers)
+	d, err := newDecoder(j, FormatJSON)
+	if err == nil {
+		err = d.Decode(&result)
+	}
 
 	if err != nil {
-		t.Errorf("Could not unmarshal manager: %v", err)
+		t.Errorf("Could not unmarshal managers: %v", err)
+	}
+
+	if len(result.Managers) != 2 {
+		t.Fatalf("Incorrect number of managers got %d, expected %d", len(result.Managers), 2)
+	}
+
+	if result.Managers[1].Guid != "DEF" || !result.Managers[1].IsActiveUser {
+		t.Errorf("Second manager unmarshalled incorrectly: %v", result.Managers[1])
 	}
-}*/
+}
@@ -0,0 +1,409 @@
+package fantasy
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Player represents a single professional athlete within a Yahoo fantasy Game.
+type Player struct {
+	XMLName xml.Name `xml:"player"`
+	// Key is the unique player identifier, it is made of the game key and the player ID e.g. 357.p.8967
+	Key string `xml:"player_key"`
+	// ID is the id of the player within the Game.
+	ID int64 `xml:"player_id"`
+	// Name contains the player's full, first and last names.
+	Name PlayerName `xml:"name"`
+	// Status is the player's injury or availability status e.g. DL, it is empty for healthy players.
+	Status string `xml:"status"`
+	// EditorialTeamAbbr is the abbreviation of the professional team the player plays for e.g. SF.
+	EditorialTeamAbbr string `xml:"editorial_team_abbr"`
+	// EditorialTeamFullName is the name of the professional team the player plays for.
+	EditorialTeamFullName string `xml:"editorial_team_full_name"`
+	// UniformNumber is the player's jersey number.
+	UniformNumber string `xml:"uniform_number"`
+	// DisplayPosition is a comma separated list of the player's positions e.g. 1B,OF.
+	DisplayPosition string `xml:"display_position"`
+	// PositionType is the broad position group of the player e.g. B for batters, P for pitchers.
+	PositionType string `xml:"position_type"`
+	// EligiblePositions are the roster positions the player can be placed in.
+	EligiblePositions []string `xml:"eligible_positions>position"`
+	// ImageURL is the address of the player's headshot.
+	ImageURL string `xml:"image_url"`
+	// IsUndroppable is true for players who can not be dropped from a roster.
+	IsUndroppable Bool `xml:"is_undroppable"`
+}
+
+// PlayerName contains the different forms of a player's name.
+type PlayerName struct {
+	Full       string `xml:"full"`
+	First      string `xml:"first"`
+	Last       string `xml:"last"`
+	ASCIIFirst string `xml:"ascii_first"`
+	ASCIILast  string `xml:"ascii_last"`
+}
+
+// PlayerQueryBuilder contains properties which are used to generate yahoo api player requests.
+type PlayerQueryBuilder struct {
+	// Add a LeagueQueryBuilder to return players within the context of leagues.
+	LeagueQB *LeagueQueryBuilder
+	// Add a GameQueryBuilder to return players within the context of games.
+	// GameQB is ignored when LeagueQB is set.
+	GameQB *GameQueryBuilder
+	// Add Player Keys to return specific players.
+	Keys []string
+	// Status filters players by availability e.g. A (all available), FA (free agents), W (waivers), T (taken).
+	Status string
+	// Position filters players by eligible position e.g. OF.
+	Position string
+	// Search filters players by name.
+	Search string
+	// Sort orders the players e.g. NAME, OR (overall rank), AR (actual rank), PTS or a stat id.
+	Sort string
+	// Start is the offset of the first player to return.
+	Start int
+	// Count is the maximum number of players to return, Yahoo returns at most 25.
+	Count int
+	// Format is the response format to request, xml by default.
+	Format Format
+	// Batching controls how a query for more keys than Yahoo accepts is split into requests.
+	Batching Batching
+}
+
+// Path returns the yahoo api path for the query excluding the host and query string.
+func (q *PlayerQueryBuilder) Path() string {
+	var path string
+	if q.LeagueQB != nil {
+		path = q.LeagueQB.Path()
+	} else if q.GameQB != nil {
+		path = q.GameQB.Path()
+	}
+
+	path += "/players"
+
+	if q.Keys != nil {
+		path += ";player_keys=" + strings.Join(q.Keys, ",")
+	}
+
+	if q.Status != "" {
+		path += ";status=" + q.Status
+	}
+
+	if q.Position != "" {
+		path += ";position=" + q.Position
+	}
+
+	if q.Search != "" {
+		path += ";search=" + q.Search
+	}
+
+	if q.Sort != "" {
+		path += ";sort=" + q.Sort
+	}
+
+	if q.Start > 0 {
+		path += ";start=" + strconv.Itoa(q.Start)
+	}
+
+	if q.Count > 0 {
+		path += ";count=" + strconv.Itoa(q.Count)
+	}
+
+	return strings.TrimLeft(path, "/")
+}
+
+// Url generates the url needed for a request of the query builder's settings.
+func (q *PlayerQueryBuilder) Url() string {
+	return baseUrl + q.Path() + "?format=" + q.Format.String()
+}
+
+// XmlPlayerParser must be able to decode an xml token stream and return a slice of Players.
+type xmlPlayerParser interface {
+	parseXML(*xml.Decoder) ([]Player, ResponseMeta, error)
+}
+
+// DefaultXMLPlayerParser parses xml with a players node as a direct child of the fantasy_content node.
+type defaultXMLPlayerParser struct {
+	result struct {
+		// XMLName fantasy_content is the main wrapper tag in a Yahoo api response.
+		XMLName xml.Name `xml:"fantasy_content"`
+		responseAttrs
+		//Players is where a PlayerQueryBuilder stores its results.
+		Players []Player `xml:"players>player"`
+	}
+}
+
+// ParseXML actually does the transformation from xml to Player slice.
+func (p defaultXMLPlayerParser) parseXML(d *xml.Decoder) ([]Player, ResponseMeta, error) {
+	err := d.Decode(&p.result)
+	if err != nil {
+		return []Player{}, ResponseMeta{}, err
+	}
+	return p.result.Players, p.result.meta(), nil
+}
+
+// LeagueXMLPlayerParser parses xml with a structure fantasy_content>leagues>league>players>player.
+type leagueXMLPlayerParser struct {
+	result struct {
+		// XMLName fantasy_content is the main wrapper tag in a Yahoo api response.
+		XMLName xml.Name `xml:"fantasy_content"`
+		responseAttrs
+		//Players is where a PlayerQueryBuilder stores its results.
+		Players []Player `xml:"leagues>league>players>player"`
+	}
+}
+
+// ParseXML actually does the transformation from xml to Player slice.
+func (p leagueXMLPlayerParser) parseXML(d *xml.Decoder) ([]Player, ResponseMeta, error) {
+	err := d.Decode(&p.result)
+	if err != nil {
+		return []Player{}, ResponseMeta{}, err
+	}
+	return p.result.Players, p.result.meta(), nil
+}
+
+// UserLeagueXMLPlayerParser parses xml with a structure fantasy_content>users>user>games>game>leagues>league>players>player.
+type userLeagueXMLPlayerParser struct {
+	result struct {
+		// XMLName fantasy_content is the main wrapper tag in a Yahoo api response.
+		XMLName xml.Name `xml:"fantasy_content"`
+		responseAttrs
+		//Players is where a PlayerQueryBuilder stores its results.
+		Players []Player `xml:"users>user>games>game>leagues>league>players>player"`
+	}
+}
+
+// ParseXML actually does the transformation from xml to Player slice.
+func (p userLeagueXMLPlayerParser) parseXML(d *xml.Decoder) ([]Player, ResponseMeta, error) {
+	err := d.Decode(&p.result)
+	if err != nil {
+		return []Player{}, ResponseMeta{}, err
+	}
+	return p.result.Players, p.result.meta(), nil
+}
+
+// GameXMLPlayerParser parses xml with a structure fantasy_content>games>game>players>player.
+type gameXMLPlayerParser struct {
+	result struct {
+		// XMLName fantasy_content is the main wrapper tag in a Yahoo api response.
+		XMLName xml.Name `xml:"fantasy_content"`
+		responseAttrs
+		//Players is where a PlayerQueryBuilder stores its results.
+		Players []Player `xml:"games>game>players>player"`
+	}
+}
+
+// ParseXML actually does the transformation from xml to Player slice.
+func (p gameXMLPlayerParser) parseXML(d *xml.Decoder) ([]Player, ResponseMeta, error) {
+	err := d.Decode(&p.result)
+	if err != nil {
+		return []Player{}, ResponseMeta{}, err
+	}
+	return p.result.Players, p.result.meta(), nil
+}
+
+// UserGameXMLPlayerParser parses xml with a structure fantasy_content>users>user>games>game>players>player.
+type userGameXMLPlayerParser struct {
+	result struct {
+		// XMLName fantasy_content is the main wrapper tag in a Yahoo api response.
+		XMLName xml.Name `xml:"fantasy_content"`
+		responseAttrs
+		//Players is where a PlayerQueryBuilder stores its results.
+		Players []Player `xml:"users>user>games>game>players>player"`
+	}
+}
+
+// ParseXML actually does the transformation from xml to Player slice.
+func (p userGameXMLPlayerParser) parseXML(d *xml.Decoder) ([]Player, ResponseMeta, error) {
+	err := d.Decode(&p.result)
+	if err != nil {
+		return []Player{}, ResponseMeta{}, err
+	}
+	return p.result.Players, p.result.meta(), nil
+}
+
+// XmlParser returns the appropriate xml parser based on the query builder settings.
+func (q *PlayerQueryBuilder) xmlParser() xmlPlayerParser {
+	if q.LeagueQB != nil {
+		if q.LeagueQB.UserQB != nil {
+			return new(userLeagueXMLPlayerParser)
+		}
+		return new(leagueXMLPlayerParser)
+	}
+
+	if q.GameQB != nil {
+		if q.GameQB.UserQB != nil {
+			return new(userGameXMLPlayerParser)
+		}
+		return new(gameXMLPlayerParser)
+	}
+	return new(defaultXMLPlayerParser)
+}
+
+// Get sends a request to the appropriate url based on the query builder settings.
+// It then sends that response to a parser and returns the resulting Player slice.
+func (q *PlayerQueryBuilder) Get(client *http.Client) ([]Player, error) {
+	players, _, err := q.GetWithMeta(client)
+	return players, err
+}
+
+// Validate checks Keys are well formed player keys, along with the keys of nested query builders.
+func (q *PlayerQueryBuilder) Validate() error {
+	if q.LeagueQB != nil {
+		if err := q.LeagueQB.Validate(); err != nil {
+			return err
+		}
+	}
+	if q.GameQB != nil {
+		if err := q.GameQB.Validate(); err != nil {
+			return err
+		}
+	}
+	return validateKeys(q.Keys, validPlayerKey)
+}
+
+// GetWithMeta works like Get but also returns the metadata of the response.
+// When Keys holds more keys than Batching allows the players are requested in batches,
+// the metadata is then that of the first successful batch.
+func (q *PlayerQueryBuilder) GetWithMeta(client *http.Client) ([]Player, ResponseMeta, error) {
+	if err := q.Validate(); err != nil {
+		return []Player{}, ResponseMeta{}, err
+	}
+
+	if len(q.Keys) > q.Batching.maxKeys() {
+		return q.getBatched(client)
+	}
+
+	d, err := fetch(client, q.Url(), q.Format)
+	if err != nil {
+		return []Player{}, ResponseMeta{}, err
+	}
+
+	return q.xmlParser().parseXML(d)
+}
+
+// GetBatched requests the players in chunks of keys and merges the results in key order.
+func (q *PlayerQueryBuilder) getBatched(client *http.Client) ([]Player, ResponseMeta, error) {
+	chunks := chunkKeys(q.Keys, q.Batching.maxKeys())
+	results := make([][]Player, len(chunks))
+	metas := make([]ResponseMeta, len(chunks))
+
+	err := batch(chunks, q.Batching.workers(), func(i int, keys []string) error {
+		chunk := *q
+		chunk.Keys = keys
+
+		var err error
+		results[i], metas[i], err = chunk.GetWithMeta(client)
+		return err
+	})
+
+	players := []Player{}
+	for _, r := range results {
+		players = append(players, r...)
+	}
+
+	// yahoo does not return a chunk in the order of its keys.
+	newKeyOrder(q.Keys).sort(players, func(i int) []string { return []string{players[i].Key} })
+
+	return players, firstMeta(metas), err
+}
+
+// Iter returns an iterator which walks every player matching the query a page at a time.
+// Pages hold Count players, or PageSize players when Count is not set or larger, beginning at Start.
+// When prefetch is greater than zero up to prefetch pages are requested ahead of the caller.
+func (q *PlayerQueryBuilder) Iter(client *http.Client, prefetch int) *PlayerIterator {
+	fetch := func(start, count int) (interface{}, int, error) {
+		pq := *q
+		pq.Start, pq.Count = start, count
+
+		players, err := pq.Get(client)
+		return players, len(players), err
+	}
+
+	return &PlayerIterator{pager: newPager(q.Start, q.Count, prefetch, fetch)}
+}
+
+// PlayerIterator lazily requests the pages of a player query.
+//
+//	it := qb.Iter(client, 0)
+//	defer it.Close()
+//	for it.Next() {
+//		player := it.Player()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type PlayerIterator struct {
+	pager   *pager
+	players []Player
+	player  Player
+	err     error
+}
+
+// Next advances the iterator to the next player, requesting the next page when needed.
+// It returns false when there are no more players or a request failed.
+func (it *PlayerIterator) Next() bool {
+	for len(it.players) == 0 {
+		if it.err != nil {
+			return false
+		}
+
+		pg, ok := it.pager.next()
+		if !ok {
+			return false
+		}
+
+		if pg.err != nil {
+			it.err = pg.err
+			return false
+		}
+		it.players = pg.items.([]Player)
+	}
+
+	it.player, it.players = it.players[0], it.players[1:]
+	return true
+}
+
+// Player returns the current player.
+func (it *PlayerIterator) Player() Player {
+	return it.player
+}
+
+// Err returns the error which stopped the iterator, if any.
+func (it *PlayerIterator) Err() error {
+	return it.err
+}
+
+// Close releases the prefetching goroutine of an iterator which is abandoned before it is exhausted.
+func (it *PlayerIterator) Close() {
+	it.pager.close()
+}
+
+// Stream works like Get but calls fn with each player as soon as it is decoded instead of building a slice,
+// which keeps memory use flat for large responses. Streaming stops at the first error returned by fn.
+// When Keys holds more keys than Batching allows the batches are streamed one after another in the order of the chunks.
+func (q *PlayerQueryBuilder) Stream(client *http.Client, fn func(Player) error) error {
+	if err := q.Validate(); err != nil {
+		return err
+	}
+
+	if len(q.Keys) > q.Batching.maxKeys() {
+		for _, keys := range chunkKeys(q.Keys, q.Batching.maxKeys()) {
+			chunk := *q
+			chunk.Keys = keys
+			if err := chunk.Stream(client, fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return stream(client, q.Url(), q.Format, "player", func(d *xml.Decoder, start xml.StartElement) error {
+		var player Player
+		if err := d.DecodeElement(&player, &start); err != nil {
+			return err
+		}
+		return fn(player)
+	})
+}
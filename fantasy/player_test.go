@@ -0,0 +1,56 @@
+package fantasy
+
+import (
+	"testing"
+)
+
+func TestPlayerQueryBuilderURL(t *testing.T) {
+	var tests = []struct {
+		input PlayerQueryBuilder
+		want  string
+	}{
+		{
+			PlayerQueryBuilder{Keys: []string{"357.p.8967"}},
+			baseUrl + "players;player_keys=357.p.8967?format=xml",
+		},
+		{
+			PlayerQueryBuilder{LeagueQB: &LeagueQueryBuilder{Keys: []string{"357.l.86753"}}},
+			baseUrl + "leagues;league_keys=357.l.86753/players?format=xml",
+		},
+		{
+			PlayerQueryBuilder{GameQB: &GameQueryBuilder{Keys: []string{"mlb"}}, Keys: []string{"357.p.8967"}},
+			baseUrl + "games;game_keys=mlb/players;player_keys=357.p.8967?format=xml",
+		},
+	}
+
+	for _, test := range tests {
+		if got := test.input.Url(); got != test.want {
+			t.Errorf("Url = %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestGetLeaguePlayers(t *testing.T) {
+	q := PlayerQueryBuilder{LeagueQB: &LeagueQueryBuilder{Keys: []string{"357.l.86753"}}}
+	client := getXMLClient(q.Url(), "league-players.xml", t)
+
+	players, err := q.Get(client.Client)
+	if err != nil {
+		t.Fatalf("Unexpected PlayerQueryBuilder.Get error: %s", err)
+	}
+
+	if len(players) != 2 {
+		t.Fatalf("Unexpected Player len got %d, expected %d", len(players), 2)
+	}
+
+	player := players[0]
+	if player.Name.Full != "Buster Posey" {
+		t.Errorf("Player unmarshaled incorrectly. Name: %s, expected %s", player.Name.Full, "Buster Posey")
+	}
+	if len(player.EligiblePositions) != 3 || player.EligiblePositions[1] != "1B" {
+		t.Errorf("Player unmarshaled incorrectly. EligiblePositions: %v", player.EligiblePositions)
+	}
+	if players[1].Status != "DL" || !players[1].IsUndroppable {
+		t.Errorf("Player unmarshaled incorrectly. Status: %s, IsUndroppable %t", players[1].Status, players[1].IsUndroppable)
+	}
+}
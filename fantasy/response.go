@@ -0,0 +1,39 @@
+package fantasy
+
+import (
+	"time"
+)
+
+// ResponseMeta contains the metadata Yahoo attaches to the fantasy_content node of every response.
+type ResponseMeta struct {
+	// URI is the canonical api url Yahoo resolved the request to.
+	URI string
+	// Time is how long Yahoo spent serving the request.
+	Time time.Duration
+	// Copyright is the attribution which must be displayed alongside the data e.g. Data provided by Yahoo! and STATS, LLC
+	Copyright string
+	// RefreshRate is the number of seconds Yahoo suggests waiting before requesting the resource again.
+	RefreshRate int64
+}
+
+// ResponseAttrs unmarshals the raw attributes of the fantasy_content node.
+// It is embedded in each parser result so the attributes are read alongside the content.
+type responseAttrs struct {
+	URI         string `xml:"http://www.yahooapis.com/v1/base.rng uri,attr"`
+	Time        string `xml:"time,attr"`
+	Copyright   string `xml:"copyright,attr"`
+	RefreshRate int64  `xml:"refresh_rate,attr"`
+}
+
+// Meta converts the raw attributes into a ResponseMeta.
+// An unparseable time attribute is left as a zero duration rather than failing the whole response.
+func (a responseAttrs) meta() ResponseMeta {
+	d, _ := time.ParseDuration(a.Time)
+
+	return ResponseMeta{
+		URI:         a.URI,
+		Time:        d,
+		Copyright:   a.Copyright,
+		RefreshRate: a.RefreshRate,
+	}
+}
@@ -0,0 +1,83 @@
+package fantasy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetWithMeta(t *testing.T) {
+	q := LeagueQueryBuilder{Keys: []string{"357.l.86753"}}
+	client := getXMLClient(q.Url(), "single-league-meta.xml", t)
+
+	leagues, meta, err := q.GetWithMeta(client.Client)
+	if err != nil {
+		t.Fatalf("Unexpected LeagueQueryBuilder.GetWithMeta error: %s", err)
+	}
+
+	if len(leagues) != 1 {
+		t.Errorf("Unexpected League len got %d, expected %d", len(leagues), 1)
+	}
+
+	uri := "http://fantasysports.yahooapis.com/fantasy/v2/leagues;league_keys=357.l.86753"
+	if meta.URI != uri {
+		t.Errorf("Incorrect ResponseMeta URI got %s, expected %s", meta.URI, uri)
+	}
+
+	d := time.Duration(39206981)
+	if meta.Time != d {
+		t.Errorf("Incorrect ResponseMeta Time got %s, expected %s", meta.Time, d)
+	}
+
+	copyright := "Data provided by Yahoo! and STATS, LLC"
+	if meta.Copyright != copyright {
+		t.Errorf("Incorrect ResponseMeta Copyright got %s, expected %s", meta.Copyright, copyright)
+	}
+
+	if meta.RefreshRate != 31 {
+		t.Errorf("Incorrect ResponseMeta RefreshRate got %d, expected %d", meta.RefreshRate, 31)
+	}
+}
+
+func TestUserGetWithMeta(t *testing.T) {
+	q := UserQueryBuilder{ActiveUser: true}
+	client := getXMLClient(q.Url(), "active-user.xml", t)
+
+	_, meta, err := q.GetWithMeta(client.Client)
+	if err != nil {
+		t.Fatalf("Unexpected UserQueryBuilder.GetWithMeta error: %s", err)
+	}
+
+	if meta.Time != time.Duration(23272991) {
+		t.Errorf("Incorrect ResponseMeta Time got %s", meta.Time)
+	}
+
+	// a failed request has no metadata
+	q = UserQueryBuilder{}
+	_, meta, err = q.GetWithMeta(client.Client)
+	if err == nil {
+		t.Error("Expected UserQueryBuilder.GetWithMeta to return an error")
+	}
+
+	if meta != (ResponseMeta{}) {
+		t.Errorf("Expected empty ResponseMeta got %v", meta)
+	}
+}
+
+func TestGetWithMetaJSON(t *testing.T) {
+	q := LeagueQueryBuilder{Keys: []string{"357.l.86753"}, Format: FormatJSON}
+	client := getXMLClient(q.Url(), "single-league-meta.json", t)
+
+	_, meta, err := q.GetWithMeta(client.Client)
+	if err != nil {
+		t.Fatalf("Unexpected LeagueQueryBuilder.GetWithMeta error: %s", err)
+	}
+
+	uri := "/fantasy/v2/leagues;league_keys=357.l.86753"
+	if meta.URI != uri {
+		t.Errorf("Incorrect ResponseMeta URI got %s, expected %s", meta.URI, uri)
+	}
+
+	if meta.RefreshRate != 31 {
+		t.Errorf("Incorrect ResponseMeta RefreshRate got %d, expected %d", meta.RefreshRate, 31)
+	}
+}
@@ -0,0 +1,48 @@
+package fantasy
+
+// Settings are the rules of a League, requested by setting LeagueQueryBuilder.Settings.
+type Settings struct {
+	DraftType      DraftType `xml:"draft_type"`
+	IsAuctionDraft Bool      `xml:"is_auction_draft"`
+	// DraftTime is when the draft starts.
+	DraftTime Timestamp `xml:"draft_time"`
+	// DraftPickTime is the number of seconds each manager has to make a pick.
+	DraftPickTime    Int              `xml:"draft_pick_time"`
+	PostDraftPlayers PostDraftPlayers `xml:"post_draft_players"`
+	ScoringType      ScoringType      `xml:"scoring_type"`
+	UsesPlayoff      Bool             `xml:"uses_playoff"`
+	// PlayoffStartWeek is the first week of the playoffs, empty without playoffs.
+	PlayoffStartWeek Int        `xml:"playoff_start_week"`
+	MaxTeams         Int        `xml:"max_teams"`
+	WaiverType       WaiverType `xml:"waiver_type"`
+	// WaiverRule is which players go through waivers e.g. all, gametime or none.
+	WaiverRule string `xml:"waiver_rule"`
+	// WaiverTime is the number of days a dropped player stays on waivers.
+	WaiverTime Int  `xml:"waiver_time"`
+	UsesFAAB   Bool `xml:"uses_faab"`
+	// TradeEndDate is the last day trades may be made.
+	TradeEndDate    Date            `xml:"trade_end_date"`
+	TradeRatifyType TradeRatifyType `xml:"trade_ratify_type"`
+	// TradeRejectTime is the number of days a trade may be vetoed.
+	TradeRejectTime Int `xml:"trade_reject_time"`
+	// PlayerPool is which players may be rostered e.g. ALL
+	PlayerPool string `xml:"player_pool"`
+	// CantCutList is whose list of players who may not be dropped is used e.g. yahoo or none.
+	CantCutList     string                 `xml:"cant_cut_list"`
+	RosterPositions []LeagueRosterPosition `xml:"roster_positions>roster_position"`
+	// StatCategories are the stats the league scores, merge them into a StatCatalog to look up stat values.
+	StatCategories []Stat `xml:"stat_categories>stats>stat"`
+	// SeasonType is the part of the season the league plays e.g. full
+	SeasonType        string `xml:"season_type"`
+	MaxGamesPlayed    Int    `xml:"max_games_played"`
+	MaxInningsPitched Int    `xml:"max_innings_pitched"`
+}
+
+// LeagueRosterPosition is a position of a League's rosters and the number of players it holds.
+type LeagueRosterPosition struct {
+	// Position is the roster position e.g. OF, BN or DL.
+	Position string `xml:"position"`
+	// PositionType is the kind of player the position holds e.g. B for batters or P for pitchers, empty for bench positions.
+	PositionType string `xml:"position_type"`
+	Count        int64  `xml:"count"`
+}
@@ -0,0 +1,47 @@
+package fantasy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLeagueSettings(t *testing.T) {
+	q := LeagueQueryBuilder{Keys: []string{"357.l.86753"}, Settings: true}
+	client := getXMLClient(q.Url(), "single-league-settings.xml", t)
+
+	leagues, err := q.Get(client.Client)
+	if err != nil {
+		t.Fatalf("Unexpected QueryBuilder.Get error: %s", err)
+	}
+	if len(leagues) != 1 || leagues[0].Settings == nil {
+		t.Fatalf("Expected one league with settings, got %+v", leagues)
+	}
+
+	league := leagues[0]
+	if league.DraftStatus != DraftPreDraft || league.ScoringType != ScoringRoto || league.LeagueType != LeaguePrivate {
+		t.Errorf("League unmarshaled incorrectly. DraftStatus: %s, ScoringType: %s, LeagueType: %s",
+			league.DraftStatus, league.ScoringType, league.LeagueType)
+	}
+
+	s := league.Settings
+	if s.DraftType != DraftLive || s.WaiverType != WaiverRolling || s.PostDraftPlayers != PostDraftWaivers || s.TradeRatifyType != TradeRatifyCommish {
+		t.Errorf("Settings enums unmarshaled incorrectly: %+v", s)
+	}
+	if s.DraftTime.Time() != time.Unix(1458775800, 0).UTC() || s.TradeEndDate.String() != "2016-08-14" {
+		t.Errorf("Settings times unmarshaled incorrectly. DraftTime: %s, TradeEndDate: %s", s.DraftTime, s.TradeEndDate)
+	}
+	if s.DraftPickTime != NewInt(90) || s.MaxTeams != NewInt(15) || s.PlayoffStartWeek.Valid || bool(s.UsesFAAB) {
+		t.Errorf("Settings numbers unmarshaled incorrectly: %+v", s)
+	}
+	if s.CantCutList != "yahoo" || s.SeasonType != "full" || s.MaxInningsPitched != NewInt(1475) {
+		t.Errorf("Settings unmarshaled incorrectly: %+v", s)
+	}
+
+	if len(s.RosterPositions) != 13 {
+		t.Fatalf("Expected 13 roster positions, got %d", len(s.RosterPositions))
+	}
+	of := s.RosterPositions[5]
+	if of.Position != "OF" || of.PositionType != "B" || of.Count != 3 {
+		t.Errorf("Roster position unmarshaled incorrectly: %+v", of)
+	}
+}
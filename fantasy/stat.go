@@ -0,0 +1,208 @@
+package fantasy
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Stat describes a stat category of a game, stat values only carry its ID.
+type Stat struct {
+	// ID is the stat_id Yahoo uses in stat values.
+	ID int64 `xml:"stat_id"`
+	// Name is the full name e.g. Earned Run Average.
+	Name string `xml:"name"`
+	// DisplayName is the abbreviation e.g. ERA.
+	DisplayName string `xml:"display_name"`
+	// SortOrder is 1 when a higher value is better and 0 when a lower value is better.
+	SortOrder int64 `xml:"sort_order"`
+	// PositionType is the kind of player the stat is for e.g. B for batters, P for pitchers.
+	PositionType string `xml:"position_type"`
+	// PositionTypes lists every position type of a stat shared by several, as sent by the game resource.
+	PositionTypes []string `xml:"position_types>position_type"`
+	// Enabled is whether a league scores the stat, it is only set in league settings.
+	Enabled Bool `xml:"enabled"`
+	// IsOnlyDisplayStat is true for stats which are shown but not scored e.g. H/AB.
+	IsOnlyDisplayStat Bool `xml:"is_only_display_stat"`
+	// Ratio holds the components of a ratio stat e.g. ERA, nil for counting stats.
+	// Yahoo does not send components, they come from the built-in catalogs.
+	// Ratios which are not a single quotient of catalog stats e.g. OPS have no components.
+	Ratio *StatRatio `xml:"-"`
+}
+
+// HigherIsBetter reports whether a higher value of the stat is better.
+func (s Stat) HigherIsBetter() bool {
+	return s.SortOrder == 1
+}
+
+// IsRatio reports whether the stat is a ratio of other stats.
+func (s Stat) IsRatio() bool {
+	return s.Ratio != nil
+}
+
+// StatRatio is a ratio stat computed as Multiplier * sum(Numerator) / sum(Denominator),
+// e.g. ERA is 9 * ER / IP and FG% is FGM / FGA.
+// Ratios must be recomputed from their components when combining values, never averaged or summed.
+// A ratio without components such as OPS, which is OBP + SLG, can not be recomputed.
+type StatRatio struct {
+	// Numerator are the ids of the stats summed above the line.
+	Numerator []int64
+	// Denominator are the ids of the stats summed below the line.
+	Denominator []int64
+	// Multiplier scales the ratio, zero is treated as 1.
+	Multiplier float64
+}
+
+// Compute calculates the ratio from stat values keyed by stat id,
+// ok is false when the denominator is zero or the ratio has no components.
+// Innings pitched must be real innings, see ParseInnings.
+func (r StatRatio) Compute(values map[int64]float64) (v float64, ok bool) {
+	var num, den float64
+	for _, id := range r.Numerator {
+		num += values[id]
+	}
+	for _, id := range r.Denominator {
+		den += values[id]
+	}
+	if den == 0 {
+		return 0, false
+	}
+
+	m := r.Multiplier
+	if m == 0 {
+		m = 1
+	}
+	return m * num / den, true
+}
+
+// ParseInnings converts Yahoo's innings pitched notation, where 6.2 is six and two thirds innings, into innings.
+func ParseInnings(s string) (float64, error) {
+	parts := strings.SplitN(strings.TrimSpace(s), ".", 2)
+	whole, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Bad innings pitched %q", s)
+	}
+	if len(parts) == 1 {
+		return float64(whole), nil
+	}
+
+	outs, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || outs < 0 || outs > 2 {
+		return 0, fmt.Errorf("Bad innings pitched %q", s)
+	}
+	return float64(whole) + float64(outs)/3, nil
+}
+
+// StatCatalog maps the stat ids of a game to their Stat.
+// It is safe for concurrent use.
+type StatCatalog struct {
+	// GameCode is the code of the game the stats belong to e.g. mlb.
+	GameCode string
+
+	mu    sync.RWMutex
+	stats map[int64]Stat
+}
+
+// NewStatCatalog creates a catalog of stats for the game with code.
+func NewStatCatalog(gameCode string, stats []Stat) *StatCatalog {
+	c := &StatCatalog{GameCode: gameCode, stats: map[int64]Stat{}}
+	for _, s := range stats {
+		c.stats[s.ID] = s
+	}
+	return c
+}
+
+// Catalog returns a copy of the built-in catalog of a game code (mlb, nfl, nba or nhl), nil for other games.
+// Refreshing or merging into the copy does not change the catalogs returned to other callers.
+func Catalog(gameCode string) *StatCatalog {
+	c, ok := catalogs[gameCode]
+	if !ok {
+		return nil
+	}
+	return NewStatCatalog(c.GameCode, c.Stats())
+}
+
+// Stat returns the stat with id.
+func (c *StatCatalog) Stat(id int64) (Stat, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	s, ok := c.stats[id]
+	return s, ok
+}
+
+// ByDisplayName returns the stat abbreviated name for players of positionType,
+// abbreviations are not unique across position types e.g. H is hits for batters and hits allowed for pitchers.
+func (c *StatCatalog) ByDisplayName(name, positionType string) (Stat, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, s := range c.stats {
+		if s.DisplayName == name && s.PositionType == positionType {
+			return s, true
+		}
+	}
+	return Stat{}, false
+}
+
+// Stats returns every stat ordered by id.
+func (c *StatCatalog) Stats() []Stat {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	stats := make([]Stat, 0, len(c.stats))
+	for _, s := range c.stats {
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].ID < stats[j].ID })
+	return stats
+}
+
+// Merge adds or replaces stats e.g. the stat categories of league settings.
+// Ratio components of known stats are kept, as Yahoo does not send them.
+func (c *StatCatalog) Merge(stats []Stat) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, s := range stats {
+		if s.PositionType == "" && len(s.PositionTypes) > 0 {
+			s.PositionType = s.PositionTypes[0]
+		}
+		if old, ok := c.stats[s.ID]; ok && s.Ratio == nil {
+			s.Ratio = old.Ratio
+		}
+		c.stats[s.ID] = s
+	}
+}
+
+// Refresh merges the stat categories of the game with gameKey into the catalog,
+// picking up stats added since the built-in catalogs were written.
+func (c *StatCatalog) Refresh(client *http.Client, gameKey string) error {
+	stats, err := GetStatCategories(client, gameKey)
+	if err != nil {
+		return err
+	}
+	c.Merge(stats)
+	return nil
+}
+
+// GetStatCategories requests every stat category of the game with gameKey e.g. mlb or 357.
+func GetStatCategories(client *http.Client, gameKey string) ([]Stat, error) {
+	if err := validGameKey(gameKey); err != nil {
+		return nil, err
+	}
+
+	d, err := fetch(client, baseUrl+"game/"+gameKey+"/stat_categories?format=xml", FormatXML)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		XMLName xml.Name `xml:"fantasy_content"`
+		Stats   []Stat   `xml:"game>stat_categories>stats>stat"`
+	}
+	if err := d.Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.Stats, nil
+}
@@ -0,0 +1,175 @@
+package fantasy
+
+// Built-in stat catalogs of the fantasy games. They cover the commonly scored stats of each sport,
+// StatCatalog.Refresh fills in any others from the game's stat_categories.
+
+var catalogs = map[string]*StatCatalog{
+	"mlb": NewStatCatalog("mlb", mlbStats),
+	"nfl": NewStatCatalog("nfl", nflStats),
+	"nba": NewStatCatalog("nba", nbaStats),
+	"nhl": NewStatCatalog("nhl", nhlStats),
+}
+
+// Baseball position types are B for batters and P for pitchers.
+var mlbStats = []Stat{
+	stat(0, "Games Played", "GP", "B", 1),
+	ratioStat(3, "Batting Average", "AVG", "B", 1, &StatRatio{Numerator: []int64{8}, Denominator: []int64{6}}),
+	ratioStat(4, "On-base Percentage", "OBP", "B", 1, &StatRatio{Numerator: []int64{8, 18, 20}, Denominator: []int64{6, 18, 20, 15}}),
+	ratioStat(5, "Slugging Percentage", "SLG", "B", 1, &StatRatio{Numerator: []int64{23}, Denominator: []int64{6}}),
+	stat(6, "At Bats", "AB", "B", 1),
+	stat(7, "Runs", "R", "B", 1),
+	stat(8, "Hits", "H", "B", 1),
+	stat(9, "Singles", "1B", "B", 1),
+	stat(10, "Doubles", "2B", "B", 1),
+	stat(11, "Triples", "3B", "B", 1),
+	stat(12, "Home Runs", "HR", "B", 1),
+	stat(13, "Runs Batted In", "RBI", "B", 1),
+	stat(14, "Sacrifice Hits", "SH", "B", 1),
+	stat(15, "Sacrifice Flys", "SF", "B", 1),
+	stat(16, "Stolen Bases", "SB", "B", 1),
+	stat(17, "Caught Stealing", "CS", "B", 0),
+	stat(18, "Walks", "BB", "B", 1),
+	stat(19, "Intentional Walks", "IBB", "B", 1),
+	stat(20, "Hit By Pitch", "HBP", "B", 1),
+	stat(21, "Strikeouts", "K", "B", 0),
+	stat(22, "Ground Into Double Play", "GIDP", "B", 0),
+	stat(23, "Total Bases", "TB", "B", 1),
+	ratioStat(26, "Earned Run Average", "ERA", "P", 0, &StatRatio{Numerator: []int64{37}, Denominator: []int64{50}, Multiplier: 9}),
+	ratioStat(27, "(Walks + Hits)/ Innings Pitched", "WHIP", "P", 0, &StatRatio{Numerator: []int64{39, 34}, Denominator: []int64{50}}),
+	stat(28, "Wins", "W", "P", 1),
+	stat(29, "Losses", "L", "P", 0),
+	stat(32, "Saves", "SV", "P", 1),
+	stat(33, "Outs", "OUT", "P", 1),
+	stat(34, "Hits", "H", "P", 0),
+	stat(35, "Total Batters Faced", "TBF", "P", 0),
+	stat(36, "Runs", "R", "P", 0),
+	stat(37, "Earned Runs", "ER", "P", 0),
+	stat(38, "Home Runs", "HR", "P", 0),
+	stat(39, "Walks", "BB", "P", 0),
+	stat(40, "Intentional Walks", "IBB", "P", 0),
+	stat(41, "Hit Batters", "HBP", "P", 0),
+	stat(42, "Strikeouts", "K", "P", 1),
+	stat(46, "Batters Grounded Into Double Plays", "GIDP", "P", 1),
+	stat(48, "Holds", "HLD", "P", 1),
+	stat(49, "Total Bases Allowed", "TB", "P", 0),
+	stat(50, "Innings Pitched", "IP", "P", 1),
+	ratioStat(54, "Fielding Percentage", "FPCT", "B", 1, &StatRatio{}),
+	ratioStat(55, "On-base + Slugging Percentage", "OPS", "B", 1, &StatRatio{}),
+	stat(60, "H/AB", "H/AB", "B", 1),
+	stat(82, "Inherited Runners Scored", "IRA", "P", 0),
+	stat(83, "Quality Starts", "QS", "P", 1),
+	stat(86, "Outfield Assists", "OFA", "B", 1),
+}
+
+// Football position types are O for offense, K for kickers and DT for team defense.
+var nflStats = []Stat{
+	stat(1, "Passing Attempts", "Pass Att", "O", 1),
+	stat(2, "Completions", "Comp", "O", 1),
+	stat(3, "Incomplete Passes", "Inc", "O", 0),
+	stat(4, "Passing Yards", "Pass Yds", "O", 1),
+	stat(5, "Passing Touchdowns", "Pass TD", "O", 1),
+	stat(6, "Interceptions", "Int", "O", 0),
+	stat(7, "Sacks", "Sack", "O", 0),
+	stat(8, "Rushing Attempts", "Rush Att", "O", 1),
+	stat(9, "Rushing Yards", "Rush Yds", "O", 1),
+	stat(10, "Rushing Touchdowns", "Rush TD", "O", 1),
+	stat(11, "Receptions", "Rec", "O", 1),
+	stat(12, "Receiving Yards", "Rec Yds", "O", 1),
+	stat(13, "Receiving Touchdowns", "Rec TD", "O", 1),
+	stat(14, "Return Yards", "Ret Yds", "O", 1),
+	stat(15, "Return Touchdowns", "Ret TD", "O", 1),
+	stat(16, "2-Point Conversions", "2-PT", "O", 1),
+	stat(17, "Fumbles", "Fum", "O", 0),
+	stat(18, "Fumbles Lost", "Fum Lost", "O", 0),
+	stat(19, "Field Goals 0-19 Yards", "FG 0-19", "K", 1),
+	stat(20, "Field Goals 20-29 Yards", "FG 20-29", "K", 1),
+	stat(21, "Field Goals 30-39 Yards", "FG 30-39", "K", 1),
+	stat(22, "Field Goals 40-49 Yards", "FG 40-49", "K", 1),
+	stat(23, "Field Goals 50+ Yards", "FG 50+", "K", 1),
+	stat(29, "Point After Attempt Made", "PAT Made", "K", 1),
+	stat(30, "Point After Attempt Missed", "PAT Miss", "K", 0),
+	stat(31, "Points Allowed", "Pts Allow", "DT", 0),
+	stat(32, "Sack", "Sack", "DT", 1),
+	stat(33, "Interception", "Int", "DT", 1),
+	stat(34, "Fumble Recovery", "Fum Rec", "DT", 1),
+	stat(35, "Touchdown", "TD", "DT", 1),
+	stat(36, "Safety", "Safe", "DT", 1),
+	stat(37, "Block Kick", "Blk Kick", "DT", 1),
+	stat(57, "Offensive Fumble Return TD", "Fum Ret TD", "O", 1),
+	stat(78, "Targets", "Tgt", "O", 1),
+}
+
+// Basketball has the single position type P.
+var nbaStats = []Stat{
+	stat(0, "Games Played", "GP", "P", 1),
+	stat(1, "Games Started", "GS", "P", 1),
+	stat(2, "Minutes Played", "MIN", "P", 1),
+	stat(3, "Field Goals Attempted", "FGA", "P", 1),
+	stat(4, "Field Goals Made", "FGM", "P", 1),
+	ratioStat(5, "Field Goal Percentage", "FG%", "P", 1, &StatRatio{Numerator: []int64{4}, Denominator: []int64{3}}),
+	stat(6, "Free Throws Attempted", "FTA", "P", 1),
+	stat(7, "Free Throws Made", "FTM", "P", 1),
+	ratioStat(8, "Free Throw Percentage", "FT%", "P", 1, &StatRatio{Numerator: []int64{7}, Denominator: []int64{6}}),
+	stat(9, "3-point Shots Attempted", "3PTA", "P", 1),
+	stat(10, "3-point Shots Made", "3PTM", "P", 1),
+	ratioStat(11, "3-point Percentage", "3PT%", "P", 1, &StatRatio{Numerator: []int64{10}, Denominator: []int64{9}}),
+	stat(12, "Points Scored", "PTS", "P", 1),
+	stat(13, "Offensive Rebounds", "OREB", "P", 1),
+	stat(14, "Defensive Rebounds", "DREB", "P", 1),
+	stat(15, "Total Rebounds", "REB", "P", 1),
+	stat(16, "Assists", "AST", "P", 1),
+	stat(17, "Steals", "ST", "P", 1),
+	stat(18, "Blocked Shots", "BLK", "P", 1),
+	stat(19, "Turnovers", "TO", "P", 0),
+	ratioStat(20, "Assist/Turnover Ratio", "A/T", "P", 1, &StatRatio{Numerator: []int64{16}, Denominator: []int64{19}}),
+	stat(21, "Personal Fouls", "PF", "P", 0),
+	stat(27, "Double-Doubles", "DD", "P", 1),
+	stat(28, "Triple-Doubles", "TD", "P", 1),
+	stat(9004003, "Field Goals Made / Field Goals Attempted", "FGM/A", "P", 1),
+	stat(9007006, "Free Throws Made / Free Throws Attempted", "FTM/A", "P", 1),
+}
+
+// Hockey position types are P for skaters and G for goalies.
+var nhlStats = []Stat{
+	stat(1, "Goals", "G", "P", 1),
+	stat(2, "Assists", "A", "P", 1),
+	stat(3, "Points", "P", "P", 1),
+	stat(4, "Plus/Minus", "+/-", "P", 1),
+	stat(5, "Penalty Minutes", "PIM", "P", 1),
+	stat(6, "Powerplay Goals", "PPG", "P", 1),
+	stat(7, "Powerplay Assists", "PPA", "P", 1),
+	stat(8, "Powerplay Points", "PPP", "P", 1),
+	stat(9, "Shorthanded Goals", "SHG", "P", 1),
+	stat(10, "Shorthanded Assists", "SHA", "P", 1),
+	stat(11, "Shorthanded Points", "SHP", "P", 1),
+	stat(12, "Game-Winning Goals", "GWG", "P", 1),
+	stat(13, "Game-Tying Goals", "GTG", "P", 1),
+	stat(14, "Shots on Goal", "SOG", "P", 1),
+	ratioStat(15, "Shooting Percentage", "SH%", "P", 1, &StatRatio{Numerator: []int64{1}, Denominator: []int64{14}}),
+	stat(16, "Faceoffs Won", "FW", "P", 1),
+	stat(17, "Faceoffs Lost", "FL", "P", 0),
+	stat(18, "Games Started", "GS", "G", 1),
+	stat(19, "Wins", "W", "G", 1),
+	stat(20, "Losses", "L", "G", 0),
+	stat(22, "Goals Against", "GA", "G", 0),
+	ratioStat(23, "Goals Against Average", "GAA", "G", 0, &StatRatio{Numerator: []int64{22}, Denominator: []int64{28}, Multiplier: 60}),
+	stat(24, "Shots Against", "SA", "G", 1),
+	stat(25, "Saves", "SV", "G", 1),
+	ratioStat(26, "Save Percentage", "SV%", "G", 1, &StatRatio{Numerator: []int64{25}, Denominator: []int64{24}}),
+	stat(27, "Shutouts", "SHO", "G", 1),
+	stat(28, "Time on Ice", "MIN", "G", 1),
+	stat(31, "Hits", "HIT", "P", 1),
+	stat(32, "Blocks", "BLK", "P", 1),
+}
+
+// Stat creates a counting stat, sortOrder is 1 when higher is better.
+func stat(id int64, name, displayName, positionType string, sortOrder int64) Stat {
+	return Stat{ID: id, Name: name, DisplayName: displayName, PositionType: positionType, SortOrder: sortOrder}
+}
+
+// RatioStat creates a stat computed from other stats, an empty ratio marks a ratio whose components are not in the catalog.
+func ratioStat(id int64, name, displayName, positionType string, sortOrder int64, ratio *StatRatio) Stat {
+	s := stat(id, name, displayName, positionType, sortOrder)
+	s.Ratio = ratio
+	return s
+}
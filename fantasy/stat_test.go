@@ -0,0 +1,150 @@
+package fantasy
+
+import (
+	"math"
+	"testing"
+
+	"github.com/muswell/gotest"
+)
+
+func TestCatalogs(t *testing.T) {
+	for _, code := range []string{"mlb", "nfl", "nba", "nhl"} {
+		c := Catalog(code)
+		if c == nil || c.GameCode != code || len(c.Stats()) == 0 {
+			t.Errorf("Missing built-in %s catalog", code)
+			continue
+		}
+
+		// every ratio component must be in the catalog.
+		for _, s := range c.Stats() {
+			if s.Ratio == nil {
+				continue
+			}
+			for _, id := range append(append([]int64{}, s.Ratio.Numerator...), s.Ratio.Denominator...) {
+				if _, ok := c.Stat(id); !ok {
+					t.Errorf("%s stat %s has unknown component %d", code, s.DisplayName, id)
+				}
+			}
+		}
+	}
+
+	if Catalog("pga") != nil {
+		t.Error("Expected no catalog for pga")
+	}
+
+	fg, ok := Catalog("nba").Stat(5)
+	if !ok || fg.DisplayName != "FG%" || !fg.IsRatio() || !fg.HigherIsBetter() {
+		t.Errorf("nba stat 5 = %+v, expected a ratio FG%%", fg)
+	}
+
+	// OPS and FPCT are ratios even though they can not be recomputed from catalog stats.
+	for _, id := range []int64{54, 55} {
+		s, _ := Catalog("mlb").Stat(id)
+		if !s.IsRatio() {
+			t.Errorf("mlb stat %d %s is not a ratio", id, s.DisplayName)
+		}
+		if _, ok := s.Ratio.Compute(map[int64]float64{4: 0.350, 5: 0.500}); ok {
+			t.Errorf("Expected mlb stat %d %s to not be computed", id, s.DisplayName)
+		}
+	}
+
+	h, ok := Catalog("mlb").ByDisplayName("H", "P")
+	if !ok || h.ID != 34 || h.HigherIsBetter() {
+		t.Errorf("mlb pitcher H = %+v, expected stat 34", h)
+	}
+}
+
+func TestStatRatioCompute(t *testing.T) {
+	ip, err := ParseInnings("30.2")
+	if err != nil {
+		t.Fatalf("Unexpected ParseInnings error: %v", err)
+	}
+
+	era, _ := Catalog("mlb").Stat(26)
+	v, ok := era.Ratio.Compute(map[int64]float64{37: 10, 50: ip})
+	if !ok || math.Abs(v-90/(30+2.0/3)) > 1e-9 {
+		t.Errorf("ERA = %f, %t", v, ok)
+	}
+
+	whip, _ := Catalog("mlb").Stat(27)
+	if v, ok := whip.Ratio.Compute(map[int64]float64{34: 20, 39: 10, 50: 30}); !ok || v != 1 {
+		t.Errorf("WHIP = %f, %t, expected 1", v, ok)
+	}
+	if _, ok := whip.Ratio.Compute(map[int64]float64{34: 20}); ok {
+		t.Error("Expected WHIP without innings to not be computed")
+	}
+
+	for _, s := range []string{"6.3", "x", "6.-1"} {
+		if _, err := ParseInnings(s); err == nil {
+			t.Errorf("Expected a ParseInnings(%s) error", s)
+		}
+	}
+}
+
+func TestStatCatalogMergeSettings(t *testing.T) {
+	q := LeagueQueryBuilder{Keys: []string{"357.l.86753"}, Settings: true}
+	client := getXMLClient(q.Url(), "single-league-settings.xml", t)
+
+	leagues, err := q.Get(client.Client)
+	if err != nil {
+		t.Fatalf("Unexpected QueryBuilder.Get error: %s", err)
+	}
+
+	stats := leagues[0].Settings.StatCategories
+	if len(stats) != 20 {
+		t.Fatalf("Expected 20 stat categories, got %d", len(stats))
+	}
+
+	c := NewStatCatalog("mlb", Catalog("mlb").Stats())
+	c.Merge(stats)
+
+	era, _ := c.Stat(26)
+	if !era.IsRatio() || !bool(era.Enabled) || era.Name != "Earned Run Average" {
+		t.Errorf("Merged ERA = %+v, expected an enabled ratio", era)
+	}
+	if hab, _ := c.Stat(60); !bool(hab.IsOnlyDisplayStat) {
+		t.Errorf("Merged H/AB = %+v, expected a display only stat", hab)
+	}
+}
+
+func TestStatCatalogRefresh(t *testing.T) {
+	client := gotest.NewRegisteredClient()
+	client.Register(baseUrl+"game/mlb/stat_categories?format=xml", "get", gotest.NewSimpleRoundTrip([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<fantasy_content xmlns:yahoo="http://www.yahooapis.com/v1/base.rng" xmlns="http://fantasysports.yahooapis.com/fantasy/v2/base.rng">
+  <game><game_key>357</game_key><code>mlb</code>
+    <stat_categories><stats>
+      <stat><stat_id>3</stat_id><name>Batting Average</name><display_name>AVG</display_name><sort_order>1</sort_order><position_types><position_type>B</position_type></position_types></stat>
+      <stat><stat_id>999</stat_id><name>Immaculate Innings</name><display_name>II</display_name><sort_order>1</sort_order><position_types><position_type>P</position_type></position_types></stat>
+    </stats></stat_categories>
+  </game>
+</fantasy_content>`), nil))
+
+	c := NewStatCatalog("mlb", Catalog("mlb").Stats())
+	if err := c.Refresh(client.Client, "mlb"); err != nil {
+		t.Fatalf("Unexpected Refresh error: %v", err)
+	}
+
+	ii, ok := c.Stat(999)
+	if !ok || ii.DisplayName != "II" || ii.PositionType != "P" {
+		t.Errorf("Refreshed stat 999 = %+v", ii)
+	}
+	if avg, _ := c.Stat(3); !avg.IsRatio() {
+		t.Error("Refresh dropped the AVG ratio components")
+	}
+	if _, ok := Catalog("mlb").Stat(999); ok {
+		t.Error("Refreshing a copy changed the built-in catalog")
+	}
+
+	// the built-in catalogs are copied so refreshing one does not leak into other callers.
+	shared := Catalog("mlb")
+	if err := shared.Refresh(client.Client, "mlb"); err != nil {
+		t.Fatalf("Unexpected Refresh error: %v", err)
+	}
+	if _, ok := Catalog("mlb").Stat(999); ok {
+		t.Error("Refreshing the catalog returned by Catalog changed it for every caller")
+	}
+
+	if err := c.Refresh(client.Client, "m.l.b"); err == nil {
+		t.Error("Expected a Refresh error for a malformed game key")
+	}
+}
@@ -0,0 +1,71 @@
+package fantasy
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// Stream requests url and calls fn for every element named local as soon as its start tag is read.
+// Fn must consume the element, normally with DecodeElement.
+// Xml responses are decoded straight from the response body so only a single element is held in memory,
+// json responses have to be read in full before they can be translated.
+func stream(client *http.Client, url string, f Format, local string, fn func(*xml.Decoder, xml.StartElement) error) error {
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var d *xml.Decoder
+	if f == FormatJSON {
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+
+		d, err = newDecoder(data, f)
+		if err != nil {
+			return err
+		}
+	} else {
+		d = xml.NewDecoder(resp.Body)
+	}
+
+	root := false
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		start, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		if !root {
+			if start.Name.Local != "fantasy_content" {
+				return fmt.Errorf("Unexpected root element %s, expected fantasy_content", start.Name.Local)
+			}
+			root = true
+			continue
+		}
+
+		if start.Name.Local == local {
+			if err := fn(d, start); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !root {
+		return fmt.Errorf("The response did not contain a fantasy_content element")
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package fantasy
+
+import (
+	"errors"
+	"github.com/muswell/gotest"
+	"testing"
+)
+
+func TestStreamPlayers(t *testing.T) {
+	q := PlayerQueryBuilder{LeagueQB: &LeagueQueryBuilder{Keys: []string{"357.l.86753"}}}
+	client := getXMLClient(q.Url(), "league-players.xml", t)
+
+	var names []string
+	err := q.Stream(client.Client, func(p Player) error {
+		names = append(names, p.Name.Full)
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected PlayerQueryBuilder.Stream error: %s", err)
+	}
+
+	if len(names) != 2 || names[0] != "Buster Posey" || names[1] != "Madison Bumgarner" {
+		t.Errorf("PlayerQueryBuilder.Stream returned incorrect players %v", names)
+	}
+
+	// an error returned by the callback stops the stream.
+	stop := errors.New("stop")
+	n := 0
+	err = q.Stream(client.Client, func(p Player) error {
+		n++
+		return stop
+	})
+
+	if err != stop || n != 1 {
+		t.Errorf("Expected PlayerQueryBuilder.Stream to stop after the first player, got %d players and error %v", n, err)
+	}
+}
+
+func TestStreamTeams(t *testing.T) {
+	q := TeamQueryBuilder{LeagueQB: &LeagueQueryBuilder{Keys: []string{"357.l.86753"}}}
+	client := getXMLClient(q.Url(), "league-teams.xml", t)
+
+	var teams []Team
+	err := q.Stream(client.Client, func(team Team) error {
+		teams = append(teams, team)
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("Unexpected TeamQueryBuilder.Stream error: %s", err)
+	}
+
+	if len(teams) != 2 || teams[1].Name != "Panda Express" {
+		t.Errorf("TeamQueryBuilder.Stream returned incorrect teams %v", teams)
+	}
+
+	if len(teams[0].Managers) != 1 {
+		t.Errorf("TeamQueryBuilder.Stream did not decode managers %v", teams[0].Managers)
+	}
+}
+
+func TestStreamErrors(t *testing.T) {
+	q := TeamQueryBuilder{Keys: []string{"357.l.86753.t.1"}}
+	client := gotest.NewRegisteredClient()
+	fn := func(Team) error { return nil }
+
+	// test bad client request
+	if err := q.Stream(client.Client, fn); err == nil {
+		t.Error("Expected TeamQueryBuilder.Stream to return an error")
+	}
+
+	// test non-xml response
+	client.Register(q.Url(), "get", gotest.NewSimpleRoundTrip([]byte("Hello, world"), nil))
+	if err := q.Stream(client.Client, fn); err == nil {
+		t.Error("Expected TeamQueryBuilder.Stream to return an error")
+	}
+
+	// test yahoo error response
+	client.Register(q.Url(), "get", gotest.NewSimpleRoundTrip([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+		<error xml:lang="en-us"><description>Invalid team key</description></error>`), nil))
+	if err := q.Stream(client.Client, fn); err == nil {
+		t.Error("Expected TeamQueryBuilder.Stream to return an error")
+	}
+}
@@ -0,0 +1,298 @@
+package fantasy
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strings"
+)
+
+// Team represents a single team within a Yahoo fantasy League.
+type Team struct {
+	XMLName xml.Name `xml:"team"`
+	// Key is the unique team identifier, it is made of the league key and the team ID e.g. 357.l.86753.t.4
+	Key string `xml:"team_key"`
+	// ID is the id of the team within the League.
+	ID int64 `xml:"team_id"`
+	// The name of the team.
+	Name string `xml:"name"`
+	// The url of the team's page.
+	URL string `xml:"url"`
+	// IsOwnedByActiveUser is true when the logged in user manages this team.
+	IsOwnedByActiveUser Bool `xml:"is_owned_by_current_login"`
+	// WaiverPriority is the team's position in the waiver order.
+	WaiverPriority int64 `xml:"waiver_priority"`
+	// FAABBalance is the remaining free agent budget in leagues which use FAAB.
+	FAABBalance int64 `xml:"faab_balance"`
+	// NumberOfMoves is the number of roster moves the team has made.
+	NumberOfMoves int64 `xml:"number_of_moves"`
+	// NumberOfTrades is the number of trades the team has made.
+	NumberOfTrades int64 `xml:"number_of_trades"`
+	// Managers are the users who manage the team.
+	Managers []Manager `xml:"managers>manager"`
+	// Roster is the team's current roster, nil unless requested with TeamQueryBuilder.Roster.
+	Roster *Roster `xml:"roster"`
+	// Standings is the team's rank and record, nil unless requested with TeamQueryBuilder.Standings.
+	Standings *TeamStandings `xml:"team_standings"`
+}
+
+// TeamStandings is the place of a Team in its League.
+type TeamStandings struct {
+	Rank        Int `xml:"rank"`
+	PlayoffSeed Int `xml:"playoff_seed"`
+	// OutcomeTotals is the team's record in head to head leagues.
+	OutcomeTotals OutcomeTotals `xml:"outcome_totals"`
+	// GamesBack is how far the team trails the leader e.g. 1.5, it is - for the leader.
+	GamesBack     string `xml:"games_back"`
+	PointsFor     Float  `xml:"points_for"`
+	PointsAgainst Float  `xml:"points_against"`
+}
+
+// OutcomeTotals is a head to head record.
+type OutcomeTotals struct {
+	Wins   Int `xml:"wins"`
+	Losses Int `xml:"losses"`
+	Ties   Int `xml:"ties"`
+	// Percentage is the share of games won e.g. .625
+	Percentage Float `xml:"percentage"`
+}
+
+// Roster holds the players of a Team for a week or a date.
+type Roster struct {
+	// CoverageType is week in leagues with weekly lineups and date in leagues with daily lineups.
+	CoverageType string `xml:"coverage_type"`
+	Week         Int    `xml:"week"`
+	Date         Date   `xml:"date"`
+	// IsEditable is true while the lineup can still be changed.
+	IsEditable Bool           `xml:"is_editable"`
+	Players    []RosterPlayer `xml:"players>player"`
+}
+
+// RosterPlayer is a player on a Roster and the position they are placed in.
+type RosterPlayer struct {
+	Player
+	SelectedPosition SelectedPosition `xml:"selected_position"`
+}
+
+// SelectedPosition is the roster position of a player for a week or a date.
+type SelectedPosition struct {
+	CoverageType string `xml:"coverage_type"`
+	Week         Int    `xml:"week"`
+	Date         Date   `xml:"date"`
+	// Position is the roster position e.g. 1B, OF, BN or DL.
+	Position string `xml:"position"`
+}
+
+// TeamQueryBuilder contains properties which are used to generate yahoo api team requests.
+type TeamQueryBuilder struct {
+	// Add a LeagueQueryBuilder to return the teams of leagues.
+	LeagueQB *LeagueQueryBuilder
+	// Add Team Keys to return specific teams.
+	Keys []string
+	// Roster set to true includes each team's current Roster.
+	Roster bool
+	// Standings set to true includes each team's Standings.
+	Standings bool
+	// Format is the response format to request, xml by default.
+	Format Format
+	// Batching controls how a query for more keys than Yahoo accepts is split into requests.
+	Batching Batching
+}
+
+// Path returns the yahoo api path for the query excluding the host and query string.
+func (q *TeamQueryBuilder) Path() string {
+	var path string
+	if q.LeagueQB != nil {
+		path = q.LeagueQB.Path()
+	}
+
+	path += "/teams"
+
+	if q.Keys != nil {
+		path += ";team_keys=" + strings.Join(q.Keys, ",")
+	}
+
+	var out []string
+	if q.Roster {
+		out = append(out, "roster")
+	}
+	if q.Standings {
+		out = append(out, "standings")
+	}
+	if out != nil {
+		path += ";out=" + strings.Join(out, ",")
+	}
+
+	return strings.TrimLeft(path, "/")
+}
+
+// Url generates the url needed for a request of the query builder's settings.
+func (q *TeamQueryBuilder) Url() string {
+	return baseUrl + q.Path() + "?format=" + q.Format.String()
+}
+
+// XmlTeamParser must be able to decode an xml token stream and return a slice of Teams.
+type xmlTeamParser interface {
+	parseXML(*xml.Decoder) ([]Team, ResponseMeta, error)
+}
+
+// DefaultXMLTeamParser parses xml with a teams node as a direct child of the fantasy_content node.
+type defaultXMLTeamParser struct {
+	result struct {
+		// XMLName fantasy_content is the main wrapper tag in a Yahoo api response.
+		XMLName xml.Name `xml:"fantasy_content"`
+		responseAttrs
+		//Teams is where a TeamQueryBuilder stores its results.
+		Teams []Team `xml:"teams>team"`
+	}
+}
+
+// ParseXML actually does the transformation from xml to Team slice.
+func (p defaultXMLTeamParser) parseXML(d *xml.Decoder) ([]Team, ResponseMeta, error) {
+	err := d.Decode(&p.result)
+	if err != nil {
+		return []Team{}, ResponseMeta{}, err
+	}
+	return p.result.Teams, p.result.meta(), nil
+}
+
+// LeagueXMLTeamParser parses xml with a structure fantasy_content>leagues>league>teams>team.
+type leagueXMLTeamParser struct {
+	result struct {
+		// XMLName fantasy_content is the main wrapper tag in a Yahoo api response.
+		XMLName xml.Name `xml:"fantasy_content"`
+		responseAttrs
+		//Teams is where a TeamQueryBuilder stores its results.
+		Teams []Team `xml:"leagues>league>teams>team"`
+	}
+}
+
+// ParseXML actually does the transformation from xml to Team slice.
+func (p leagueXMLTeamParser) parseXML(d *xml.Decoder) ([]Team, ResponseMeta, error) {
+	err := d.Decode(&p.result)
+	if err != nil {
+		return []Team{}, ResponseMeta{}, err
+	}
+	return p.result.Teams, p.result.meta(), nil
+}
+
+// UserXMLTeamParser parses xml with a structure fantasy_content>users>user>games>game>leagues>league>teams>team.
+type userXMLTeamParser struct {
+	result struct {
+		// XMLName fantasy_content is the main wrapper tag in a Yahoo api response.
+		XMLName xml.Name `xml:"fantasy_content"`
+		responseAttrs
+		//Teams is where a TeamQueryBuilder stores its results.
+		Teams []Team `xml:"users>user>games>game>leagues>league>teams>team"`
+	}
+}
+
+// ParseXML actually does the transformation from xml to Team slice.
+func (p userXMLTeamParser) parseXML(d *xml.Decoder) ([]Team, ResponseMeta, error) {
+	err := d.Decode(&p.result)
+	if err != nil {
+		return []Team{}, ResponseMeta{}, err
+	}
+	return p.result.Teams, p.result.meta(), nil
+}
+
+// XmlParser returns the appropriate xml parser based on the query builder settings.
+func (q *TeamQueryBuilder) xmlParser() xmlTeamParser {
+	if q.LeagueQB != nil {
+		if q.LeagueQB.UserQB != nil {
+			return new(userXMLTeamParser)
+		}
+		return new(leagueXMLTeamParser)
+	}
+	return new(defaultXMLTeamParser)
+}
+
+// Get sends a request to the appropriate url based on the query builder settings.
+// It then sends that response to a parser and returns the resulting Team slice.
+func (q *TeamQueryBuilder) Get(client *http.Client) ([]Team, error) {
+	teams, _, err := q.GetWithMeta(client)
+	return teams, err
+}
+
+// Validate checks Keys are well formed team keys, along with the keys of nested query builders.
+func (q *TeamQueryBuilder) Validate() error {
+	if q.LeagueQB != nil {
+		if err := q.LeagueQB.Validate(); err != nil {
+			return err
+		}
+	}
+	return validateKeys(q.Keys, validTeamKey)
+}
+
+// GetWithMeta works like Get but also returns the metadata of the response.
+// When Keys holds more keys than Batching allows the teams are requested in batches,
+// the metadata is then that of the first successful batch.
+func (q *TeamQueryBuilder) GetWithMeta(client *http.Client) ([]Team, ResponseMeta, error) {
+	if err := q.Validate(); err != nil {
+		return []Team{}, ResponseMeta{}, err
+	}
+
+	if len(q.Keys) > q.Batching.maxKeys() {
+		return q.getBatched(client)
+	}
+
+	d, err := fetch(client, q.Url(), q.Format)
+	if err != nil {
+		return []Team{}, ResponseMeta{}, err
+	}
+
+	return q.xmlParser().parseXML(d)
+}
+
+// GetBatched requests the teams in chunks of keys and merges the results in key order.
+func (q *TeamQueryBuilder) getBatched(client *http.Client) ([]Team, ResponseMeta, error) {
+	chunks := chunkKeys(q.Keys, q.Batching.maxKeys())
+	results := make([][]Team, len(chunks))
+	metas := make([]ResponseMeta, len(chunks))
+
+	err := batch(chunks, q.Batching.workers(), func(i int, keys []string) error {
+		chunk := *q
+		chunk.Keys = keys
+
+		var err error
+		results[i], metas[i], err = chunk.GetWithMeta(client)
+		return err
+	})
+
+	teams := []Team{}
+	for _, r := range results {
+		teams = append(teams, r...)
+	}
+
+	// yahoo does not return a chunk in the order of its keys.
+	newKeyOrder(q.Keys).sort(teams, func(i int) []string { return []string{teams[i].Key} })
+
+	return teams, firstMeta(metas), err
+}
+
+// Stream works like Get but calls fn with each team as soon as it is decoded instead of building a slice,
+// which keeps memory use flat for large responses. Streaming stops at the first error returned by fn.
+// When Keys holds more keys than Batching allows the batches are streamed one after another in the order of the chunks.
+func (q *TeamQueryBuilder) Stream(client *http.Client, fn func(Team) error) error {
+	if err := q.Validate(); err != nil {
+		return err
+	}
+
+	if len(q.Keys) > q.Batching.maxKeys() {
+		for _, keys := range chunkKeys(q.Keys, q.Batching.maxKeys()) {
+			chunk := *q
+			chunk.Keys = keys
+			if err := chunk.Stream(client, fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return stream(client, q.Url(), q.Format, "team", func(d *xml.Decoder, start xml.StartElement) error {
+		var team Team
+		if err := d.DecodeElement(&team, &start); err != nil {
+			return err
+		}
+		return fn(team)
+	})
+}
@@ -0,0 +1,67 @@
+package fantasy
+
+import (
+	"testing"
+)
+
+func TestTeamQueryBuilderURL(t *testing.T) {
+	var tests = []struct {
+		input TeamQueryBuilder
+		want  string
+	}{
+		{
+			TeamQueryBuilder{Keys: []string{"357.l.86753.t.1", "357.l.86753.t.2"}},
+			baseUrl + "teams;team_keys=357.l.86753.t.1,357.l.86753.t.2?format=xml",
+		},
+		{
+			TeamQueryBuilder{Keys: []string{"357.l.86753.t.1"}, Roster: true},
+			baseUrl + "teams;team_keys=357.l.86753.t.1;out=roster?format=xml",
+		},
+		{
+			TeamQueryBuilder{LeagueQB: &LeagueQueryBuilder{Keys: []string{"357.l.86753"}}, Roster: true, Standings: true},
+			baseUrl + "leagues;league_keys=357.l.86753/teams;out=roster,standings?format=xml",
+		},
+		{
+			TeamQueryBuilder{LeagueQB: &LeagueQueryBuilder{Keys: []string{"357.l.86753"}}},
+			baseUrl + "leagues;league_keys=357.l.86753/teams?format=xml",
+		},
+		{
+			TeamQueryBuilder{LeagueQB: &LeagueQueryBuilder{UserQB: &UserQueryBuilder{ActiveUser: true}}},
+			baseUrl + "users;use_login=1/games/leagues/teams?format=xml",
+		},
+	}
+
+	for _, test := range tests {
+		if got := test.input.Url(); got != test.want {
+			t.Errorf("Url = %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestGetLeagueTeams(t *testing.T) {
+	q := TeamQueryBuilder{LeagueQB: &LeagueQueryBuilder{Keys: []string{"357.l.86753"}}}
+	client := getXMLClient(q.Url(), "league-teams.xml", t)
+
+	teams, err := q.Get(client.Client)
+	if err != nil {
+		t.Fatalf("Unexpected TeamQueryBuilder.Get error: %s", err)
+	}
+
+	if len(teams) != 2 {
+		t.Fatalf("Unexpected Team len got %d, expected %d", len(teams), 2)
+	}
+
+	team := teams[0]
+	if team.Key != "357.l.86753.t.1" {
+		t.Errorf("Team unmarshaled incorrectly. Key: %s, expected %s", team.Key, "357.l.86753.t.1")
+	}
+	if !team.IsOwnedByActiveUser {
+		t.Error("Team unmarshaled incorrectly. Expected IsOwnedByActiveUser to be true")
+	}
+	if team.NumberOfMoves != 4 {
+		t.Errorf("Team unmarshaled incorrectly. NumberOfMoves: %d, expected %d", team.NumberOfMoves, 4)
+	}
+	if len(team.Managers) != 1 || team.Managers[0].Guid != guid {
+		t.Errorf("Team unmarshaled incorrectly. Managers: %v", team.Managers)
+	}
+}
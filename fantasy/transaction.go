@@ -0,0 +1,279 @@
+package fantasy
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Transaction represents a single roster move within a League e.g. an add, drop or trade.
+type Transaction struct {
+	XMLName xml.Name `xml:"transaction"`
+	// Key is the unique transaction identifier e.g. 357.l.86753.tr.12
+	Key string `xml:"transaction_key"`
+	// ID is the id of the transaction within the League.
+	ID int64 `xml:"transaction_id"`
+	// Type is the kind of transaction e.g. add, drop, add/drop, trade or commish.
+	Type string `xml:"type"`
+	// Status is the state of the transaction e.g. successful, pending or proposed.
+	Status string `xml:"status"`
+	// Timestamp is the time the transaction took place.
+	Timestamp Timestamp `xml:"timestamp"`
+	// FAABBid is the amount bid on a waiver claim in leagues which use FAAB.
+	FAABBid int64 `xml:"faab_bid"`
+	// TraderTeamKey is the team which proposed a trade.
+	TraderTeamKey string `xml:"trader_team_key"`
+	// TradeeTeamKey is the team a trade was proposed to.
+	TradeeTeamKey string `xml:"tradee_team_key"`
+	// Players are the players moved by the transaction.
+	Players []TransactionPlayer `xml:"players>player"`
+}
+
+// TransactionPlayer is a player moved by a Transaction.
+type TransactionPlayer struct {
+	Player
+	// TransactionData describes where the player was moved from and to.
+	TransactionData TransactionData `xml:"transaction_data"`
+}
+
+// TransactionData describes the movement of a single player within a Transaction.
+type TransactionData struct {
+	// Type is the kind of movement e.g. add or drop.
+	Type string `xml:"type"`
+	// SourceType is where the player came from e.g. team, freeagents or waivers.
+	SourceType string `xml:"source_type"`
+	// SourceTeamKey is set when the player came from a team.
+	SourceTeamKey string `xml:"source_team_key"`
+	// DestinationType is where the player went e.g. team or waivers.
+	DestinationType string `xml:"destination_type"`
+	// DestinationTeamKey is set when the player went to a team.
+	DestinationTeamKey string `xml:"destination_team_key"`
+}
+
+// TransactionQueryBuilder contains properties which are used to generate yahoo api transaction requests.
+type TransactionQueryBuilder struct {
+	// Add a LeagueQueryBuilder to return the transactions of leagues.
+	LeagueQB *LeagueQueryBuilder
+	// Add Transaction Keys to return specific transactions.
+	Keys []string
+	// Types filters transactions by type e.g. add, drop, trade.
+	Types []string
+	// TeamKey filters transactions to those involving a single team.
+	TeamKey string
+	// Start is the offset of the first transaction to return.
+	Start int
+	// Count is the maximum number of transactions to return.
+	Count int
+	// Format is the response format to request, xml by default.
+	Format Format
+}
+
+// Path returns the yahoo api path for the query excluding the host and query string.
+func (q *TransactionQueryBuilder) Path() string {
+	var path string
+	if q.LeagueQB != nil {
+		path = q.LeagueQB.Path()
+	}
+
+	path += "/transactions"
+
+	if q.Keys != nil {
+		path += ";transaction_keys=" + strings.Join(q.Keys, ",")
+	}
+
+	if q.Types != nil {
+		path += ";types=" + strings.Join(q.Types, ",")
+	}
+
+	if q.TeamKey != "" {
+		path += ";team_key=" + q.TeamKey
+	}
+
+	if q.Start > 0 {
+		path += ";start=" + strconv.Itoa(q.Start)
+	}
+
+	if q.Count > 0 {
+		path += ";count=" + strconv.Itoa(q.Count)
+	}
+
+	return strings.TrimLeft(path, "/")
+}
+
+// Url generates the url needed for a request of the query builder's settings.
+func (q *TransactionQueryBuilder) Url() string {
+	return baseUrl + q.Path() + "?format=" + q.Format.String()
+}
+
+// XmlTransactionParser must be able to decode an xml token stream and return a slice of Transactions.
+type xmlTransactionParser interface {
+	parseXML(*xml.Decoder) ([]Transaction, ResponseMeta, error)
+}
+
+// DefaultXMLTransactionParser parses xml with a transactions node as a direct child of the fantasy_content node.
+type defaultXMLTransactionParser struct {
+	result struct {
+		// XMLName fantasy_content is the main wrapper tag in a Yahoo api response.
+		XMLName xml.Name `xml:"fantasy_content"`
+		responseAttrs
+		//Transactions is where a TransactionQueryBuilder stores its results.
+		Transactions []Transaction `xml:"transactions>transaction"`
+	}
+}
+
+// ParseXML actually does the transformation from xml to Transaction slice.
+func (p defaultXMLTransactionParser) parseXML(d *xml.Decoder) ([]Transaction, ResponseMeta, error) {
+	err := d.Decode(&p.result)
+	if err != nil {
+		return []Transaction{}, ResponseMeta{}, err
+	}
+	return p.result.Transactions, p.result.meta(), nil
+}
+
+// LeagueXMLTransactionParser parses xml with a structure fantasy_content>leagues>league>transactions>transaction.
+type leagueXMLTransactionParser struct {
+	result struct {
+		// XMLName fantasy_content is the main wrapper tag in a Yahoo api response.
+		XMLName xml.Name `xml:"fantasy_content"`
+		responseAttrs
+		//Transactions is where a TransactionQueryBuilder stores its results.
+		Transactions []Transaction `xml:"leagues>league>transactions>transaction"`
+	}
+}
+
+// ParseXML actually does the transformation from xml to Transaction slice.
+func (p leagueXMLTransactionParser) parseXML(d *xml.Decoder) ([]Transaction, ResponseMeta, error) {
+	err := d.Decode(&p.result)
+	if err != nil {
+		return []Transaction{}, ResponseMeta{}, err
+	}
+	return p.result.Transactions, p.result.meta(), nil
+}
+
+// UserXMLTransactionParser parses xml with a structure fantasy_content>users>user>games>game>leagues>league>transactions>transaction.
+type userXMLTransactionParser struct {
+	result struct {
+		// XMLName fantasy_content is the main wrapper tag in a Yahoo api response.
+		XMLName xml.Name `xml:"fantasy_content"`
+		responseAttrs
+		//Transactions is where a TransactionQueryBuilder stores its results.
+		Transactions []Transaction `xml:"users>user>games>game>leagues>league>transactions>transaction"`
+	}
+}
+
+// ParseXML actually does the transformation from xml to Transaction slice.
+func (p userXMLTransactionParser) parseXML(d *xml.Decoder) ([]Transaction, ResponseMeta, error) {
+	err := d.Decode(&p.result)
+	if err != nil {
+		return []Transaction{}, ResponseMeta{}, err
+	}
+	return p.result.Transactions, p.result.meta(), nil
+}
+
+// XmlParser returns the appropriate xml parser based on the query builder settings.
+func (q *TransactionQueryBuilder) xmlParser() xmlTransactionParser {
+	if q.LeagueQB != nil {
+		if q.LeagueQB.UserQB != nil {
+			return new(userXMLTransactionParser)
+		}
+		return new(leagueXMLTransactionParser)
+	}
+	return new(defaultXMLTransactionParser)
+}
+
+// Get sends a request to the appropriate url based on the query builder settings.
+// It then sends that response to a parser and returns the resulting Transaction slice.
+func (q *TransactionQueryBuilder) Get(client *http.Client) ([]Transaction, error) {
+	transactions, _, err := q.GetWithMeta(client)
+	return transactions, err
+}
+
+// Validate checks TeamKey and the keys of the league query builder.
+func (q *TransactionQueryBuilder) Validate() error {
+	if q.LeagueQB != nil {
+		if err := q.LeagueQB.Validate(); err != nil {
+			return err
+		}
+	}
+	if q.TeamKey != "" {
+		return validTeamKey(q.TeamKey)
+	}
+	return nil
+}
+
+// GetWithMeta works like Get but also returns the metadata of the response.
+func (q *TransactionQueryBuilder) GetWithMeta(client *http.Client) ([]Transaction, ResponseMeta, error) {
+	if err := q.Validate(); err != nil {
+		return []Transaction{}, ResponseMeta{}, err
+	}
+
+	d, err := fetch(client, q.Url(), q.Format)
+	if err != nil {
+		return []Transaction{}, ResponseMeta{}, err
+	}
+
+	return q.xmlParser().parseXML(d)
+}
+
+// Iter returns an iterator which walks every transaction matching the query a page at a time.
+// Pages hold Count transactions, or PageSize transactions when Count is not set or larger, beginning at Start.
+// When prefetch is greater than zero up to prefetch pages are requested ahead of the caller.
+func (q *TransactionQueryBuilder) Iter(client *http.Client, prefetch int) *TransactionIterator {
+	fetch := func(start, count int) (interface{}, int, error) {
+		tq := *q
+		tq.Start, tq.Count = start, count
+
+		transactions, err := tq.Get(client)
+		return transactions, len(transactions), err
+	}
+
+	return &TransactionIterator{pager: newPager(q.Start, q.Count, prefetch, fetch)}
+}
+
+// TransactionIterator lazily requests the pages of a transaction query.
+type TransactionIterator struct {
+	pager        *pager
+	transactions []Transaction
+	transaction  Transaction
+	err          error
+}
+
+// Next advances the iterator to the next transaction, requesting the next page when needed.
+// It returns false when there are no more transactions or a request failed.
+func (it *TransactionIterator) Next() bool {
+	for len(it.transactions) == 0 {
+		if it.err != nil {
+			return false
+		}
+
+		pg, ok := it.pager.next()
+		if !ok {
+			return false
+		}
+
+		if pg.err != nil {
+			it.err = pg.err
+			return false
+		}
+		it.transactions = pg.items.([]Transaction)
+	}
+
+	it.transaction, it.transactions = it.transactions[0], it.transactions[1:]
+	return true
+}
+
+// Transaction returns the current transaction.
+func (it *TransactionIterator) Transaction() Transaction {
+	return it.transaction
+}
+
+// Err returns the error which stopped the iterator, if any.
+func (it *TransactionIterator) Err() error {
+	return it.err
+}
+
+// Close releases the prefetching goroutine of an iterator which is abandoned before it is exhausted.
+func (it *TransactionIterator) Close() {
+	it.pager.close()
+}
@@ -0,0 +1,68 @@
+package fantasy
+
+import (
+	"testing"
+)
+
+func TestTransactionQueryBuilderURL(t *testing.T) {
+	var tests = []struct {
+		input TransactionQueryBuilder
+		want  string
+	}{
+		{
+			TransactionQueryBuilder{Keys: []string{"357.l.86753.tr.12"}},
+			baseUrl + "transactions;transaction_keys=357.l.86753.tr.12?format=xml",
+		},
+		{
+			TransactionQueryBuilder{
+				LeagueQB: &LeagueQueryBuilder{Keys: []string{"357.l.86753"}},
+				Types:    []string{"add", "drop"},
+				TeamKey:  "357.l.86753.t.1",
+				Start:    10,
+				Count:    5,
+			},
+			baseUrl + "leagues;league_keys=357.l.86753/transactions;types=add,drop;team_key=357.l.86753.t.1;start=10;count=5?format=xml",
+		},
+	}
+
+	for _, test := range tests {
+		if got := test.input.Url(); got != test.want {
+			t.Errorf("Url = %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestGetLeagueTransactions(t *testing.T) {
+	q := TransactionQueryBuilder{LeagueQB: &LeagueQueryBuilder{Keys: []string{"357.l.86753"}}}
+	client := getXMLClient(q.Url(), "league-transactions.xml", t)
+
+	transactions, err := q.Get(client.Client)
+	if err != nil {
+		t.Fatalf("Unexpected TransactionQueryBuilder.Get error: %s", err)
+	}
+
+	if len(transactions) != 2 {
+		t.Fatalf("Unexpected Transaction len got %d, expected %d", len(transactions), 2)
+	}
+
+	tr := transactions[0]
+	if tr.Type != "add/drop" || tr.Timestamp.Unix() != 1460153112 {
+		t.Errorf("Transaction unmarshaled incorrectly. Type: %s, Timestamp: %d", tr.Type, tr.Timestamp.Unix())
+	}
+
+	if len(tr.Players) != 2 {
+		t.Fatalf("Unexpected Transaction Players len got %d, expected %d", len(tr.Players), 2)
+	}
+
+	drop := tr.Players[1]
+	if drop.Name.Full != "Angel Pagan" || drop.TransactionData.Type != "drop" {
+		t.Errorf("Transaction player unmarshaled incorrectly. Name: %s, Type: %s", drop.Name.Full, drop.TransactionData.Type)
+	}
+	if drop.TransactionData.SourceTeamKey != "357.l.86753.t.1" {
+		t.Errorf("Transaction player unmarshaled incorrectly. SourceTeamKey: %s", drop.TransactionData.SourceTeamKey)
+	}
+
+	if transactions[1].TraderTeamKey != "357.l.86753.t.2" {
+		t.Errorf("Transaction unmarshaled incorrectly. TraderTeamKey: %s", transactions[1].TraderTeamKey)
+	}
+}
@@ -1,45 +1,374 @@
 package fantasy
 
 import (
-	/*"fmt"
-	  "log"
-	  "strings"*/
+	"bytes"
+	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
-// IntAsBool reads integer xml node values and converts them to true or false,
-// 0 = false, 1 = true, everything else errors.
-type intAsBool bool
+// DateFormat is the layout of Yahoo's calendar dates e.g. 2014-05-22
+const DateFormat = "2006-01-02"
 
-// UnmarshalXML takes an xml element, reads its content as an int64 and converts that to a bool.
-func (b *intAsBool) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
-	var i int64
-	d.DecodeElement(&i, &start)
+// jsonNull is the json encoding of an empty value.
+var jsonNull = []byte("null")
 
-	if i == 0 {
-		*b = intAsBool(false)
+// Bool is a 0 or 1 xml value e.g. <is_undroppable>1</is_undroppable>, in json it is true or false.
+type Bool bool
+
+// UnmarshalXML reads 0 as false and 1 as true, everything else errors.
+func (b *Bool) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var s string
+	if err := d.DecodeElement(&s, &start); err != nil {
+		return err
+	}
+	return b.parse(s)
+}
+
+// MarshalXML writes 0 or 1.
+func (b Bool) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(b.digit(), start)
+}
+
+// UnmarshalJSON reads true, false, 0 or 1.
+func (b *Bool) UnmarshalJSON(data []byte) error {
+	switch s := string(data); s {
+	case "true", "false":
+		*b = Bool(s == "true")
 		return nil
+	default:
+		return b.parse(strings.Trim(s, `"`))
+	}
+}
+
+// MarshalJSON writes true or false.
+func (b Bool) MarshalJSON() ([]byte, error) {
+	return json.Marshal(bool(b))
+}
+
+func (b *Bool) parse(s string) error {
+	switch strings.TrimSpace(s) {
+	case "0":
+		*b = false
+	case "1":
+		*b = true
+	default:
+		return fmt.Errorf("Bad Bool value %q (0 = false, 1 = true)", s)
 	}
+	return nil
+}
+
+func (b Bool) digit() string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// Date is a calendar date e.g. <start_date>2014-05-22</start_date>.
+// An empty element is the zero Date.
+type Date time.Time
 
-	if i == 1 {
-		*b = intAsBool(true)
+// ParseDate parses a yyyy-mm-dd date, an empty string is the zero Date.
+func ParseDate(s string) (Date, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Date{}, nil
+	}
+	t, err := time.Parse(DateFormat, s)
+	return Date(t), err
+}
+
+// Time returns the date as midnight UTC.
+func (d Date) Time() time.Time {
+	return time.Time(d)
+}
+
+// IsZero reports whether the date is empty.
+func (d Date) IsZero() bool {
+	return time.Time(d).IsZero()
+}
+
+// String formats the date as yyyy-mm-dd, the zero Date is empty.
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return time.Time(d).Format(DateFormat)
+}
+
+// UnmarshalXML reads a yyyy-mm-dd date or an empty element.
+func (d *Date) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	var s string
+	if err := dec.DecodeElement(&s, &start); err != nil {
+		return err
+	}
+	p, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = p
+	return nil
+}
+
+// MarshalXML writes a yyyy-mm-dd date, the zero Date is an empty element.
+func (d Date) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(d.String(), start)
+}
+
+// UnmarshalJSON reads a "yyyy-mm-dd" string, an empty string or null.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, jsonNull) {
+		*d = Date{}
 		return nil
 	}
-	return fmt.Errorf("Bad intAsBool value %d (0 = false 1, = true)", i)
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	p, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = p
+	return nil
+}
+
+// MarshalJSON writes a "yyyy-mm-dd" string, the zero Date is null.
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return jsonNull, nil
+	}
+	return json.Marshal(d.String())
+}
+
+// Timestamp is a unix time in seconds e.g. <timestamp>1460153112</timestamp>, in json it is an RFC 3339 string.
+// An empty element is the zero Timestamp.
+type Timestamp time.Time
+
+// Time returns the timestamp as a time.Time.
+func (t Timestamp) Time() time.Time {
+	return time.Time(t)
+}
+
+// Unix returns the timestamp in seconds since January 1, 1970 UTC, the zero Timestamp is 0.
+func (t Timestamp) Unix() int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return time.Time(t).Unix()
 }
 
-type calendarDate time.Time
+// IsZero reports whether the timestamp is empty.
+func (t Timestamp) IsZero() bool {
+	return time.Time(t).IsZero()
+}
 
-func (c *calendarDate) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
-	const shortForm = "2006-01-02" // yyyy-mm-dd date format
-	var v string
-	d.DecodeElement(&v, &start)
-	parse, err := time.Parse(shortForm, v)
+// String formats the timestamp as RFC 3339, the zero Timestamp is empty.
+func (t Timestamp) String() string {
+	if t.IsZero() {
+		return ""
+	}
+	return time.Time(t).Format(time.RFC3339)
+}
+
+// UnmarshalXML reads unix seconds or an empty element.
+func (t *Timestamp) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var s string
+	if err := d.DecodeElement(&s, &start); err != nil {
+		return err
+	}
+	return t.parseUnix(s)
+}
+
+// MarshalXML writes unix seconds, the zero Timestamp is an empty element.
+func (t Timestamp) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if t.IsZero() {
+		return e.EncodeElement("", start)
+	}
+	return e.EncodeElement(t.Unix(), start)
+}
+
+// UnmarshalJSON reads an RFC 3339 string, unix seconds as a number or string, or null.
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, jsonNull) {
+		*t = Timestamp{}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		// a json number
+		return t.parseUnix(string(data))
+	}
+
+	if p, err := time.Parse(time.RFC3339, s); err == nil {
+		*t = Timestamp(p)
+		return nil
+	}
+	return t.parseUnix(s)
+}
+
+// MarshalJSON writes an RFC 3339 string, the zero Timestamp is null.
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return jsonNull, nil
+	}
+	return json.Marshal(t.String())
+}
+
+func (t *Timestamp) parseUnix(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		*t = Timestamp{}
+		return nil
+	}
+
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("Bad Timestamp value %q", s)
+	}
+	*t = Timestamp(time.Unix(sec, 0).UTC())
+	return nil
+}
+
+// Int is an integer xml value which may be empty e.g. <faab_balance/> or a stat value of -.
+// Valid is false for an empty value, which is null in json.
+type Int struct {
+	Value int64
+	Valid bool
+}
+
+// NewInt returns a valid Int.
+func NewInt(v int64) Int {
+	return Int{Value: v, Valid: true}
+}
+
+// String formats the value, an empty Int is empty.
+func (i Int) String() string {
+	if !i.Valid {
+		return ""
+	}
+	return strconv.FormatInt(i.Value, 10)
+}
+
+// UnmarshalXML reads an integer, an empty element or -.
+func (i *Int) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var s string
+	if err := d.DecodeElement(&s, &start); err != nil {
+		return err
+	}
+	return i.parse(s)
+}
+
+// MarshalXML writes the integer, an empty Int is an empty element.
+func (i Int) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(i.String(), start)
+}
+
+// UnmarshalJSON reads a number, a numeric string or null.
+func (i *Int) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, jsonNull) {
+		*i = Int{}
+		return nil
+	}
+	return i.parse(strings.Trim(string(data), `"`))
+}
+
+// MarshalJSON writes the number, an empty Int is null.
+func (i Int) MarshalJSON() ([]byte, error) {
+	if !i.Valid {
+		return jsonNull, nil
+	}
+	return []byte(i.String()), nil
+}
+
+func (i *Int) parse(s string) error {
+	s = strings.TrimSpace(s)
+	if isEmptyNumber(s) {
+		*i = Int{}
+		return nil
+	}
+
+	v, err := strconv.ParseInt(s, 10, 64)
 	if err != nil {
+		return fmt.Errorf("Bad Int value %q", s)
+	}
+	*i = NewInt(v)
+	return nil
+}
+
+// Float is a decimal xml value which may be empty e.g. <percent_owned/> or a stat value of -.
+// Valid is false for an empty value, which is null in json.
+type Float struct {
+	Value float64
+	Valid bool
+}
+
+// NewFloat returns a valid Float.
+func NewFloat(v float64) Float {
+	return Float{Value: v, Valid: true}
+}
+
+// String formats the value, an empty Float is empty.
+func (f Float) String() string {
+	if !f.Valid {
+		return ""
+	}
+	return strconv.FormatFloat(f.Value, 'f', -1, 64)
+}
+
+// UnmarshalXML reads a number, an empty element or -.
+func (f *Float) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var s string
+	if err := d.DecodeElement(&s, &start); err != nil {
 		return err
 	}
-	*c = calendarDate(parse)
+	return f.parse(s)
+}
+
+// MarshalXML writes the number, an empty Float is an empty element.
+func (f Float) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(f.String(), start)
+}
+
+// UnmarshalJSON reads a number, a numeric string or null.
+func (f *Float) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, jsonNull) {
+		*f = Float{}
+		return nil
+	}
+	return f.parse(strings.Trim(string(data), `"`))
+}
+
+// MarshalJSON writes the number, an empty Float is null.
+func (f Float) MarshalJSON() ([]byte, error) {
+	if !f.Valid {
+		return jsonNull, nil
+	}
+	return []byte(f.String()), nil
+}
+
+func (f *Float) parse(s string) error {
+	s = strings.TrimSpace(s)
+	if isEmptyNumber(s) {
+		*f = Float{}
+		return nil
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("Bad Float value %q", s)
+	}
+	*f = NewFloat(v)
 	return nil
 }
+
+// IsEmptyNumber reports whether s is a value Yahoo uses for a missing number.
+func isEmptyNumber(s string) bool {
+	return s == "" || s == "-"
+}
@@ -1,15 +1,16 @@
 package fantasy
 
 import (
+	"encoding/json"
 	"encoding/xml"
 	"testing"
 	"time"
 )
 
-func TestUnmarshalIntAsBool(t *testing.T) {
+func TestUnmarshalBool(t *testing.T) {
 	type testObject struct {
-		XMLName xml.Name  `xml:"content"`
-		Expired intAsBool `xml:"expired"`
+		XMLName xml.Name `xml:"content"`
+		Expired Bool     `xml:"expired"`
 	}
 
 	var tests = []struct {
@@ -40,23 +41,23 @@ func TestUnmarshalIntAsBool(t *testing.T) {
 
 		err := xml.Unmarshal(test.input, &obj)
 		if test.err && err == nil {
-			t.Errorf("expecting Unmaarshal intAsBool to return an error for %s", test.input)
+			t.Errorf("expecting Unmarshal Bool to return an error for %s", test.input)
 		}
 
 		if !test.err && err != nil {
-			t.Errorf("Unmarshal intAsBool returned an error: ", err)
+			t.Errorf("Unmarshal Bool returned an error: ", err)
 		}
 
-		if obj.Expired != intAsBool(test.expect) {
-			t.Errorf("intAsBool unmarshalled incorrectly got %s, expected %s", obj.Expired, test.expect)
+		if obj.Expired != Bool(test.expect) {
+			t.Errorf("Bool unmarshalled incorrectly got %s, expected %s", obj.Expired, test.expect)
 		}
 	}
 }
 
 func TestCalendarDate(t *testing.T) {
 	type testObject struct {
-		XMLName xml.Name     `xml:"content"`
-		Date    calendarDate `xml:"date"`
+		XMLName xml.Name `xml:"content"`
+		Date    Date     `xml:"date"`
 	}
 
 	var tests = []struct {
@@ -70,9 +71,14 @@ func TestCalendarDate(t *testing.T) {
 			"2014-05-22",
 			false,
 		},
+		{
+			[]byte(`<content><date/></content>`),
+			"",
+			false,
+		},
 		{
 			[]byte(`<content><date>22-05-2014</date></content>`),
-			time.Time{}.Format("2006-01-02"),
+			"",
 			true,
 		},
 	}
@@ -82,15 +88,114 @@ func TestCalendarDate(t *testing.T) {
 
 		err := xml.Unmarshal(test.input, &obj)
 		if test.err && err == nil {
-			t.Errorf("expecting Unmaarshal intAsBool to return an error for %s", test.input)
+			t.Errorf("expecting Unmarshal Date to return an error for %s", test.input)
 		}
 
 		if !test.err && err != nil {
-			t.Errorf("Unmarshal intAsBool returned an error: ", err)
+			t.Errorf("Unmarshal Date returned an error: ", err)
 		}
-		dateStr := time.Time(obj.Date).Format("2006-01-02")
+		dateStr := obj.Date.String()
 		if dateStr != test.expect {
-			t.Errorf("calendarDate unmarshalled incorrectly got %s, expected %s", dateStr, test.expect)
+			t.Errorf("Date unmarshalled incorrectly got %s, expected %s", dateStr, test.expect)
 		}
 	}
 }
+
+func TestValueTypesRoundTrip(t *testing.T) {
+	type testObject struct {
+		XMLName   xml.Name  `xml:"content" json:"-"`
+		Expired   Bool      `xml:"expired" json:"expired"`
+		Date      Date      `xml:"date" json:"date"`
+		Deadline  Date      `xml:"deadline" json:"deadline"`
+		Timestamp Timestamp `xml:"timestamp" json:"timestamp"`
+		Balance   Int       `xml:"balance" json:"balance"`
+		Bid       Int       `xml:"bid" json:"bid"`
+		Owned     Float     `xml:"owned" json:"owned"`
+		Ratio     Float     `xml:"ratio" json:"ratio"`
+	}
+
+	input := `<content><expired>1</expired><date>2014-05-22</date><deadline></deadline>` +
+		`<timestamp>1460153112</timestamp><balance>87</balance><bid>-</bid><owned>95.5</owned><ratio></ratio></content>`
+
+	obj := testObject{}
+	if err := xml.Unmarshal([]byte(input), &obj); err != nil {
+		t.Fatalf("Unexpected Unmarshal error: %v", err)
+	}
+
+	if !bool(obj.Expired) || obj.Date.Time() != time.Date(2014, 5, 22, 0, 0, 0, 0, time.UTC) || !obj.Deadline.IsZero() {
+		t.Errorf("Unmarshal incorrect bool or dates: %+v", obj)
+	}
+	if obj.Timestamp.Unix() != 1460153112 || obj.Timestamp.Time().Location() != time.UTC {
+		t.Errorf("Unmarshal incorrect timestamp %v", obj.Timestamp)
+	}
+	if obj.Balance != NewInt(87) || obj.Bid.Valid || obj.Owned != NewFloat(95.5) || obj.Ratio.Valid {
+		t.Errorf("Unmarshal incorrect numbers: %+v", obj)
+	}
+
+	out, err := xml.Marshal(obj)
+	if err != nil {
+		t.Fatalf("Unexpected Marshal error: %v", err)
+	}
+	expect := `<content><expired>1</expired><date>2014-05-22</date><deadline></deadline>` +
+		`<timestamp>1460153112</timestamp><balance>87</balance><bid></bid><owned>95.5</owned><ratio></ratio></content>`
+	if string(out) != expect {
+		t.Errorf("Marshal xml = %s, expected %s", out, expect)
+	}
+
+	js, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("Unexpected json Marshal error: %v", err)
+	}
+	expect = `{"expired":true,"date":"2014-05-22","deadline":null,"timestamp":"2016-04-08T22:05:12Z",` +
+		`"balance":87,"bid":null,"owned":95.5,"ratio":null}`
+	if string(js) != expect {
+		t.Errorf("Marshal json = %s, expected %s", js, expect)
+	}
+
+	back := testObject{}
+	if err := json.Unmarshal(js, &back); err != nil {
+		t.Fatalf("Unexpected json Unmarshal error: %v", err)
+	}
+	back.XMLName = obj.XMLName
+	if back.Timestamp.Unix() != obj.Timestamp.Unix() {
+		t.Errorf("json round trip timestamp = %v, expected %v", back.Timestamp, obj.Timestamp)
+	}
+	back.Timestamp, obj.Timestamp = Timestamp{}, Timestamp{}
+	if back != obj {
+		t.Errorf("json round trip = %+v, expected %+v", back, obj)
+	}
+}
+
+func TestValueTypesJSONInput(t *testing.T) {
+	var b Bool
+	if err := json.Unmarshal([]byte(`"1"`), &b); err != nil || !b {
+		t.Errorf("Bool from \"1\" = %t, %v", b, err)
+	}
+	if err := json.Unmarshal([]byte(`2`), &b); err == nil {
+		t.Error("Expected an error unmarshaling Bool 2")
+	}
+
+	var ts Timestamp
+	if err := json.Unmarshal([]byte(`1460153112`), &ts); err != nil || ts.Unix() != 1460153112 {
+		t.Errorf("Timestamp from a number = %v, %v", ts, err)
+	}
+	if err := json.Unmarshal([]byte(`"1460153112"`), &ts); err != nil || ts.Unix() != 1460153112 {
+		t.Errorf("Timestamp from a string = %v, %v", ts, err)
+	}
+	if err := json.Unmarshal([]byte(`"yesterday"`), &ts); err == nil {
+		t.Error("Expected an error unmarshaling Timestamp yesterday")
+	}
+
+	var i Int
+	if err := json.Unmarshal([]byte(`"12"`), &i); err != nil || i != NewInt(12) {
+		t.Errorf("Int from a string = %v, %v", i, err)
+	}
+	if err := json.Unmarshal([]byte(`1.5`), &i); err == nil {
+		t.Error("Expected an error unmarshaling Int 1.5")
+	}
+
+	var d Date
+	if err := json.Unmarshal([]byte(`""`), &d); err != nil || !d.IsZero() {
+		t.Errorf("Date from an empty string = %v, %v", d, err)
+	}
+}
@@ -0,0 +1,298 @@
+package fantasy
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// sportCodes maps the first path segment of fantasy website urls to game codes.
+var sportCodes = map[string]string{
+	"b1":     "mlb",
+	"b2":     "mlb",
+	"f1":     "nfl",
+	"f2":     "nfl",
+	"nba":    "nba",
+	"hockey": "nhl",
+}
+
+// subresources are api resources which ParseURI ignores because they only change what is returned about their parent.
+var subresources = map[string]bool{
+	"metadata":        true,
+	"scoreboard":      true,
+	"stats":           true,
+	"draftresults":    true,
+	"matchups":        true,
+	"ownership":       true,
+	"percent_owned":   true,
+	"stat_categories": true,
+}
+
+// ParsedURI holds the query builders equivalent to a Yahoo api path or fantasy website url.
+// Each builder is nil when the uri does not refer to that resource.
+type ParsedURI struct {
+	Game        *GameQueryBuilder
+	League      *LeagueQueryBuilder
+	Team        *TeamQueryBuilder
+	Player      *PlayerQueryBuilder
+	Transaction *TransactionQueryBuilder
+}
+
+// ParseURI turns an api path or url e.g. the yahoo:uri of a response, or a fantasy website url
+// e.g. https://baseball.fantasysports.yahoo.com/b1/86753/4 into query builders.
+// Website urls name a sport and an optional season instead of a game key, when client is not nil
+// the game key is looked up with a games request, otherwise the game code is used
+// which only refers to the current season, so past seasons require a client.
+func ParseURI(client *http.Client, uri string) (*ParsedURI, error) {
+	u, err := url.Parse(strings.TrimSpace(uri))
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasSuffix(u.Hostname(), "fantasysports.yahoo.com") {
+		return parseWebsiteURL(client, u)
+	}
+	return parseAPIPath(u.Path)
+}
+
+// ParseAPIPath reads api resources e.g. leagues;league_keys=357.l.86753/teams
+func parseAPIPath(path string) (*ParsedURI, error) {
+	path = strings.Trim(path, "/")
+	if i := strings.Index(path, "fantasy/v2/"); i >= 0 {
+		path = path[i+len("fantasy/v2/"):]
+	}
+	if path == "" {
+		return nil, errors.New("Empty fantasy api path")
+	}
+
+	p := &ParsedURI{}
+	var user *UserQueryBuilder
+	segments := strings.Split(path, "/")
+
+	for i := 0; i < len(segments); i++ {
+		name, params := splitSegment(segments[i])
+
+		// singular resources are followed by their key.
+		var key []string
+		switch name {
+		case "game", "league", "team", "player", "transaction":
+			if i+1 >= len(segments) {
+				return nil, fmt.Errorf("Missing %s key in %s", name, path)
+			}
+			i++
+			key = []string{segments[i]}
+		}
+
+		switch name {
+		case "users":
+			user = &UserQueryBuilder{ActiveUser: params["use_login"] == "1"}
+		case "game", "games":
+			g := GameQueryBuilder{Keys: orKeys(key, params["game_keys"]), Codes: splitParam(params["game_codes"])}
+			for _, s := range splitParam(params["seasons"]) {
+				season, err := strconv.ParseInt(s, 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("Bad season %s in %s", s, path)
+				}
+				g.Seasons = append(g.Seasons, season)
+			}
+
+			p.Game = &g
+			if user != nil {
+				// the user's games, later resources are requested through the user's filtered games.
+				p.Game = &GameQueryBuilder{UserQB: user, Keys: g.Keys, Codes: g.Codes, Seasons: g.Seasons}
+				user = &UserQueryBuilder{ActiveUser: user.ActiveUser, GameQB: &g}
+			}
+		case "league", "leagues":
+			p.League = &LeagueQueryBuilder{UserQB: user, Keys: orKeys(key, params["league_keys"])}
+			for _, out := range splitParam(params["out"]) {
+				p.League.Settings = p.League.Settings || out == "settings"
+			}
+		case "team", "teams":
+			p.Team = &TeamQueryBuilder{LeagueQB: p.League, Keys: orKeys(key, params["team_keys"])}
+			for _, out := range splitParam(params["out"]) {
+				p.Team.Roster = p.Team.Roster || out == "roster"
+				p.Team.Standings = p.Team.Standings || out == "standings"
+			}
+		case "player", "players":
+			p.Player = &PlayerQueryBuilder{
+				LeagueQB: p.League,
+				Keys:     orKeys(key, params["player_keys"]),
+				Status:   params["status"],
+				Position: params["position"],
+				Search:   params["search"],
+				Sort:     params["sort"],
+			}
+			p.Player.Start, _ = strconv.Atoi(params["start"])
+			p.Player.Count, _ = strconv.Atoi(params["count"])
+			if p.League == nil {
+				p.Player.GameQB = p.Game
+			}
+		case "transaction", "transactions":
+			p.Transaction = &TransactionQueryBuilder{
+				LeagueQB: p.League,
+				Keys:     orKeys(key, params["transaction_keys"]),
+				Types:    splitParam(params["types"]),
+				TeamKey:  params["team_key"],
+			}
+			p.Transaction.Start, _ = strconv.Atoi(params["start"])
+			p.Transaction.Count, _ = strconv.Atoi(params["count"])
+		case "roster":
+			if p.Team != nil && p.Player == nil && p.Transaction == nil {
+				p.Team.Roster = true
+			}
+		case "standings":
+			// the standings of a league are those of its teams.
+			if p.Player != nil || p.Transaction != nil {
+				break
+			}
+			if p.Team == nil && p.League != nil {
+				p.Team = &TeamQueryBuilder{LeagueQB: p.League}
+			}
+			if p.Team != nil {
+				p.Team.Standings = true
+			}
+		case "settings":
+			// the settings of a league are included by its query builder.
+			if p.League != nil && p.Team == nil && p.Player == nil && p.Transaction == nil {
+				p.League.Settings = true
+			}
+		default:
+			if !subresources[name] {
+				return nil, fmt.Errorf("Unsupported fantasy api resource %s in %s", name, path)
+			}
+		}
+	}
+
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ParseWebsiteURL reads a league or team page url e.g. /b1/86753/4 or /2015/b1/86753
+func parseWebsiteURL(client *http.Client, u *url.URL) (*ParsedURI, error) {
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	var season int64
+	if s, err := strconv.ParseInt(segments[0], 10, 64); err == nil {
+		season = s
+		segments = segments[1:]
+	}
+
+	if len(segments) < 2 {
+		return nil, fmt.Errorf("%s is not a fantasy league url", u)
+	}
+
+	code, ok := sportCodes[segments[0]]
+	if !ok {
+		return nil, fmt.Errorf("Unknown fantasy sport %s in %s", segments[0], u)
+	}
+
+	leagueID, ok := parseID(segments[1])
+	if !ok {
+		return nil, fmt.Errorf("Bad league id %s in %s", segments[1], u)
+	}
+
+	game, err := resolveGameKey(client, code, season)
+	if err != nil {
+		return nil, err
+	}
+
+	league := game.League(leagueID)
+	p := &ParsedURI{
+		Game:   &GameQueryBuilder{Keys: []string{game.String()}},
+		League: &LeagueQueryBuilder{Keys: []string{league.String()}},
+	}
+
+	if len(segments) > 2 {
+		if teamID, ok := parseID(segments[2]); ok {
+			p.Team = &TeamQueryBuilder{Keys: []string{league.Team(teamID).String()}}
+		}
+	}
+	return p, nil
+}
+
+// ResolveGameKey finds the key of the game with code in season.
+// Without a client the code itself is used, it refers to the current season.
+func resolveGameKey(client *http.Client, code string, season int64) (GameKey, error) {
+	if client == nil {
+		if season != 0 {
+			return GameKey{}, fmt.Errorf("A client is required to find the %s game of the %d season", code, season)
+		}
+		return GameKey{Code: code}, nil
+	}
+
+	q := GameQueryBuilder{Codes: []string{code}}
+	if season != 0 {
+		q.Seasons = []int64{season}
+	}
+
+	games, err := q.Get(client)
+	if err != nil {
+		return GameKey{}, err
+	}
+	if len(games) == 0 {
+		return GameKey{}, fmt.Errorf("No %s game found for season %d", code, season)
+	}
+	return GameKey{ID: games[0].Key}, nil
+}
+
+// SplitSegment splits a path segment into its resource name and parameters e.g. leagues;league_keys=357.l.1
+func splitSegment(segment string) (string, map[string]string) {
+	parts := strings.Split(segment, ";")
+	params := map[string]string{}
+	for _, p := range parts[1:] {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) == 2 {
+			params[kv[0]] = kv[1]
+		}
+	}
+	return parts[0], params
+}
+
+// SplitParam splits a comma separated parameter value, an empty value is nil.
+func splitParam(v string) []string {
+	if v == "" {
+		return nil
+	}
+	return strings.Split(v, ",")
+}
+
+// OrKeys returns the key of a singular resource or the keys of a collection.
+func orKeys(key []string, param string) []string {
+	if key != nil {
+		return key
+	}
+	return splitParam(param)
+}
+
+// Validate checks the keys of every builder.
+func (p *ParsedURI) validate() error {
+	if p.Game != nil {
+		if err := p.Game.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.League != nil {
+		if err := p.League.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.Team != nil {
+		if err := p.Team.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.Player != nil {
+		if err := p.Player.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.Transaction != nil {
+		return p.Transaction.Validate()
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package fantasy
+
+import (
+	"testing"
+
+	"github.com/muswell/gotest"
+)
+
+func TestParseAPIURI(t *testing.T) {
+	tests := []struct {
+		uri string
+		// want are the expected paths of the game, league, team, player and transaction builders, empty when nil.
+		want [5]string
+	}{
+		{
+			"http://fantasysports.yahooapis.com/fantasy/v2/leagues;league_keys=357.l.86753/transactions",
+			[5]string{"", "leagues;league_keys=357.l.86753", "", "", "leagues;league_keys=357.l.86753/transactions"},
+		},
+		{
+			"/fantasy/v2/league/357.l.86753/metadata",
+			[5]string{"", "leagues;league_keys=357.l.86753", "", "", ""},
+		},
+		{
+			"league/357.l.86753/settings",
+			[5]string{"", "leagues;league_keys=357.l.86753;out=settings", "", "", ""},
+		},
+		{
+			"leagues;league_keys=357.l.86753;out=settings,standings/teams",
+			[5]string{"", "leagues;league_keys=357.l.86753;out=settings", "leagues;league_keys=357.l.86753;out=settings/teams", "", ""},
+		},
+		{
+			"league/357.l.86753/players;status=FA;position=OF;start=25;count=25",
+			[5]string{"", "leagues;league_keys=357.l.86753", "", "leagues;league_keys=357.l.86753/players;status=FA;position=OF;start=25;count=25", ""},
+		},
+		{
+			"team/357.l.86753.t.4/roster",
+			[5]string{"", "", "teams;team_keys=357.l.86753.t.4;out=roster", "", ""},
+		},
+		{
+			"league/357.l.86753/standings",
+			[5]string{"", "leagues;league_keys=357.l.86753", "leagues;league_keys=357.l.86753/teams;out=standings", "", ""},
+		},
+		{
+			"games;game_codes=mlb;seasons=2015",
+			[5]string{"games;game_codes=mlb;seasons=2015", "", "", "", ""},
+		},
+		{
+			"game/mlb/players;player_keys=357.p.8967",
+			[5]string{"games;game_keys=mlb", "", "", "games;game_keys=mlb/players;player_keys=357.p.8967", ""},
+		},
+		{
+			"https://fantasysports.yahooapis.com/fantasy/v2/users;use_login=1/games;game_keys=mlb/leagues/teams",
+			[5]string{"users;use_login=1/games;game_keys=mlb", "users;use_login=1/games;game_keys=mlb/leagues", "users;use_login=1/games;game_keys=mlb/leagues/teams", "", ""},
+		},
+		{
+			"transaction/357.l.86753.tr.12",
+			[5]string{"", "", "", "", "transactions;transaction_keys=357.l.86753.tr.12"},
+		},
+	}
+
+	for _, test := range tests {
+		p, err := ParseURI(nil, test.uri)
+		if err != nil {
+			t.Errorf("Unexpected ParseURI(%s) error: %v", test.uri, err)
+			continue
+		}
+
+		got := [5]string{}
+		if p.Game != nil {
+			got[0] = p.Game.Path()
+		}
+		if p.League != nil {
+			got[1] = p.League.Path()
+		}
+		if p.Team != nil {
+			got[2] = p.Team.Path()
+		}
+		if p.Player != nil {
+			got[3] = p.Player.Path()
+		}
+		if p.Transaction != nil {
+			got[4] = p.Transaction.Path()
+		}
+
+		if got != test.want {
+			t.Errorf("ParseURI(%s) paths = %q, want %q", test.uri, got, test.want)
+		}
+	}
+}
+
+func TestParseWebsiteURI(t *testing.T) {
+	tests := []struct {
+		uri                string
+		game, league, team string
+	}{
+		{"https://baseball.fantasysports.yahoo.com/b1/86753", "mlb", "mlb.l.86753", ""},
+		{"https://baseball.fantasysports.yahoo.com/b1/86753/4", "mlb", "mlb.l.86753", "mlb.l.86753.t.4"},
+		{"https://football.fantasysports.yahoo.com/f1/12345/2/team", "nfl", "nfl.l.12345", "nfl.l.12345.t.2"},
+		{"https://basketball.fantasysports.yahoo.com/nba/555/players", "nba", "nba.l.555", ""},
+		{"hockey.fantasysports.yahoo.com/hockey/777", "", "", ""},
+		{"https://hockey.fantasysports.yahoo.com/hockey/777", "nhl", "nhl.l.777", ""},
+	}
+
+	for _, test := range tests {
+		p, err := ParseURI(nil, test.uri)
+		if test.game == "" {
+			// without a scheme the host is read as part of an api path.
+			if err == nil {
+				t.Errorf("Expected a ParseURI(%s) error", test.uri)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected ParseURI(%s) error: %v", test.uri, err)
+			continue
+		}
+
+		if p.Game.Keys[0] != test.game || p.League.Keys[0] != test.league {
+			t.Errorf("ParseURI(%s) = %v %v, want %s %s", test.uri, p.Game.Keys, p.League.Keys, test.game, test.league)
+		}
+		if (p.Team == nil) != (test.team == "") || (p.Team != nil && p.Team.Keys[0] != test.team) {
+			t.Errorf("ParseURI(%s) team = %v, want %s", test.uri, p.Team, test.team)
+		}
+	}
+}
+
+func TestParseWebsiteURISeason(t *testing.T) {
+	uri := "https://baseball.fantasysports.yahoo.com/2015/b1/86753"
+
+	if _, err := ParseURI(nil, uri); err == nil {
+		t.Error("Expected an error resolving a past season without a client")
+	}
+
+	q := GameQueryBuilder{Codes: []string{"mlb"}, Seasons: []int64{2015}}
+	client := gotest.NewRegisteredClient()
+	client.Register(q.Url(), "get", gotest.NewSimpleRoundTrip([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<fantasy_content xmlns:yahoo="http://www.yahooapis.com/v1/base.rng" xmlns="http://fantasysports.yahooapis.com/fantasy/v2/base.rng">
+  <games count="1"><game><game_key>346</game_key><game_id>346</game_id><name>Baseball</name><code>mlb</code><season>2015</season></game></games>
+</fantasy_content>`), nil))
+
+	p, err := ParseURI(client.Client, uri)
+	if err != nil {
+		t.Fatalf("Unexpected ParseURI error: %v", err)
+	}
+	if p.League.Keys[0] != "346.l.86753" || p.Game.Keys[0] != "346" {
+		t.Errorf("ParseURI resolved %v %v, want 346.l.86753", p.Game.Keys, p.League.Keys)
+	}
+}
+
+func TestParseURIErrors(t *testing.T) {
+	uris := []string{
+		"",
+		"leagues;league_keys=abc",
+		"league",
+		"league/357.l.86753/widgets",
+		"https://baseball.fantasysports.yahoo.com/b1",
+		"https://baseball.fantasysports.yahoo.com/x9/86753",
+		"https://baseball.fantasysports.yahoo.com/b1/league",
+	}
+
+	for _, uri := range uris {
+		if _, err := ParseURI(nil, uri); err == nil {
+			t.Errorf("Expected a ParseURI(%q) error", uri)
+		}
+	}
+}
@@ -3,7 +3,6 @@ package fantasy
 import (
 	"encoding/xml"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"regexp"
 	"strings"
@@ -22,6 +21,8 @@ type User struct {
 type UserQueryBuilder struct {
 	ActiveUser bool
 	GameQB     *GameQueryBuilder
+	// Format is the response format to request, xml by default.
+	Format Format
 }
 
 //Path returns the yahoo api path for the query excluding the host and query string.
@@ -40,12 +41,12 @@ func (q *UserQueryBuilder) Path() string {
 
 // Url returns the api url that the query builder fields create.
 func (q *UserQueryBuilder) Url() string {
-	return baseUrl + q.Path() + "?format=xml"
+	return baseUrl + q.Path() + "?format=" + q.Format.String()
 }
 
-// XmlUserParser must be able to parse a byte slice of xml data and return a slice of Users.
+// XmlUserParser must be able to decode an xml token stream and return a slice of Users.
 type xmlUserParser interface {
-	parseXML([]byte) ([]User, error)
+	parseXML(*xml.Decoder) ([]User, ResponseMeta, error)
 }
 
 // DefaultXMLUserParser parses xml with a users node as a direct child of the fantasy_content node.
@@ -53,18 +54,19 @@ type defaultXMLUserParser struct {
 	result struct {
 		// XMLName fantasy_content is the main wrapper tag in a Yahoo api response.
 		XMLName xml.Name `xml:"fantasy_content"`
+		responseAttrs
 		//Users is where a UserBuilderQuery stores its results.
 		Users []User `xml:"users>user"`
 	}
 }
 
 // ParseXML actually does the transformation from xml to Game slice.
-func (p defaultXMLUserParser) parseXML(data []byte) ([]User, error) {
-	err := xml.Unmarshal(data, &p.result)
+func (p defaultXMLUserParser) parseXML(d *xml.Decoder) ([]User, ResponseMeta, error) {
+	err := d.Decode(&p.result)
 	if err != nil {
-		return []User{}, err
+		return []User{}, ResponseMeta{}, err
 	}
-	return p.result.Users, nil
+	return p.result.Users, p.result.meta(), nil
 }
 
 // XmlParser returns the appropriate xml parser based on the query builder settings.
@@ -74,20 +76,30 @@ func (qb *UserQueryBuilder) xmlParser() xmlUserParser {
 
 // Get formats the appropriate api url to query and unmarshals the response into a slice of users.
 func (q *UserQueryBuilder) Get(client *http.Client) ([]User, error) {
-	url := q.Url()
+	users, _, err := q.GetWithMeta(client)
+	return users, err
+}
 
-	resp, err := client.Get(url)
-	if err != nil {
-		return []User{}, err
+// Validate checks the keys of the nested game query builder.
+func (q *UserQueryBuilder) Validate() error {
+	if q.GameQB != nil {
+		return q.GameQB.Validate()
+	}
+	return nil
+}
+
+// GetWithMeta works like Get but also returns the metadata of the response.
+func (q *UserQueryBuilder) GetWithMeta(client *http.Client) ([]User, ResponseMeta, error) {
+	if err := q.Validate(); err != nil {
+		return []User{}, ResponseMeta{}, err
 	}
-	defer resp.Body.Close()
 
-	data, err := ioutil.ReadAll(resp.Body)
+	d, err := fetch(client, q.Url(), q.Format)
 	if err != nil {
-		return []User{}, err
+		return []User{}, ResponseMeta{}, err
 	}
 
-	return q.xmlParser().parseXML(data)
+	return q.xmlParser().parseXML(d)
 }
 
 // ActiveUser takes an ouath ready http.client and returns a User object representing the authorized user.
@@ -0,0 +1,419 @@
+package fantasy
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// WriteScope is the OAuth scope Yahoo requires for every WriteRequest.
+const WriteScope = "fspt-w"
+
+// ScopeReporter is implemented by http.RoundTrippers which know the OAuth scopes of their token.
+// Send checks the scopes of a client's transport before any write request is sent,
+// an empty list means the scopes are unknown and the request is sent anyway.
+type ScopeReporter interface {
+	Scopes() []string
+}
+
+// ScopeError is returned by Send when the client's token was not granted WriteScope.
+type ScopeError struct {
+	Scopes []string
+}
+
+func (e *ScopeError) Error() string {
+	return fmt.Sprintf("Write requests need the %s scope, the token was granted %s", WriteScope, strings.Join(e.Scopes, " "))
+}
+
+// APIError is returned when Yahoo rejects a request, Description is Yahoo's explanation.
+type APIError struct {
+	StatusCode  int
+	Description string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Yahoo fantasy api error %d: %s", e.StatusCode, e.Description)
+}
+
+// WriteRequest is a change to fantasy data such as a roster edit or a transaction.
+type WriteRequest interface {
+	// Method is the http method of the request, PUT or POST.
+	Method() string
+	// Url is the api url the request is sent to.
+	Url() string
+	// Body returns the xml request body, it errors when the request is incomplete.
+	Body() ([]byte, error)
+}
+
+// WriteResult is the response to a WriteRequest.
+type WriteResult struct {
+	// Transaction is the transaction created or changed, it is nil for roster edits.
+	Transaction *Transaction
+	// Meta is the metadata of the response.
+	Meta ResponseMeta
+}
+
+// Send sends a write request with client.
+// It fails with a *ScopeError before sending anything when the client's transport reports scopes without WriteScope.
+func Send(client *http.Client, w WriteRequest) (*WriteResult, error) {
+	if err := checkWriteScope(client); err != nil {
+		return nil, err
+	}
+
+	body, err := w.Body()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(w.Method(), w.Url(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/xml")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 300 {
+		return nil, newAPIError(resp.StatusCode, data)
+	}
+
+	var result struct {
+		XMLName xml.Name `xml:"fantasy_content"`
+		responseAttrs
+		Transaction *Transaction `xml:"transaction"`
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := xml.Unmarshal(data, &result); err != nil {
+			return nil, err
+		}
+	}
+
+	return &WriteResult{Transaction: result.Transaction, Meta: result.meta()}, nil
+}
+
+// CheckWriteScope returns a *ScopeError when the client's transport reports scopes without WriteScope.
+func checkWriteScope(client *http.Client) error {
+	r, ok := client.Transport.(ScopeReporter)
+	if !ok {
+		return nil
+	}
+
+	scopes := r.Scopes()
+	if len(scopes) == 0 {
+		return nil
+	}
+	for _, s := range scopes {
+		if s == WriteScope {
+			return nil
+		}
+	}
+	return &ScopeError{Scopes: scopes}
+}
+
+// NewAPIError reads the description from a Yahoo error response.
+func newAPIError(status int, data []byte) error {
+	var e struct {
+		Description string `xml:"description"`
+	}
+	if err := xml.Unmarshal(data, &e); err != nil || e.Description == "" {
+		e.Description = strings.TrimSpace(string(data))
+	}
+	return &APIError{StatusCode: status, Description: e.Description}
+}
+
+// MarshalBody wraps v in a fantasy_content node and encodes it with an xml header.
+func marshalBody(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(struct {
+		XMLName xml.Name `xml:"fantasy_content"`
+		Content interface{}
+	}{Content: v}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// ValidLeagueTeams checks the league and team keys and that every team belongs to the league.
+func validLeagueTeams(leagueKey string, teamKeys ...string) error {
+	league, err := ParseLeagueKey(leagueKey)
+	if err != nil {
+		return err
+	}
+
+	for _, k := range teamKeys {
+		team, err := ParseTeamKey(k)
+		if err != nil {
+			return err
+		}
+		if team.LeagueKey() != league {
+			return fmt.Errorf("Team %s is not in league %s", k, leagueKey)
+		}
+	}
+	return nil
+}
+
+// RosterPosition places a player in a roster position.
+type RosterPosition struct {
+	PlayerKey string `xml:"player_key"`
+	// Position is the roster position e.g. 1B, OF, BN or DL.
+	Position string `xml:"position"`
+}
+
+// RosterEdit changes the positions of players on a team's roster.
+// Set Week in leagues with weekly lineups and Date (yyyy-mm-dd) in leagues with daily lineups.
+type RosterEdit struct {
+	TeamKey string
+	Week    int
+	Date    string
+	Players []RosterPosition
+}
+
+// Method returns PUT.
+func (r *RosterEdit) Method() string {
+	return "PUT"
+}
+
+// Url returns the roster url of the team.
+func (r *RosterEdit) Url() string {
+	return baseUrl + "team/" + r.TeamKey + "/roster"
+}
+
+// Body returns the roster xml.
+func (r *RosterEdit) Body() ([]byte, error) {
+	if r.TeamKey == "" {
+		return nil, errors.New("RosterEdit requires a TeamKey")
+	}
+	if len(r.Players) == 0 {
+		return nil, errors.New("RosterEdit requires at least one player")
+	}
+	if (r.Week == 0) == (r.Date == "") {
+		return nil, errors.New("RosterEdit requires either a Week or a Date")
+	}
+	if err := validTeamKey(r.TeamKey); err != nil {
+		return nil, err
+	}
+	for _, p := range r.Players {
+		if err := validPlayerKey(p.PlayerKey); err != nil {
+			return nil, err
+		}
+	}
+
+	roster := struct {
+		XMLName      xml.Name         `xml:"roster"`
+		CoverageType string           `xml:"coverage_type"`
+		Week         int              `xml:"week,omitempty"`
+		Date         string           `xml:"date,omitempty"`
+		Players      []RosterPosition `xml:"players>player"`
+	}{CoverageType: "week", Week: r.Week, Date: r.Date, Players: r.Players}
+	if r.Date != "" {
+		roster.CoverageType = "date"
+	}
+
+	return marshalBody(roster)
+}
+
+// transactionPlayer is a player in a transaction request body.
+type transactionPlayer struct {
+	PlayerKey       string              `xml:"player_key"`
+	TransactionData transactionDataBody `xml:"transaction_data"`
+}
+
+// transactionDataBody is the transaction_data of a player in a request body.
+type transactionDataBody struct {
+	Type               string `xml:"type"`
+	SourceTeamKey      string `xml:"source_team_key,omitempty"`
+	DestinationTeamKey string `xml:"destination_team_key,omitempty"`
+}
+
+// transactionBody is the transaction node of a transaction request body.
+type transactionBody struct {
+	XMLName        xml.Name            `xml:"transaction"`
+	TransactionKey string              `xml:"transaction_key,omitempty"`
+	Type           string              `xml:"type"`
+	Action         string              `xml:"action,omitempty"`
+	FAABBid        *int                `xml:"faab_bid,omitempty"`
+	TraderTeamKey  string              `xml:"trader_team_key,omitempty"`
+	TradeeTeamKey  string              `xml:"tradee_team_key,omitempty"`
+	TradeNote      string              `xml:"trade_note,omitempty"`
+	Player         *transactionPlayer  `xml:"player,omitempty"`
+	Players        []transactionPlayer `xml:"players>player,omitempty"`
+}
+
+// AddDrop adds a player to a team, drops one, or both at once.
+// When the added player is on waivers the transaction is a waiver claim,
+// set FAABBid to bid on it in leagues which use FAAB.
+type AddDrop struct {
+	LeagueKey     string
+	TeamKey       string
+	AddPlayerKey  string
+	DropPlayerKey string
+	FAABBid       *int
+}
+
+// Method returns POST.
+func (a *AddDrop) Method() string {
+	return "POST"
+}
+
+// Url returns the transactions url of the league.
+func (a *AddDrop) Url() string {
+	return baseUrl + "league/" + a.LeagueKey + "/transactions"
+}
+
+// Type returns add, drop or add/drop.
+func (a *AddDrop) Type() string {
+	switch {
+	case a.AddPlayerKey != "" && a.DropPlayerKey != "":
+		return "add/drop"
+	case a.DropPlayerKey != "":
+		return "drop"
+	}
+	return "add"
+}
+
+// Body returns the transaction xml.
+func (a *AddDrop) Body() ([]byte, error) {
+	if a.LeagueKey == "" || a.TeamKey == "" {
+		return nil, errors.New("AddDrop requires a LeagueKey and a TeamKey")
+	}
+	if a.AddPlayerKey == "" && a.DropPlayerKey == "" {
+		return nil, errors.New("AddDrop requires a player to add or drop")
+	}
+	if a.FAABBid != nil && a.AddPlayerKey == "" {
+		return nil, errors.New("AddDrop can only bid FAAB on an added player")
+	}
+	if err := validLeagueTeams(a.LeagueKey, a.TeamKey); err != nil {
+		return nil, err
+	}
+	for _, key := range []string{a.AddPlayerKey, a.DropPlayerKey} {
+		if key == "" {
+			continue
+		}
+		if err := validPlayerKey(key); err != nil {
+			return nil, err
+		}
+	}
+
+	add := transactionPlayer{PlayerKey: a.AddPlayerKey, TransactionData: transactionDataBody{Type: "add", DestinationTeamKey: a.TeamKey}}
+	drop := transactionPlayer{PlayerKey: a.DropPlayerKey, TransactionData: transactionDataBody{Type: "drop", SourceTeamKey: a.TeamKey}}
+
+	t := transactionBody{Type: a.Type(), FAABBid: a.FAABBid}
+	switch t.Type {
+	case "add":
+		t.Player = &add
+	case "drop":
+		t.Player = &drop
+	default:
+		t.Players = []transactionPlayer{add, drop}
+	}
+
+	return marshalBody(t)
+}
+
+// TradeProposal proposes a trade between two teams of a league.
+type TradeProposal struct {
+	LeagueKey     string
+	TraderTeamKey string
+	TradeeTeamKey string
+	// TraderPlayerKeys are the players the trader gives up.
+	TraderPlayerKeys []string
+	// TradeePlayerKeys are the players the trader receives.
+	TradeePlayerKeys []string
+	// Note is an optional message to the tradee.
+	Note string
+}
+
+// Method returns POST.
+func (p *TradeProposal) Method() string {
+	return "POST"
+}
+
+// Url returns the transactions url of the league.
+func (p *TradeProposal) Url() string {
+	return baseUrl + "league/" + p.LeagueKey + "/transactions"
+}
+
+// Body returns the pending trade xml.
+func (p *TradeProposal) Body() ([]byte, error) {
+	if p.LeagueKey == "" || p.TraderTeamKey == "" || p.TradeeTeamKey == "" {
+		return nil, errors.New("TradeProposal requires a LeagueKey, TraderTeamKey and TradeeTeamKey")
+	}
+	if len(p.TraderPlayerKeys) == 0 || len(p.TradeePlayerKeys) == 0 {
+		return nil, errors.New("TradeProposal requires players from both teams")
+	}
+	if err := validLeagueTeams(p.LeagueKey, p.TraderTeamKey, p.TradeeTeamKey); err != nil {
+		return nil, err
+	}
+	if err := validateKeys(append(append([]string{}, p.TraderPlayerKeys...), p.TradeePlayerKeys...), validPlayerKey); err != nil {
+		return nil, err
+	}
+
+	t := transactionBody{Type: "pending_trade", TraderTeamKey: p.TraderTeamKey, TradeeTeamKey: p.TradeeTeamKey, TradeNote: p.Note}
+	for _, key := range p.TraderPlayerKeys {
+		t.Players = append(t.Players, transactionPlayer{PlayerKey: key, TransactionData: transactionDataBody{Type: "pending_trade", SourceTeamKey: p.TraderTeamKey, DestinationTeamKey: p.TradeeTeamKey}})
+	}
+	for _, key := range p.TradeePlayerKeys {
+		t.Players = append(t.Players, transactionPlayer{PlayerKey: key, TransactionData: transactionDataBody{Type: "pending_trade", SourceTeamKey: p.TradeeTeamKey, DestinationTeamKey: p.TraderTeamKey}})
+	}
+
+	return marshalBody(t)
+}
+
+// Trade response actions.
+const (
+	// TradeAccept and TradeReject are used by the tradee.
+	TradeAccept = "accept"
+	TradeReject = "reject"
+	// TradeAllow and TradeDisallow are used by the commissioner in leagues where trades are ratified.
+	TradeAllow    = "allow"
+	TradeDisallow = "disallow"
+	// TradeVoteAgainst is used by other managers in leagues where trades are voted on.
+	TradeVoteAgainst = "vote_against"
+)
+
+// TradeResponse accepts, rejects or ratifies a pending trade.
+type TradeResponse struct {
+	TransactionKey string
+	// Action is one of TradeAccept, TradeReject, TradeAllow, TradeDisallow or TradeVoteAgainst.
+	Action string
+	// Note is an optional message to the trader.
+	Note string
+}
+
+// Method returns PUT.
+func (r *TradeResponse) Method() string {
+	return "PUT"
+}
+
+// Url returns the url of the transaction.
+func (r *TradeResponse) Url() string {
+	return baseUrl + "transaction/" + r.TransactionKey
+}
+
+// Body returns the trade response xml.
+func (r *TradeResponse) Body() ([]byte, error) {
+	if r.TransactionKey == "" {
+		return nil, errors.New("TradeResponse requires a TransactionKey")
+	}
+
+	switch r.Action {
+	case TradeAccept, TradeReject, TradeAllow, TradeDisallow, TradeVoteAgainst:
+	default:
+		return nil, fmt.Errorf("Unknown TradeResponse action %q", r.Action)
+	}
+
+	return marshalBody(transactionBody{TransactionKey: r.TransactionKey, Type: "pending_trade", Action: r.Action, TradeNote: r.Note})
+}
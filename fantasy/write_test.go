@@ -0,0 +1,177 @@
+package fantasy
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/muswell/gotest"
+)
+
+// recordingRoundTrip saves the body of the request it receives and responds with a fixed status and body.
+type recordingRoundTrip struct {
+	status int
+	body   string
+	got    []byte
+}
+
+func (r *recordingRoundTrip) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.got, _ = ioutil.ReadAll(req.Body)
+	return &http.Response{StatusCode: r.status, Body: ioutil.NopCloser(strings.NewReader(r.body)), Header: http.Header{}, Request: req}, nil
+}
+
+// scopedTransport reports fixed scopes for a RegisteredClient.
+type scopedTransport struct {
+	*gotest.RegisteredClient
+	scopes []string
+}
+
+func (s scopedTransport) Scopes() []string {
+	return s.scopes
+}
+
+func TestWriteRequestBodies(t *testing.T) {
+	bid := 7
+	tests := []struct {
+		input  WriteRequest
+		method string
+		url    string
+		body   []string
+	}{
+		{
+			&RosterEdit{TeamKey: "357.l.86753.t.1", Date: "2016-04-10", Players: []RosterPosition{{"357.p.9105", "OF"}, {"357.p.8658", "BN"}}},
+			"PUT", baseUrl + "team/357.l.86753.t.1/roster",
+			[]string{"<roster>", "<coverage_type>date</coverage_type>", "<date>2016-04-10</date>", "<player_key>357.p.9105</player_key>", "<position>BN</position>"},
+		},
+		{
+			&RosterEdit{TeamKey: "357.l.86753.t.1", Week: 3, Players: []RosterPosition{{"357.p.9105", "OF"}}},
+			"PUT", baseUrl + "team/357.l.86753.t.1/roster",
+			[]string{"<coverage_type>week</coverage_type>", "<week>3</week>"},
+		},
+		{
+			&AddDrop{LeagueKey: "357.l.86753", TeamKey: "357.l.86753.t.1", AddPlayerKey: "357.p.9105"},
+			"POST", baseUrl + "league/357.l.86753/transactions",
+			[]string{"<type>add</type>", "<player>", "<destination_team_key>357.l.86753.t.1</destination_team_key>"},
+		},
+		{
+			&AddDrop{LeagueKey: "357.l.86753", TeamKey: "357.l.86753.t.1", DropPlayerKey: "357.p.8658"},
+			"POST", baseUrl + "league/357.l.86753/transactions",
+			[]string{"<type>drop</type>", "<source_team_key>357.l.86753.t.1</source_team_key>"},
+		},
+		{
+			&AddDrop{LeagueKey: "357.l.86753", TeamKey: "357.l.86753.t.1", AddPlayerKey: "357.p.9105", DropPlayerKey: "357.p.8658", FAABBid: &bid},
+			"POST", baseUrl + "league/357.l.86753/transactions",
+			[]string{"<type>add/drop</type>", "<faab_bid>7</faab_bid>", "<players>", "<player_key>357.p.8658</player_key>"},
+		},
+		{
+			&TradeProposal{LeagueKey: "357.l.86753", TraderTeamKey: "357.l.86753.t.1", TradeeTeamKey: "357.l.86753.t.2", TraderPlayerKeys: []string{"357.p.9105"}, TradeePlayerKeys: []string{"357.p.8967"}, Note: "Fair?"},
+			"POST", baseUrl + "league/357.l.86753/transactions",
+			[]string{"<type>pending_trade</type>", "<trader_team_key>357.l.86753.t.1</trader_team_key>", "<tradee_team_key>357.l.86753.t.2</tradee_team_key>", "<trade_note>Fair?</trade_note>"},
+		},
+		{
+			&TradeResponse{TransactionKey: "357.l.86753.pt.1", Action: TradeAccept},
+			"PUT", baseUrl + "transaction/357.l.86753.pt.1",
+			[]string{"<transaction_key>357.l.86753.pt.1</transaction_key>", "<action>accept</action>"},
+		},
+	}
+
+	for _, test := range tests {
+		if test.input.Method() != test.method || test.input.Url() != test.url {
+			t.Errorf("%T sends %s %s, expected %s %s", test.input, test.input.Method(), test.input.Url(), test.method, test.url)
+		}
+
+		body, err := test.input.Body()
+		if err != nil {
+			t.Errorf("Unexpected %T.Body error: %v", test.input, err)
+			continue
+		}
+		if !bytes.HasPrefix(body, []byte("<?xml")) || !bytes.Contains(body, []byte("<fantasy_content>")) {
+			t.Errorf("%T body is not a fantasy_content document:\n%s", test.input, body)
+		}
+		for _, b := range test.body {
+			if !bytes.Contains(body, []byte(b)) {
+				t.Errorf("%T body does not contain %s:\n%s", test.input, b, body)
+			}
+		}
+	}
+}
+
+func TestWriteRequestErrors(t *testing.T) {
+	bid := 1
+	requests := []WriteRequest{
+		&RosterEdit{Week: 1, Players: []RosterPosition{{"357.p.9105", "OF"}}},
+		&RosterEdit{TeamKey: "357.l.86753.t.1", Week: 1},
+		&RosterEdit{TeamKey: "357.l.86753.t.1", Players: []RosterPosition{{"357.p.9105", "OF"}}},
+		&AddDrop{LeagueKey: "357.l.86753", TeamKey: "357.l.86753.t.1"},
+		&AddDrop{LeagueKey: "357.l.86753", TeamKey: "357.l.86753.t.1", DropPlayerKey: "357.p.8658", FAABBid: &bid},
+		&TradeProposal{LeagueKey: "357.l.86753", TraderTeamKey: "357.l.86753.t.1", TradeeTeamKey: "357.l.86753.t.2"},
+		&TradeResponse{TransactionKey: "357.l.86753.pt.1", Action: "maybe"},
+		&RosterEdit{TeamKey: "357.l.86753", Week: 1, Players: []RosterPosition{{"357.p.9105", "OF"}}},
+		&AddDrop{LeagueKey: "357.l.86753", TeamKey: "357.l.1.t.1", AddPlayerKey: "357.p.9105"},
+		&AddDrop{LeagueKey: "357.l.86753", TeamKey: "357.l.86753.t.1", AddPlayerKey: "9105"},
+		&TradeProposal{LeagueKey: "357.l.86753", TraderTeamKey: "357.l.86753.t.1", TradeeTeamKey: "357.l.86753.t.2", TraderPlayerKeys: []string{"357.p.9105"}, TradeePlayerKeys: []string{"p"}},
+	}
+
+	for _, r := range requests {
+		if _, err := r.Body(); err == nil {
+			t.Errorf("Expected a %T.Body error for %+v", r, r)
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	add := &AddDrop{LeagueKey: "357.l.86753", TeamKey: "357.l.86753.t.1", AddPlayerKey: "357.p.9105"}
+	rt := &recordingRoundTrip{status: http.StatusCreated, body: `<?xml version="1.0" encoding="UTF-8"?>
+<fantasy_content yahoo:uri="http://fantasysports.yahooapis.com/fantasy/v2/league/357.l.86753/transactions" xmlns:yahoo="http://www.yahooapis.com/v1/base.rng" xmlns="http://fantasysports.yahooapis.com/fantasy/v2/base.rng">
+  <transaction><transaction_key>357.l.86753.tr.13</transaction_key><type>add</type><status>successful</status></transaction>
+</fantasy_content>`}
+
+	client := gotest.NewRegisteredClient()
+	client.Register(add.Url(), "post", rt)
+
+	result, err := Send(client.Client, add)
+	if err != nil {
+		t.Fatalf("Unexpected Send error: %v", err)
+	}
+	if result.Transaction == nil || result.Transaction.Key != "357.l.86753.tr.13" {
+		t.Errorf("Send returned incorrect transaction %+v", result.Transaction)
+	}
+	if want, _ := add.Body(); !bytes.Equal(rt.got, want) {
+		t.Errorf("Send sent body %s, expected %s", rt.got, want)
+	}
+
+	// Yahoo's error description is returned.
+	rt.status = http.StatusBadRequest
+	rt.body = `<?xml version="1.0" encoding="UTF-8"?>
+<error xml:lang="en-us" xmlns="http://www.yahooapis.com/v1/base.rng"><description>You cannot add a player you already have.</description><detail/></error>`
+	_, err = Send(client.Client, add)
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.StatusCode != http.StatusBadRequest || apiErr.Description != "You cannot add a player you already have." {
+		t.Errorf("Expected an APIError got %v", err)
+	}
+}
+
+func TestSendScope(t *testing.T) {
+	add := &AddDrop{LeagueKey: "357.l.86753", TeamKey: "357.l.86753.t.1", AddPlayerKey: "357.p.9105"}
+	rt := &recordingRoundTrip{status: http.StatusOK}
+
+	registered := gotest.NewRegisteredClient()
+	registered.Register(add.Url(), "post", rt)
+
+	client := &http.Client{Transport: scopedTransport{registered, []string{"fspt-r", "openid"}}}
+	if _, err := Send(client, add); err == nil {
+		t.Fatal("Expected a ScopeError for a read only token")
+	} else if _, ok := err.(*ScopeError); !ok {
+		t.Errorf("Expected a ScopeError got %v", err)
+	}
+	if rt.got != nil {
+		t.Error("Send sent a request without the write scope")
+	}
+
+	client.Transport = scopedTransport{registered, []string{WriteScope}}
+	if _, err := Send(client, add); err != nil {
+		t.Errorf("Unexpected Send error with the write scope: %v", err)
+	}
+}
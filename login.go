@@ -0,0 +1,269 @@
+package yahoo
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrStateMismatch is returned when the context ends after the only authorization callbacks carried a different
+// state than was sent, which means they were not the result of this login.
+var ErrStateMismatch = errors.New("The authorization callback state does not match the request")
+
+// LocalLogin runs the authorization code flow for installed applications by listening for the
+// authorization callback on a temporary local server, so users don't have to copy and paste a code.
+// When the listener can not be started it falls back to the oob flow.
+//
+// Yahoo only redirects to a registered redirect url. When the config's RedirectURL is a loopback url
+// such as https://localhost:8443/callback its host, port and path are used for the listener,
+// otherwise the listener binds a random port on 127.0.0.1.
+type LocalLogin struct {
+	// Addr overrides the address the listener binds e.g. 127.0.0.1:8080.
+	Addr string
+	// TLSConfig serves the callback over https when set, it must contain a certificate.
+	TLSConfig *tls.Config
+	// Open is called with the authorization url, OpenBrowser is a good choice.
+	// When Open is nil or fails the url is printed to Out instead.
+	Open func(url string) error
+	// Out receives instructions for the user, os.Stdout when nil.
+	Out io.Writer
+	// In is read for the code during the oob flow, os.Stdin when nil.
+	In io.Reader
+	// OOB skips the local listener and always uses the oob flow.
+	OOB bool
+	// PKCE protects the code exchange with a proof key, public clients should set it instead of using a client secret.
+	PKCE bool
+}
+
+// Login authorizes the user and exchanges the resulting code for a token.
+// The context bounds the whole flow including the wait for the user.
+// Requests to the callback without the state of this login are rejected and the wait goes on.
+func (l *LocalLogin) Login(ctx context.Context, conf *oauth2.Config, opts ...oauth2.AuthCodeOption) (*oauth2.Token, error) {
+	authOpts, exchangeOpts := opts, opts
+	if l.PKCE {
+		p, err := NewPKCE()
+		if err != nil {
+			return nil, err
+		}
+		authOpts = append(p.AuthCodeOptions(), opts...)
+		exchangeOpts = append(p.ExchangeOptions(), opts...)
+	}
+
+	if l.OOB {
+		return l.oob(ctx, conf, authOpts, exchangeOpts)
+	}
+
+	redirect, err := l.redirectURL(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	ln, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		fmt.Fprintf(l.out(), "Could not start the local callback listener (%v), falling back to copy and paste.\n", err)
+		return l.oob(ctx, conf, authOpts, exchangeOpts)
+	}
+
+	// the listener may have been given a random port.
+	if redirect.Port() == "0" {
+		_, port, _ := net.SplitHostPort(ln.Addr().String())
+		redirect.Host = net.JoinHostPort(redirect.Hostname(), port)
+	}
+	if l.TLSConfig != nil {
+		ln = tls.NewListener(ln, l.TLSConfig)
+	}
+
+	c := *conf
+	c.RedirectURL = redirect.String()
+
+	state, err := randomString(24)
+	if err != nil {
+		ln.Close()
+		return nil, err
+	}
+
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+
+	// mismatched records a callback with the wrong state, e.g. a prefetch or a stray local request.
+	// It is answered with an error but the login keeps waiting for the real callback.
+	mismatched := make(chan struct{}, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(redirect.Path, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("state") != state {
+			select {
+			case mismatched <- struct{}{}:
+			default:
+			}
+			http.Error(w, ErrStateMismatch.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var res result
+		switch {
+		case q.Get("error") != "":
+			res.err = fmt.Errorf("Authorization failed: %s %s", q.Get("error"), q.Get("error_description"))
+		case q.Get("code") == "":
+			res.err = errors.New("The authorization callback did not contain a code")
+		default:
+			res.code = q.Get("code")
+		}
+
+		if res.err != nil {
+			http.Error(w, res.err.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "Login complete, you may close this window.")
+		}
+
+		select {
+		case results <- res:
+		default:
+		}
+	})
+
+	server := &http.Server{Handler: mux}
+	go server.Serve(ln)
+	defer server.Close()
+
+	l.open(c.AuthCodeURL(state, authOpts...))
+
+	select {
+	case res := <-results:
+		if res.err != nil {
+			return nil, res.err
+		}
+		return c.Exchange(ctx, res.code, exchangeOpts...)
+	case <-ctx.Done():
+		select {
+		case <-mismatched:
+			return nil, ErrStateMismatch
+		default:
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// Oob runs the copy and paste flow, the user is shown the authorization url and types in the code Yahoo displays.
+func (l *LocalLogin) oob(ctx context.Context, conf *oauth2.Config, authOpts, exchangeOpts []oauth2.AuthCodeOption) (*oauth2.Token, error) {
+	c := *conf
+	c.RedirectURL = "oob"
+
+	state, err := randomString(24)
+	if err != nil {
+		return nil, err
+	}
+
+	l.open(c.AuthCodeURL(state, authOpts...))
+	fmt.Fprint(l.out(), "Enter the code shown by Yahoo: ")
+
+	in := l.In
+	if in == nil {
+		in = os.Stdin
+	}
+
+	code, err := bufio.NewReader(in).ReadString('\n')
+	code = strings.TrimSpace(code)
+	if code == "" {
+		if err == nil || err == io.EOF {
+			err = errors.New("No authorization code was entered")
+		}
+		return nil, err
+	}
+
+	return c.Exchange(ctx, code, exchangeOpts...)
+}
+
+// RedirectURL returns the callback url the listener should serve.
+func (l *LocalLogin) redirectURL(conf *oauth2.Config) (*url.URL, error) {
+	scheme := "http"
+	if l.TLSConfig != nil {
+		scheme = "https"
+	}
+
+	u, err := url.Parse(conf.RedirectURL)
+	if err != nil || !isLoopback(u.Hostname()) {
+		u = &url.URL{Scheme: scheme, Host: "127.0.0.1:0", Path: "/callback"}
+	}
+
+	if u.Scheme == "https" && l.TLSConfig == nil {
+		return nil, fmt.Errorf("The redirect url %s uses https but LocalLogin has no TLSConfig", conf.RedirectURL)
+	}
+
+	if u.Port() == "" {
+		u.Host = net.JoinHostPort(u.Hostname(), "0")
+	}
+
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	if l.Addr != "" {
+		u.Host = l.Addr
+	}
+	return u, nil
+}
+
+// Open shows the authorization url to the user.
+func (l *LocalLogin) open(authURL string) {
+	if l.Open != nil && l.Open(authURL) == nil {
+		fmt.Fprintln(l.out(), "Your browser has been opened to authorize the application.")
+		return
+	}
+	fmt.Fprintf(l.out(), "Visit this url to authorize the application:\n\n%s\n\n", authURL)
+}
+
+// Out returns the writer for user instructions.
+func (l *LocalLogin) out() io.Writer {
+	if l.Out == nil {
+		return os.Stdout
+	}
+	return l.Out
+}
+
+// IsLoopback determines if host refers to the local machine.
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// RandomString returns a url safe random string made from n random bytes.
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// OpenBrowser opens url in the user's default browser.
+func OpenBrowser(url string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	}
+	return exec.Command("xdg-open", url).Start()
+}
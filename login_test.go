@@ -0,0 +1,245 @@
+package yahoo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// fakeAuthServer is a stand in for Yahoo's authorization and token endpoints.
+// The authorization endpoint immediately redirects back with a code, as if the user had approved.
+type fakeAuthServer struct {
+	*httptest.Server
+	// redirects are the redirect_uri values received by the token endpoint.
+	redirects []string
+	// callbackState overrides the state sent back to the callback.
+	callbackState string
+	// challenge is the PKCE code challenge sent to the authorization endpoint, the token endpoint verifies it.
+	challenge string
+	// clientSecrets are the client_secret values received by the token endpoint.
+	clientSecrets []string
+}
+
+func newFakeAuthServer() *fakeAuthServer {
+	f := &fakeAuthServer{}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/auth", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("code_challenge_method") == "S256" {
+			f.challenge = q.Get("code_challenge")
+		}
+
+		state := q.Get("state")
+		if f.callbackState != "" {
+			state = f.callbackState
+		}
+
+		cb, _ := url.Parse(q.Get("redirect_uri"))
+		cb.RawQuery = url.Values{"code": {"good-code"}, "state": {state}}.Encode()
+		http.Redirect(w, r, cb.String(), http.StatusFound)
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		f.redirects = append(f.redirects, r.Form.Get("redirect_uri"))
+		f.clientSecrets = append(f.clientSecrets, r.Form.Get("client_secret"))
+
+		verified := true
+		if f.challenge != "" {
+			p := PKCE{Verifier: r.Form.Get("code_verifier")}
+			verified = p.Challenge() == f.challenge
+		}
+
+		if r.Form.Get("code") != "good-code" || !verified {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"invalid_grant"}`)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token":"access","token_type":"bearer","expires_in":3600,"refresh_token":"refresh","xoauth_yahoo_guid":"JT4FACLQZI2OCE"}`)
+	})
+
+	f.Server = httptest.NewServer(mux)
+	return f
+}
+
+func (f *fakeAuthServer) config(redirectUrl string) *oauth2.Config {
+	conf := NewConfig("clientId", "clientSecret", redirectUrl)
+	conf.Endpoint.AuthURL = f.URL + "/auth"
+	conf.Endpoint.TokenURL = f.URL + "/token"
+	return conf
+}
+
+// browser follows the authorization url like a user's browser would.
+func browser(client *http.Client) func(string) error {
+	return func(u string) error {
+		resp, err := client.Get(u)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+}
+
+func TestLocalLogin(t *testing.T) {
+	f := newFakeAuthServer()
+	defer f.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	out := &bytes.Buffer{}
+	l := LocalLogin{Open: browser(http.DefaultClient), Out: out}
+
+	token, err := l.Login(ctx, f.config(""))
+	if err != nil {
+		t.Fatalf("Unexpected LocalLogin.Login error: %v", err)
+	}
+
+	if token.AccessToken != "access" || token.Extra("xoauth_yahoo_guid") != "JT4FACLQZI2OCE" {
+		t.Errorf("LocalLogin.Login returned incorrect token %v", token)
+	}
+
+	if len(f.redirects) != 1 || !strings.HasPrefix(f.redirects[0], "http://127.0.0.1:") {
+		t.Errorf("Token exchange used incorrect redirect urls %v", f.redirects)
+	}
+}
+
+func TestLocalLoginTLS(t *testing.T) {
+	f := newFakeAuthServer()
+	defer f.Close()
+
+	// borrow the certificate and trusting client of a test tls server.
+	tlsServer := httptest.NewTLSServer(http.NotFoundHandler())
+	defer tlsServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	l := LocalLogin{TLSConfig: tlsServer.TLS, Open: browser(tlsServer.Client()), Out: &bytes.Buffer{}}
+	if _, err := l.Login(ctx, f.config("https://127.0.0.1/yahoo/callback")); err != nil {
+		t.Fatalf("Unexpected LocalLogin.Login error: %v", err)
+	}
+
+	u, _ := url.Parse(f.redirects[0])
+	if u.Scheme != "https" || u.Path != "/yahoo/callback" {
+		t.Errorf("Token exchange used incorrect redirect url %s", f.redirects[0])
+	}
+
+	// https redirect urls require a certificate.
+	l.TLSConfig = nil
+	if _, err := l.Login(ctx, f.config("https://127.0.0.1/yahoo/callback")); err == nil {
+		t.Error("Expected LocalLogin.Login to return an error")
+	}
+}
+
+func TestLocalLoginStateMismatch(t *testing.T) {
+	f := newFakeAuthServer()
+	defer f.Close()
+	f.callbackState = "forged"
+
+	// the forged callback is rejected and the login waits for the real one until the deadline.
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	l := LocalLogin{Open: browser(http.DefaultClient), Out: &bytes.Buffer{}}
+	if _, err := l.Login(ctx, f.config("")); err != ErrStateMismatch {
+		t.Errorf("Expected ErrStateMismatch got %v", err)
+	}
+
+	if len(f.redirects) != 0 {
+		t.Error("The code was exchanged despite the state mismatch")
+	}
+}
+
+func TestLocalLoginStrayRequests(t *testing.T) {
+	f := newFakeAuthServer()
+	defer f.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// a redirect url without a path is served at / so it also receives e.g. the browser's favicon request.
+	var strays []int
+	open := func(authURL string) error {
+		u, _ := url.Parse(authURL)
+		cb, _ := url.Parse(u.Query().Get("redirect_uri"))
+		for _, path := range []string{"/favicon.ico", "/?code=prefetched"} {
+			resp, err := http.Get(cb.Scheme + "://" + cb.Host + path)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			strays = append(strays, resp.StatusCode)
+		}
+		return browser(http.DefaultClient)(authURL)
+	}
+
+	l := LocalLogin{Open: open, Out: &bytes.Buffer{}}
+	token, err := l.Login(ctx, f.config("http://127.0.0.1"))
+	if err != nil {
+		t.Fatalf("Unexpected LocalLogin.Login error after stray requests: %v", err)
+	}
+	if token.AccessToken != "access" {
+		t.Errorf("LocalLogin.Login returned incorrect token %v", token)
+	}
+	if len(strays) != 2 || strays[0] != http.StatusBadRequest || strays[1] != http.StatusBadRequest {
+		t.Errorf("Stray callback requests returned %v, expected two %d", strays, http.StatusBadRequest)
+	}
+}
+
+func TestLocalLoginTimeout(t *testing.T) {
+	f := newFakeAuthServer()
+	defer f.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// the user never visits the url.
+	l := LocalLogin{Out: &bytes.Buffer{}}
+	if _, err := l.Login(ctx, f.config("")); err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded got %v", err)
+	}
+}
+
+func TestLocalLoginOOB(t *testing.T) {
+	f := newFakeAuthServer()
+	defer f.Close()
+
+	out := &bytes.Buffer{}
+	l := LocalLogin{OOB: true, Out: out, In: strings.NewReader("good-code\n")}
+
+	token, err := l.Login(context.Background(), f.config(""))
+	if err != nil {
+		t.Fatalf("Unexpected LocalLogin.Login error: %v", err)
+	}
+	if token.AccessToken != "access" {
+		t.Errorf("LocalLogin.Login returned incorrect token %v", token)
+	}
+
+	if !strings.Contains(out.String(), "redirect_uri=oob") {
+		t.Errorf("The oob authorization url was not printed: %s", out.String())
+	}
+
+	// falls back to oob when the listener can not bind.
+	l = LocalLogin{Addr: "256.0.0.1:0", Out: &bytes.Buffer{}, In: strings.NewReader("good-code\n")}
+	if _, err := l.Login(context.Background(), f.config("")); err != nil {
+		t.Errorf("Unexpected LocalLogin.Login error after falling back: %v", err)
+	}
+
+	l = LocalLogin{OOB: true, Out: &bytes.Buffer{}, In: strings.NewReader("\n")}
+	if _, err := l.Login(context.Background(), f.config("")); err == nil {
+		t.Error("Expected LocalLogin.Login to return an error without a code")
+	}
+}
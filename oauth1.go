@@ -0,0 +1,372 @@
+package yahoo
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OAuth 1.0a endpoints, only needed for integrations which still hold OAuth 1.0a tokens.
+const (
+	OAuth1RequestTokenURL = "https://api.login.yahoo.com/oauth/v2/get_request_token"
+	OAuth1AuthorizeURL    = "https://api.login.yahoo.com/oauth/v2/request_auth"
+	OAuth1AccessTokenURL  = "https://api.login.yahoo.com/oauth/v2/get_token"
+)
+
+// OAuth1Config is the consumer of the legacy OAuth 1.0a flow (RFC 5849).
+type OAuth1Config struct {
+	ConsumerKey    string
+	ConsumerSecret string
+	// CallbackURL receives the verifier, oob for installed applications.
+	CallbackURL string
+
+	RequestTokenURL string
+	AuthorizeURL    string
+	AccessTokenURL  string
+}
+
+// NewOAuth1Config creates an OAuth1Config using Yahoo's endpoints.
+// if the callbackUrl is empty, this is assumed to be an installed application.
+func NewOAuth1Config(consumerKey, consumerSecret, callbackUrl string) *OAuth1Config {
+	if callbackUrl == "" {
+		callbackUrl = "oob"
+	}
+
+	return &OAuth1Config{
+		ConsumerKey:     consumerKey,
+		ConsumerSecret:  consumerSecret,
+		CallbackURL:     callbackUrl,
+		RequestTokenURL: OAuth1RequestTokenURL,
+		AuthorizeURL:    OAuth1AuthorizeURL,
+		AccessTokenURL:  OAuth1AccessTokenURL,
+	}
+}
+
+// OAuth1Token is a request or access token and its secret.
+type OAuth1Token struct {
+	Token  string `json:"token"`
+	Secret string `json:"secret"`
+	// SessionHandle lets Yahoo access tokens be refreshed after they expire.
+	SessionHandle string `json:"session_handle,omitempty"`
+	// Expiry is when the access token expires, zero if unknown.
+	Expiry time.Time `json:"expiry,omitempty"`
+	// Guid is the Yahoo GUID of the user who authorized the token.
+	Guid string `json:"guid,omitempty"`
+}
+
+// Expired determines if the token has an expiry which has passed.
+func (t *OAuth1Token) Expired() bool {
+	return !t.Expiry.IsZero() && !time.Now().Before(t.Expiry)
+}
+
+// RequestToken obtains a temporary token to send the user to AuthCodeURL with.
+func (c *OAuth1Config) RequestToken(client *http.Client) (*OAuth1Token, error) {
+	return c.tokenRequest(client, c.RequestTokenURL, &OAuth1Token{}, map[string]string{"oauth_callback": c.CallbackURL})
+}
+
+// AuthCodeURL returns the url the user approves the request token at.
+func (c *OAuth1Config) AuthCodeURL(requestToken *OAuth1Token) string {
+	sep := "?"
+	if strings.Contains(c.AuthorizeURL, "?") {
+		sep = "&"
+	}
+	return c.AuthorizeURL + sep + "oauth_token=" + url.QueryEscape(requestToken.Token)
+}
+
+// Exchange trades an approved request token and its verifier for an access token.
+func (c *OAuth1Config) Exchange(client *http.Client, requestToken *OAuth1Token, verifier string) (*OAuth1Token, error) {
+	return c.tokenRequest(client, c.AccessTokenURL, requestToken, map[string]string{"oauth_verifier": verifier})
+}
+
+// Refresh obtains a new access token using the session handle of an expired one.
+func (c *OAuth1Config) Refresh(client *http.Client, t *OAuth1Token) (*OAuth1Token, error) {
+	if t.SessionHandle == "" {
+		return nil, errors.New("The OAuth 1.0a token has no session handle and can not be refreshed")
+	}
+
+	nt, err := c.tokenRequest(client, c.AccessTokenURL, t, map[string]string{"oauth_session_handle": t.SessionHandle})
+	if err != nil {
+		return nil, err
+	}
+	if nt.SessionHandle == "" {
+		nt.SessionHandle = t.SessionHandle
+	}
+	if nt.Guid == "" {
+		nt.Guid = t.Guid
+	}
+	return nt, nil
+}
+
+// Client returns an http.Client which signs its requests with t, refreshing it when it expires.
+// It can be passed to every fantasy query builder.
+func (c *OAuth1Config) Client(t *OAuth1Token) *http.Client {
+	return &http.Client{Transport: NewOAuth1Transport(c, t)}
+}
+
+// TokenRequest sends a signed POST to a token endpoint and reads the form encoded token in the response.
+func (c *OAuth1Config) tokenRequest(client *http.Client, endpoint string, t *OAuth1Token, params map[string]string) (*OAuth1Token, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequest("POST", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s := c.signer(t)
+	if err := s.sign(req, params, randomNonce(), time.Now().Unix()); err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OAuth 1.0a token request to %s failed with status %s: %s", endpoint, resp.Status, body)
+	}
+
+	v, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("Could not read the OAuth 1.0a token response: %v", err)
+	}
+	if v.Get("oauth_token") == "" {
+		return nil, fmt.Errorf("OAuth 1.0a token response has no oauth_token: %s", body)
+	}
+
+	nt := &OAuth1Token{
+		Token:         v.Get("oauth_token"),
+		Secret:        v.Get("oauth_token_secret"),
+		SessionHandle: v.Get("oauth_session_handle"),
+		Guid:          v.Get("xoauth_yahoo_guid"),
+	}
+	if exp, err := strconv.Atoi(v.Get("oauth_expires_in")); err == nil && exp > 0 {
+		nt.Expiry = time.Now().Add(time.Duration(exp) * time.Second)
+	}
+	return nt, nil
+}
+
+func (c *OAuth1Config) signer(t *OAuth1Token) *OAuth1Signer {
+	return &OAuth1Signer{ConsumerKey: c.ConsumerKey, ConsumerSecret: c.ConsumerSecret, Token: t.Token, TokenSecret: t.Secret}
+}
+
+// OAuth1Signer signs requests with HMAC-SHA1 (RFC 5849 section 3.4.2).
+type OAuth1Signer struct {
+	ConsumerKey    string
+	ConsumerSecret string
+	Token          string
+	TokenSecret    string
+}
+
+// Sign adds the OAuth Authorization header to req.
+// A form encoded body is read and restored so its parameters can be signed.
+func (s *OAuth1Signer) Sign(req *http.Request) error {
+	return s.sign(req, nil, randomNonce(), time.Now().Unix())
+}
+
+// Sign adds the Authorization header with extra oauth parameters, nonce and timestamp.
+func (s *OAuth1Signer) sign(req *http.Request, extra map[string]string, nonce string, timestamp int64) error {
+	oauth := map[string]string{
+		"oauth_consumer_key":     s.ConsumerKey,
+		"oauth_nonce":            nonce,
+		"oauth_signature_method": "HMAC-SHA1",
+		"oauth_timestamp":        strconv.FormatInt(timestamp, 10),
+	}
+	if s.Token != "" {
+		oauth["oauth_token"] = s.Token
+	}
+	for k, v := range extra {
+		oauth[k] = v
+	}
+
+	base, err := signatureBase(req, oauth)
+	if err != nil {
+		return err
+	}
+	oauth["oauth_signature"] = s.signature(base)
+
+	keys := make([]string, 0, len(oauth))
+	for k := range oauth {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = percentEncode(k) + `="` + percentEncode(oauth[k]) + `"`
+	}
+	req.Header.Set("Authorization", "OAuth "+strings.Join(parts, ", "))
+	return nil
+}
+
+// Signature returns the base64 HMAC-SHA1 of the signature base string.
+func (s *OAuth1Signer) signature(base string) string {
+	mac := hmac.New(sha1.New, []byte(percentEncode(s.ConsumerSecret)+"&"+percentEncode(s.TokenSecret)))
+	mac.Write([]byte(base))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SignatureBase builds the signature base string of req (RFC 5849 section 3.4.1)
+// from its method, base url, query, form encoded body and the oauth parameters.
+func signatureBase(req *http.Request, oauth map[string]string) (string, error) {
+	var params [][2]string
+	add := func(v url.Values) {
+		for k, vs := range v {
+			for _, val := range vs {
+				params = append(params, [2]string{percentEncode(k), percentEncode(val)})
+			}
+		}
+	}
+
+	q, err := url.ParseQuery(req.URL.RawQuery)
+	if err != nil {
+		return "", err
+	}
+	add(q)
+
+	if req.Body != nil && strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return "", err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return "", err
+		}
+		add(form)
+	}
+
+	for k, v := range oauth {
+		params = append(params, [2]string{percentEncode(k), percentEncode(v)})
+	}
+
+	sort.Slice(params, func(i, j int) bool {
+		if params[i][0] != params[j][0] {
+			return params[i][0] < params[j][0]
+		}
+		return params[i][1] < params[j][1]
+	})
+
+	pairs := make([]string, len(params))
+	for i, p := range params {
+		pairs[i] = p[0] + "=" + p[1]
+	}
+
+	return strings.ToUpper(req.Method) + "&" + percentEncode(baseURL(req.URL)) + "&" + percentEncode(strings.Join(pairs, "&")), nil
+}
+
+// BaseURL returns the scheme, host and path of u with the default port removed and the scheme and host lower cased.
+func baseURL(u *url.URL) string {
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Host)
+	if (scheme == "http" && strings.HasSuffix(host, ":80")) || (scheme == "https" && strings.HasSuffix(host, ":443")) {
+		host = host[:strings.LastIndex(host, ":")]
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	return scheme + "://" + host + path
+}
+
+// PercentEncode encodes every byte except the RFC 3986 unreserved characters.
+func percentEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || c == '-' || c == '.' || c == '_' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func randomNonce() string {
+	n, err := randomString(16)
+	if err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return n
+}
+
+// OAuth1Transport is an http.RoundTripper which signs requests with an OAuth 1.0a access token.
+// Expired tokens with a session handle are refreshed before the request is sent.
+type OAuth1Transport struct {
+	Config *OAuth1Config
+	// Base sends the signed requests, http.DefaultTransport if nil.
+	Base http.RoundTripper
+
+	mu    sync.Mutex
+	token *OAuth1Token
+}
+
+// NewOAuth1Transport creates an OAuth1Transport signing with t.
+func NewOAuth1Transport(c *OAuth1Config, t *OAuth1Token) *OAuth1Transport {
+	return &OAuth1Transport{Config: c, token: t}
+}
+
+// Token returns the current access token, which changes when it is refreshed.
+func (t *OAuth1Transport) Token() *OAuth1Token {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.token
+}
+
+// RoundTrip signs a copy of req and sends it.
+func (t *OAuth1Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.validToken()
+	if err != nil {
+		return nil, err
+	}
+
+	r := req.Clone(req.Context())
+	if err := t.Config.signer(token).Sign(r); err != nil {
+		return nil, err
+	}
+	return t.base().RoundTrip(r)
+}
+
+func (t *OAuth1Transport) validToken() (*OAuth1Token, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token.Expired() && t.token.SessionHandle != "" {
+		nt, err := t.Config.Refresh(&http.Client{Transport: t.base()}, t.token)
+		if err != nil {
+			return nil, err
+		}
+		t.token = nt
+	}
+	return t.token, nil
+}
+
+func (t *OAuth1Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
@@ -0,0 +1,238 @@
+package yahoo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 5849 section 1.2 example credentials.
+const (
+	rfcConsumerKey    = "dpf43f3p2l4k3l03"
+	rfcConsumerSecret = "kd94hf93k423kf44"
+)
+
+func TestOAuth1SignatureVectors(t *testing.T) {
+	tests := []struct {
+		method, url string
+		signer      OAuth1Signer
+		extra       map[string]string
+		nonce       string
+		timestamp   int64
+		signature   string
+	}{
+		// temporary credentials request.
+		{"POST", "https://photos.example.net/initiate",
+			OAuth1Signer{ConsumerKey: rfcConsumerKey, ConsumerSecret: rfcConsumerSecret},
+			map[string]string{"oauth_callback": "http://printer.example.com/ready"},
+			"wIjqoS", 137131200, "74KNZJeDHnMBp0EMJ9ZHt/XKycU="},
+		// token request.
+		{"POST", "https://photos.example.net/token",
+			OAuth1Signer{ConsumerKey: rfcConsumerKey, ConsumerSecret: rfcConsumerSecret, Token: "hh5s93j4hdidpola", TokenSecret: "hdhd0244k9j7ao03"},
+			map[string]string{"oauth_verifier": "hfdp7dh39dks9884"},
+			"walatlh", 137131201, "gKgrFCywp7rO0OXSjdot/IHF7IU="},
+		// protected resource request.
+		{"GET", "http://photos.example.net/photos?file=vacation.jpg&size=original",
+			OAuth1Signer{ConsumerKey: rfcConsumerKey, ConsumerSecret: rfcConsumerSecret, Token: "nnch734d00sl2jdk", TokenSecret: "pfkkdhi9sl3r4s00"},
+			nil, "chapoH", 137131202, "MdpQcU8iPSUjWoN/UDMsK2sui9I="},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest(test.method, test.url, nil)
+		if err := test.signer.sign(req, test.extra, test.nonce, test.timestamp); err != nil {
+			t.Fatalf("Unexpected sign error: %v", err)
+		}
+
+		params := authorizationParams(req.Header.Get("Authorization"))
+		if params["oauth_signature"] != test.signature {
+			t.Errorf("Incorrect signature for %s %s got %s, expected %s", test.method, test.url, params["oauth_signature"], test.signature)
+		}
+	}
+}
+
+func TestOAuth1SignatureBase(t *testing.T) {
+	// RFC 5849 section 3.4.1.1
+	req, _ := http.NewRequest("POST", "http://example.com/request?b5=%3D%253D&a3=a&c%40=&a2=r%20b", strings.NewReader("c2&a3=2+q"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	base, err := signatureBase(req, map[string]string{
+		"oauth_consumer_key":     "9djdj82h48djs9d2",
+		"oauth_token":            "kkk9d7dh3k39sjv7",
+		"oauth_signature_method": "HMAC-SHA1",
+		"oauth_timestamp":        "137131201",
+		"oauth_nonce":            "7d8f3e4a",
+	})
+	if err != nil {
+		t.Fatalf("Unexpected signatureBase error: %v", err)
+	}
+
+	want := "POST&http%3A%2F%2Fexample.com%2Frequest&a2%3Dr%2520b%26a3%3D2%2520q%26a3%3Da%26b5%3D%253D%25253D%26c%2540%3D%26c2%3D%26oauth_consumer_key%3D9djdj82h48djs9d2%26oauth_nonce%3D7d8f3e4a%26oauth_signature_method%3DHMAC-SHA1%26oauth_timestamp%3D137131201%26oauth_token%3Dkkk9d7dh3k39sjv7"
+	if base != want {
+		t.Errorf("Incorrect signature base string\ngot  %s\nwant %s", base, want)
+	}
+
+	// the body can still be sent.
+	body, _ := ioutil.ReadAll(req.Body)
+	if string(body) != "c2&a3=2+q" {
+		t.Errorf("signatureBase did not restore the body got %s", body)
+	}
+}
+
+// authorizationParams parses an OAuth Authorization header.
+func authorizationParams(header string) map[string]string {
+	params := map[string]string{}
+	for _, p := range strings.Split(strings.TrimPrefix(header, "OAuth "), ",") {
+		kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		k, _ := url.PathUnescape(kv[0])
+		v, _ := url.PathUnescape(strings.Trim(kv[1], `"`))
+		params[k] = v
+	}
+	return params
+}
+
+// fakeOAuth1Provider is a stand in for Yahoo's OAuth 1.0a endpoints and a protected resource.
+// Every request must be signed with the RFC 5849 example consumer.
+type fakeOAuth1Provider struct {
+	*httptest.Server
+	secrets   map[string]string
+	refreshes int
+}
+
+func newFakeOAuth1Provider() *fakeOAuth1Provider {
+	f := &fakeOAuth1Provider{secrets: map[string]string{"": ""}}
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/get_request_token", func(w http.ResponseWriter, r *http.Request) {
+		params, ok := f.verify(w, r)
+		if !ok {
+			return
+		}
+		if params["oauth_callback"] != "oob" {
+			http.Error(w, "oauth_problem=parameter_absent", http.StatusBadRequest)
+			return
+		}
+		f.secrets["hh5s93j4hdidpola"] = "hdhd0244k9j7ao03"
+		fmt.Fprint(w, "oauth_token=hh5s93j4hdidpola&oauth_token_secret=hdhd0244k9j7ao03&oauth_callback_confirmed=true")
+	})
+
+	mux.HandleFunc("/get_token", func(w http.ResponseWriter, r *http.Request) {
+		params, ok := f.verify(w, r)
+		if !ok {
+			return
+		}
+
+		switch {
+		case params["oauth_token"] == "hh5s93j4hdidpola" && params["oauth_verifier"] == "hfdp7dh39dks9884":
+			f.secrets["nnch734d00sl2jdk"] = "pfkkdhi9sl3r4s00"
+			fmt.Fprint(w, "oauth_token=nnch734d00sl2jdk&oauth_token_secret=pfkkdhi9sl3r4s00&oauth_expires_in=3600&oauth_session_handle=session&xoauth_yahoo_guid=GUID")
+		case params["oauth_session_handle"] == "session":
+			f.refreshes++
+			f.secrets["refreshed"] = "refreshed-secret"
+			fmt.Fprint(w, "oauth_token=refreshed&oauth_token_secret=refreshed-secret&oauth_expires_in=3600")
+		default:
+			http.Error(w, "oauth_problem=token_rejected", http.StatusUnauthorized)
+		}
+	})
+
+	mux.HandleFunc("/photos", func(w http.ResponseWriter, r *http.Request) {
+		params, ok := f.verify(w, r)
+		if !ok {
+			return
+		}
+		fmt.Fprintf(w, "%s %s", params["oauth_token"], r.URL.Query().Get("file"))
+	})
+
+	f.Server = httptest.NewServer(mux)
+	return f
+}
+
+// verify recomputes the signature of r and rejects it if it does not match.
+func (f *fakeOAuth1Provider) verify(w http.ResponseWriter, r *http.Request) (map[string]string, bool) {
+	params := authorizationParams(r.Header.Get("Authorization"))
+	secret, known := f.secrets[params["oauth_token"]]
+	if params["oauth_consumer_key"] != rfcConsumerKey || !known {
+		http.Error(w, "oauth_problem=consumer_key_rejected", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	oauth := map[string]string{}
+	for k, v := range params {
+		if k != "oauth_signature" {
+			oauth[k] = v
+		}
+	}
+
+	r.URL.Scheme, r.URL.Host = "http", r.Host
+	base, err := signatureBase(r, oauth)
+	s := OAuth1Signer{ConsumerSecret: rfcConsumerSecret, TokenSecret: secret}
+	if err != nil || s.signature(base) != params["oauth_signature"] {
+		http.Error(w, "oauth_problem=signature_invalid", http.StatusUnauthorized)
+		return nil, false
+	}
+	return params, true
+}
+
+func TestOAuth1Flow(t *testing.T) {
+	f := newFakeOAuth1Provider()
+	defer f.Close()
+
+	conf := NewOAuth1Config(rfcConsumerKey, rfcConsumerSecret, "")
+	conf.RequestTokenURL = f.URL + "/get_request_token"
+	conf.AuthorizeURL = f.URL + "/request_auth"
+	conf.AccessTokenURL = f.URL + "/get_token"
+
+	rt, err := conf.RequestToken(nil)
+	if err != nil {
+		t.Fatalf("Unexpected RequestToken error: %v", err)
+	}
+	if u := conf.AuthCodeURL(rt); u != f.URL+"/request_auth?oauth_token=hh5s93j4hdidpola" {
+		t.Errorf("Incorrect AuthCodeURL got %s", u)
+	}
+
+	if _, err := conf.Exchange(nil, rt, "wrong"); err == nil {
+		t.Error("Expected an Exchange error for an incorrect verifier")
+	}
+
+	token, err := conf.Exchange(nil, rt, "hfdp7dh39dks9884")
+	if err != nil {
+		t.Fatalf("Unexpected Exchange error: %v", err)
+	}
+	if token.Token != "nnch734d00sl2jdk" || token.Guid != "GUID" || token.SessionHandle != "session" || token.Expired() {
+		t.Errorf("Exchange returned incorrect token %+v", token)
+	}
+
+	get := func(client *http.Client) string {
+		resp, err := client.Get(f.URL + "/photos?file=vacation.jpg&size=original")
+		if err != nil {
+			t.Fatalf("Unexpected request error: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("Signed request was rejected: %s", body)
+		}
+		return string(body)
+	}
+
+	if body := get(conf.Client(token)); body != "nnch734d00sl2jdk vacation.jpg" {
+		t.Errorf("Incorrect protected resource response %s", body)
+	}
+
+	// an expired token is refreshed with its session handle before the request.
+	token.Expiry = time.Now().Add(-time.Minute)
+	transport := NewOAuth1Transport(conf, token)
+	if body := get(&http.Client{Transport: transport}); body != "refreshed vacation.jpg" {
+		t.Errorf("Expired token was not refreshed got %s", body)
+	}
+	if nt := transport.Token(); f.refreshes != 1 || nt.Guid != "GUID" || nt.SessionHandle != "session" {
+		t.Errorf("Refreshed token is incorrect %+v, refreshes %d", nt, f.refreshes)
+	}
+}
@@ -0,0 +1,370 @@
+package yahoo
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	// ScopeOpenID requests an OpenID Connect ID token alongside the access token.
+	ScopeOpenID = "openid"
+	// Issuer is the iss claim of Yahoo ID tokens.
+	Issuer = "https://api.login.yahoo.com"
+	// JWKSURL serves the keys Yahoo signs ID tokens with.
+	JWKSURL = "https://api.login.yahoo.com/openid/v1/certs"
+	// UserInfoURL serves the profile of the authorized user.
+	UserInfoURL = "https://api.login.yahoo.com/openid/v1/userinfo"
+)
+
+// WithOpenID returns a copy of conf which also requests the openid scope.
+func WithOpenID(conf *oauth2.Config) *oauth2.Config {
+	c := *conf
+	c.Scopes = append([]string{}, conf.Scopes...)
+	for _, s := range c.Scopes {
+		if s == ScopeOpenID {
+			return &c
+		}
+	}
+	c.Scopes = append(c.Scopes, ScopeOpenID)
+	return &c
+}
+
+// NewNonce returns a random nonce and the option which adds it to the authorization url.
+// Pass the nonce to IDTokenVerifier.Verify to make sure the ID token was issued for this login.
+func NewNonce() (string, oauth2.AuthCodeOption, error) {
+	n, err := randomString(24)
+	if err != nil {
+		return "", nil, err
+	}
+	return n, oauth2.SetAuthURLParam("nonce", n), nil
+}
+
+// IDToken contains the verified claims of an OpenID Connect ID token.
+type IDToken struct {
+	// Issuer should always be https://api.login.yahoo.com
+	Issuer string `json:"iss"`
+	// Subject is the Yahoo GUID of the user, it matches fantasy.User.Guid.
+	Subject string `json:"sub"`
+	// Audience holds the client ids the token was issued to.
+	Audience audience `json:"aud"`
+	// Expiry is the unix time after which the token must not be accepted.
+	Expiry int64 `json:"exp"`
+	// IssuedAt is the unix time the token was issued.
+	IssuedAt int64 `json:"iat"`
+	// Nonce is the nonce sent with the authorization request.
+	Nonce string `json:"nonce"`
+	// Name, Email and Picture are only set when the profile and email scopes were granted.
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Picture string `json:"picture"`
+}
+
+// Guid returns the Yahoo GUID of the user the token identifies.
+func (t *IDToken) Guid() string {
+	return t.Subject
+}
+
+// Audience unmarshals the aud claim which is either a string or an array of strings.
+type audience []string
+
+// UnmarshalJSON accepts a single string or an array of strings.
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*a = audience{s}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = audience(list)
+	return nil
+}
+
+// JWKS is a JSON Web Key Set (RFC 7517) holding the public keys ID tokens are signed with.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK is a single RSA or elliptic curve public key.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	// N and E are the modulus and exponent of an RSA key.
+	N string `json:"n"`
+	E string `json:"e"`
+	// Crv, X and Y are the curve and coordinates of an elliptic curve key.
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJWKS decodes a json key set.
+func ParseJWKS(data []byte) (*JWKS, error) {
+	jwks := &JWKS{}
+	if err := json.Unmarshal(data, jwks); err != nil {
+		return nil, fmt.Errorf("Could not read the key set: %v", err)
+	}
+	return jwks, nil
+}
+
+// FetchJWKS downloads the key set at url, normally JWKSURL.
+func FetchJWKS(client *http.Client, url string) (*JWKS, error) {
+	data, err := getJSON(client, url)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
+
+// PublicKey converts the key into an *rsa.PublicKey or *ecdsa.PublicKey.
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("Bad RSA modulus: %v", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("Bad RSA exponent: %v", err)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("Unsupported curve %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("Bad EC x coordinate: %v", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("Bad EC y coordinate: %v", err)
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	}
+	return nil, fmt.Errorf("Unsupported key type %s", k.Kty)
+}
+
+// IDTokenVerifier validates ID tokens issued to a client.
+type IDTokenVerifier struct {
+	// ClientID must be in the token's audience.
+	ClientID string
+	// Keys are the keys the token may be signed with.
+	Keys *JWKS
+	// Issuer overrides the expected issuer, Issuer by default.
+	Issuer string
+	// Now overrides the clock used to check expiry.
+	Now func() time.Time
+}
+
+// VerifyToken verifies the id_token returned alongside an oauth2 token.
+func (v *IDTokenVerifier) VerifyToken(t *oauth2.Token, nonce string) (*IDToken, error) {
+	raw, ok := t.Extra("id_token").(string)
+	if !ok || raw == "" {
+		return nil, errors.New("The token does not contain an id_token, was the openid scope requested?")
+	}
+	return v.Verify(raw, nonce)
+}
+
+// Verify checks the signature, issuer, audience and expiry of a raw ID token,
+// and its nonce when nonce is not empty, then returns its claims.
+func (v *IDTokenVerifier) Verify(raw, nonce string) (*IDToken, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("Malformed ID token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("Malformed ID token header: %v", err)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("Malformed ID token signature: %v", err)
+	}
+
+	key, err := v.key(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+
+	token := &IDToken{}
+	if err := decodeSegment(parts[1], token); err != nil {
+		return nil, fmt.Errorf("Malformed ID token claims: %v", err)
+	}
+
+	issuer := v.Issuer
+	if issuer == "" {
+		issuer = Issuer
+	}
+	if token.Issuer != issuer {
+		return nil, fmt.Errorf("ID token issued by %s, expected %s", token.Issuer, issuer)
+	}
+
+	if !token.Audience.contains(v.ClientID) {
+		return nil, fmt.Errorf("ID token was not issued to client %s", v.ClientID)
+	}
+
+	now := time.Now
+	if v.Now != nil {
+		now = v.Now
+	}
+	if now().Unix() >= token.Expiry {
+		return nil, errors.New("ID token has expired")
+	}
+
+	if nonce != "" && token.Nonce != nonce {
+		return nil, errors.New("ID token nonce does not match the authorization request")
+	}
+
+	return token, nil
+}
+
+// Key finds the key with id kid, a key set with a single key matches any id.
+func (v *IDTokenVerifier) key(kid string) (crypto.PublicKey, error) {
+	if v.Keys == nil {
+		return nil, errors.New("IDTokenVerifier has no keys")
+	}
+
+	for _, k := range v.Keys.Keys {
+		if k.Kid == kid || (kid == "" && len(v.Keys.Keys) == 1) {
+			return k.PublicKey()
+		}
+	}
+	return nil, fmt.Errorf("No key with id %s", kid)
+}
+
+// VerifySignature checks sig over signed with key using the RS256 or ES256 algorithm.
+func verifySignature(alg string, key crypto.PublicKey, signed string, sig []byte) error {
+	sum := sha256.Sum256([]byte(signed))
+
+	switch alg {
+	case "RS256":
+		k, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("RS256 ID token requires an RSA key")
+		}
+		if err := rsa.VerifyPKCS1v15(k, crypto.SHA256, sum[:], sig); err != nil {
+			return errors.New("ID token signature is invalid")
+		}
+		return nil
+	case "ES256":
+		k, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return errors.New("ES256 ID token requires an EC key")
+		}
+		if len(sig) != 64 {
+			return errors.New("ID token signature is invalid")
+		}
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(k, sum[:], r, s) {
+			return errors.New("ID token signature is invalid")
+		}
+		return nil
+	}
+	return fmt.Errorf("Unsupported ID token algorithm %s", alg)
+}
+
+// DecodeSegment base64url decodes a token segment and unmarshals its json into v.
+func decodeSegment(seg string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Contains determines if the audience includes clientID.
+func (a audience) contains(clientID string) bool {
+	for _, aud := range a {
+		if aud == clientID {
+			return true
+		}
+	}
+	return false
+}
+
+// UserInfo is the profile of the authorized user returned by the userinfo endpoint.
+type UserInfo struct {
+	// Subject is the Yahoo GUID of the user, it matches fantasy.User.Guid.
+	Subject       string `json:"sub"`
+	Name          string `json:"name"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+	Nickname      string `json:"nickname"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Picture       string `json:"picture"`
+	Locale        string `json:"locale"`
+}
+
+// Guid returns the Yahoo GUID of the user.
+func (u *UserInfo) Guid() string {
+	return u.Subject
+}
+
+// GetUserInfo requests the profile of the user who authorized client.
+func GetUserInfo(client *http.Client) (*UserInfo, error) {
+	return getUserInfo(client, UserInfoURL)
+}
+
+// GetUserInfo requests the profile from url.
+func getUserInfo(client *http.Client, url string) (*UserInfo, error) {
+	data, err := getJSON(client, url)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &UserInfo{}
+	if err := json.Unmarshal(data, info); err != nil {
+		return nil, fmt.Errorf("Could not read the user info: %v", err)
+	}
+	return info, nil
+}
+
+// GetJSON requests url and returns the body of a successful response.
+func getJSON(client *http.Client, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Request to %s failed with status %s: %s", url, resp.Status, data)
+	}
+	return data, nil
+}
@@ -0,0 +1,209 @@
+package yahoo
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+var openIDNow = time.Unix(1500000000, 0)
+
+// signIDToken builds a raw ID token with claims, signed by key with the given algorithm.
+func signIDToken(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	body, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(body)
+	sum := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		var err error
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, sum[:]); err != nil {
+			t.Fatal(err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, sum[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func claims() map[string]interface{} {
+	return map[string]interface{}{
+		"iss":   Issuer,
+		"sub":   "RXLFKG3VQFJ7IOKHGDL7TNPQ3A",
+		"aud":   "clientId",
+		"exp":   openIDNow.Add(time.Hour).Unix(),
+		"iat":   openIDNow.Unix(),
+		"nonce": "nonce",
+		"email": "manager@example.com",
+	}
+}
+
+func testJWKS(t *testing.T) (*JWKS, *rsa.PrivateKey, *ecdsa.PrivateKey) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enc := func(i *big.Int) string { return base64.RawURLEncoding.EncodeToString(i.Bytes()) }
+	data := fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":"rsa","alg":"RS256","use":"sig","n":"%s","e":"%s"},{"kty":"EC","kid":"ec","alg":"ES256","use":"sig","crv":"P-256","x":"%s","y":"%s"}]}`,
+		enc(rsaKey.N), enc(big.NewInt(int64(rsaKey.E))), enc(ecKey.X), enc(ecKey.Y))
+
+	jwks, err := ParseJWKS([]byte(data))
+	if err != nil {
+		t.Fatalf("Unexpected ParseJWKS error: %v", err)
+	}
+	return jwks, rsaKey, ecKey
+}
+
+func TestIDTokenVerify(t *testing.T) {
+	jwks, rsaKey, ecKey := testJWKS(t)
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	v := &IDTokenVerifier{ClientID: "clientId", Keys: jwks, Now: func() time.Time { return openIDNow }}
+
+	for _, raw := range []string{signIDToken(t, "RS256", "rsa", rsaKey, claims()), signIDToken(t, "ES256", "ec", ecKey, claims())} {
+		token, err := v.Verify(raw, "nonce")
+		if err != nil {
+			t.Fatalf("Unexpected IDTokenVerifier.Verify error: %v", err)
+		}
+		if token.Guid() != "RXLFKG3VQFJ7IOKHGDL7TNPQ3A" || token.Email != "manager@example.com" {
+			t.Errorf("IDTokenVerifier.Verify returned incorrect claims %+v", token)
+		}
+	}
+
+	// an audience list containing the client is accepted.
+	c := claims()
+	c["aud"] = []string{"other", "clientId"}
+	if _, err := v.Verify(signIDToken(t, "RS256", "rsa", rsaKey, c), ""); err != nil {
+		t.Errorf("Unexpected IDTokenVerifier.Verify error for audience list: %v", err)
+	}
+
+	tests := map[string]string{}
+
+	c = claims()
+	c["iss"] = "https://login.example.com"
+	tests["issuer"] = signIDToken(t, "RS256", "rsa", rsaKey, c)
+
+	c = claims()
+	c["aud"] = "other"
+	tests["audience"] = signIDToken(t, "RS256", "rsa", rsaKey, c)
+
+	c = claims()
+	c["exp"] = openIDNow.Add(-time.Minute).Unix()
+	tests["expiry"] = signIDToken(t, "RS256", "rsa", rsaKey, c)
+
+	c = claims()
+	c["nonce"] = "replayed"
+	tests["nonce"] = signIDToken(t, "RS256", "rsa", rsaKey, c)
+
+	tests["signature"] = signIDToken(t, "RS256", "rsa", otherKey, claims())
+	tests["key type"] = signIDToken(t, "ES256", "rsa", ecKey, claims())
+	tests["unknown key"] = signIDToken(t, "RS256", "missing", rsaKey, claims())
+	tests["malformed"] = "not-a-token"
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"rsa"}`))
+	body, _ := json.Marshal(claims())
+	tests["alg none"] = header + "." + base64.RawURLEncoding.EncodeToString(body) + "."
+
+	for name, raw := range tests {
+		if _, err := v.Verify(raw, "nonce"); err == nil {
+			t.Errorf("Expected an IDTokenVerifier.Verify error for bad %s", name)
+		}
+	}
+}
+
+func TestIDTokenVerifyToken(t *testing.T) {
+	jwks, rsaKey, _ := testJWKS(t)
+	v := &IDTokenVerifier{ClientID: "clientId", Keys: jwks, Now: func() time.Time { return openIDNow }}
+
+	token := (&oauth2.Token{AccessToken: "access"}).WithExtra(map[string]interface{}{
+		"id_token": signIDToken(t, "RS256", "rsa", rsaKey, claims()),
+	})
+	id, err := v.VerifyToken(token, "nonce")
+	if err != nil {
+		t.Fatalf("Unexpected IDTokenVerifier.VerifyToken error: %v", err)
+	}
+	if id.Guid() != "RXLFKG3VQFJ7IOKHGDL7TNPQ3A" {
+		t.Errorf("Incorrect guid got %s", id.Guid())
+	}
+
+	if _, err := v.VerifyToken(&oauth2.Token{AccessToken: "access"}, ""); err == nil {
+		t.Error("Expected an error for a token without an id_token")
+	}
+}
+
+func TestWithOpenID(t *testing.T) {
+	conf := NewConfig("clientId", "secret", "")
+	conf.Scopes = []string{"fspt-r"}
+
+	c := WithOpenID(conf)
+	if len(c.Scopes) != 2 || c.Scopes[1] != ScopeOpenID {
+		t.Errorf("WithOpenID returned scopes %v", c.Scopes)
+	}
+	if len(conf.Scopes) != 1 {
+		t.Errorf("WithOpenID modified the original scopes %v", conf.Scopes)
+	}
+	if c = WithOpenID(c); len(c.Scopes) != 2 {
+		t.Errorf("WithOpenID added a duplicate scope %v", c.Scopes)
+	}
+
+	nonce, opt, err := NewNonce()
+	if err != nil {
+		t.Fatalf("Unexpected NewNonce error: %v", err)
+	}
+	if u := c.AuthCodeURL("state", opt); !strings.Contains(u, "nonce="+nonce) {
+		t.Errorf("AuthCodeURL %s does not contain the nonce %s", u, nonce)
+	}
+}
+
+func TestGetUserInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access" {
+			http.Error(w, `{"error":"invalid_token"}`, http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"sub":"RXLFKG3VQFJ7IOKHGDL7TNPQ3A","name":"Test Manager","email":"manager@example.com","email_verified":true}`)
+	}))
+	defer server.Close()
+
+	conf := NewConfig("clientId", "secret", "")
+	client := conf.Client(oauth2.NoContext, &oauth2.Token{AccessToken: "access", Expiry: time.Now().Add(time.Hour)})
+
+	info, err := getUserInfo(client, server.URL)
+	if err != nil {
+		t.Fatalf("Unexpected getUserInfo error: %v", err)
+	}
+	if info.Guid() != "RXLFKG3VQFJ7IOKHGDL7TNPQ3A" || info.Name != "Test Manager" || !info.EmailVerified {
+		t.Errorf("getUserInfo returned incorrect info %+v", info)
+	}
+
+	if _, err := getUserInfo(http.DefaultClient, server.URL); err == nil {
+		t.Error("Expected an error for an unauthorized userinfo request")
+	}
+}
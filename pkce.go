@@ -0,0 +1,46 @@
+package yahoo
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+
+	"golang.org/x/oauth2"
+)
+
+// PKCE is a proof key for a single authorization code exchange (RFC 7636).
+// Public clients such as desktop and mobile applications use it instead of embedding a client secret,
+// the code can only be exchanged by whoever holds the verifier.
+type PKCE struct {
+	// Verifier is the secret sent with the code exchange.
+	Verifier string
+}
+
+// NewPKCE creates a PKCE with a random 43 character verifier.
+func NewPKCE() (*PKCE, error) {
+	v, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+	return &PKCE{Verifier: v}, nil
+}
+
+// Challenge returns the S256 code challenge of the verifier.
+func (p *PKCE) Challenge() string {
+	sum := sha256.Sum256([]byte(p.Verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// AuthCodeOptions returns the options which add the challenge to the authorization url.
+func (p *PKCE) AuthCodeOptions() []oauth2.AuthCodeOption {
+	return []oauth2.AuthCodeOption{
+		oauth2.SetAuthURLParam("code_challenge", p.Challenge()),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	}
+}
+
+// ExchangeOptions returns the options which add the verifier to the code exchange.
+func (p *PKCE) ExchangeOptions() []oauth2.AuthCodeOption {
+	return []oauth2.AuthCodeOption{
+		oauth2.SetAuthURLParam("code_verifier", p.Verifier),
+	}
+}
@@ -0,0 +1,62 @@
+package yahoo
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPKCEChallenge(t *testing.T) {
+	// RFC 7636 Appendix B
+	p := PKCE{Verifier: "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"}
+	want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	if got := p.Challenge(); got != want {
+		t.Errorf("PKCE.Challenge() = %s, want %s", got, want)
+	}
+
+	n, err := NewPKCE()
+	if err != nil {
+		t.Fatalf("Unexpected NewPKCE error: %v", err)
+	}
+	if len(n.Verifier) != 43 {
+		t.Errorf("NewPKCE verifier has length %d, expected %d", len(n.Verifier), 43)
+	}
+}
+
+func TestLocalLoginPKCE(t *testing.T) {
+	f := newFakeAuthServer()
+	defer f.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// a public client has no secret.
+	conf := NewConfig("clientId", "", "")
+	conf.Endpoint.AuthURL = f.URL + "/auth"
+	conf.Endpoint.TokenURL = f.URL + "/token"
+
+	l := LocalLogin{PKCE: true, Open: browser(http.DefaultClient), Out: &bytes.Buffer{}}
+	token, err := l.Login(ctx, conf)
+	if err != nil {
+		t.Fatalf("Unexpected LocalLogin.Login error: %v", err)
+	}
+
+	if f.challenge == "" {
+		t.Error("The authorization url did not contain a code challenge")
+	}
+	if token.AccessToken != "access" {
+		t.Errorf("LocalLogin.Login returned incorrect token %v", token)
+	}
+	if f.clientSecrets[0] != "" {
+		t.Errorf("A client secret was sent by a public client %s", f.clientSecrets[0])
+	}
+
+	// the stand in token endpoint rejects a verifier which doesn't match the challenge.
+	p := PKCE{Verifier: "wrong"}
+	if _, err := conf.Exchange(ctx, "good-code", p.ExchangeOptions()...); err == nil {
+		t.Error("Expected the code exchange with the wrong verifier to fail")
+	}
+}
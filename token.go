@@ -0,0 +1,255 @@
+package yahoo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// ErrNoToken is returned when no token has been saved yet, the user needs to authorize the application.
+var ErrNoToken = errors.New("No token has been saved, authorization is required")
+
+// TokenStore saves and loads an oauth2 token so users stay logged in between runs.
+type TokenStore interface {
+	// Load returns the saved token or ErrNoToken.
+	Load() (*oauth2.Token, error)
+	// Save replaces the saved token.
+	Save(*oauth2.Token) error
+}
+
+// FileTokenStore saves a token as json in a file only readable by its owner.
+type FileTokenStore struct {
+	Path string
+}
+
+// NewFileTokenStore creates a FileTokenStore saving to path.
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{Path: path}
+}
+
+// Load reads the token from the file.
+func (s *FileTokenStore) Load() (*oauth2.Token, error) {
+	data, err := ioutil.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return nil, ErrNoToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t := &oauth2.Token{}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("Could not read token file %s: %v", s.Path, err)
+	}
+	return t, nil
+}
+
+// Save atomically replaces the file with t.
+func (s *FileTokenStore) Save(t *oauth2.Token) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.Path, data, 0600)
+}
+
+// WriteFileAtomic writes data to a temporary file in the same directory as path then renames it over path,
+// so readers never see a partially written file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(dir, "."+filepath.Base(path)+".")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+
+	err = f.Chmod(perm)
+	if err == nil {
+		_, err = f.Write(data)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// TokenSource is an oauth2.TokenSource which refreshes expired tokens and saves every new token to a TokenStore.
+// It is safe for concurrent use.
+type TokenSource struct {
+	// OnRefresh is called after the token has been refreshed.
+	OnRefresh func(old, new *oauth2.Token)
+	// OnRefreshError is called when a refresh fails, including when the refresh token is invalid.
+	OnRefreshError func(err error)
+	// OnInvalid is called when Yahoo rejects the refresh token, the user must authorize the application again.
+	OnInvalid func(err error)
+	// OnSaveError is called when a refreshed token could not be saved to the store.
+	// The token is still used, saving is retried after the next refresh.
+	OnSaveError func(err error)
+
+	ctx   context.Context
+	conf  *oauth2.Config
+	store TokenStore
+
+	mu    sync.Mutex
+	token *oauth2.Token
+	// Unsaved is set when the current token could not be saved, saving is retried after the next refresh.
+	unsaved bool
+	// Invalid is the error refreshing a rejected refresh token, it is returned without retrying until SetToken.
+	invalid error
+}
+
+// NewTokenSource creates a TokenSource which loads its token from store the first time it is needed.
+// The context is used for refresh requests.
+// The hooks must be set before the TokenSource is used.
+func NewTokenSource(ctx context.Context, conf *oauth2.Config, store TokenStore) *TokenSource {
+	return &TokenSource{ctx: ctx, conf: conf, store: store}
+}
+
+// Token returns a valid token, refreshing it when it has expired.
+// It returns ErrNoToken when the store has no token, SetToken must then be called with a newly authorized token.
+// Once the refresh token has been rejected the same error is returned without contacting Yahoo until SetToken is called.
+func (s *TokenSource) Token() (*oauth2.Token, error) {
+	return s.get(0)
+}
+
+// RefreshIfExpiring refreshes the token now if it expires within d, so requests never wait on a refresh.
+// A token without an expiry is never refreshed.
+func (s *TokenSource) RefreshIfExpiring(d time.Duration) (*oauth2.Token, error) {
+	return s.get(d)
+}
+
+// Expiry returns the expiry of the current token, it is zero when no token has been loaded.
+func (s *TokenSource) Expiry() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token == nil {
+		return time.Time{}
+	}
+	return s.token.Expiry
+}
+
+// Get returns the current token, refreshing it when it is invalid or expires within early.
+// The hooks are called after the lock is released so they may use the TokenSource.
+func (s *TokenSource) get(early time.Duration) (*oauth2.Token, error) {
+	s.mu.Lock()
+	old, t, saveErr, err := s.refresh(early)
+	s.mu.Unlock()
+
+	if saveErr != nil && s.OnSaveError != nil {
+		s.OnSaveError(saveErr)
+	}
+	if err != nil && old != nil {
+		if s.OnRefreshError != nil {
+			s.OnRefreshError(err)
+		}
+		if s.OnInvalid != nil && IsRevoked(err) {
+			s.OnInvalid(err)
+		}
+	}
+	if err == nil && old != nil && s.OnRefresh != nil {
+		s.OnRefresh(old, t)
+	}
+	return t, err
+}
+
+// Refresh loads and refreshes the token, old is set when a refresh was attempted.
+// A refreshed token which could not be saved is still returned along with saveErr.
+// The caller must hold s.mu.
+func (s *TokenSource) refresh(early time.Duration) (old, t *oauth2.Token, saveErr, err error) {
+	if s.token == nil {
+		t, err := s.store.Load()
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		s.token = t
+	}
+
+	expiring := early > 0 && !s.token.Expiry.IsZero() && time.Until(s.token.Expiry) < early
+	if !s.token.Valid() || expiring {
+		if s.invalid != nil {
+			return nil, nil, nil, s.invalid
+		}
+
+		old = s.token
+		current := s.token
+		if expiring {
+			// a copy which has already expired makes oauth2 refresh it.
+			c := *current
+			c.Expiry = time.Now().Add(-time.Minute)
+			current = &c
+		}
+
+		t, err := s.conf.TokenSource(s.ctx, current).Token()
+		if err != nil {
+			if IsRevoked(err) {
+				s.invalid = err
+			}
+			return old, nil, nil, err
+		}
+
+		s.unsaved = s.unsaved || t.AccessToken != s.token.AccessToken || t.RefreshToken != s.token.RefreshToken
+		s.token = t
+
+		if s.unsaved {
+			if err := s.store.Save(s.token); err != nil {
+				saveErr = fmt.Errorf("Could not save the refreshed token: %v", err)
+			} else {
+				s.unsaved = false
+			}
+		}
+	}
+
+	return old, s.token, saveErr, nil
+}
+
+// SetToken replaces the current token and saves it, use it after exchanging an authorization code.
+func (s *TokenSource) SetToken(t *oauth2.Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.token = t
+	s.invalid = nil
+	s.unsaved = true
+	if err := s.store.Save(t); err != nil {
+		return err
+	}
+	s.unsaved = false
+	return nil
+}
+
+// Client returns an http.Client which authorizes its requests with tokens from the TokenSource.
+// Its transport reports the token's scopes so fantasy write requests fail fast without ScopeWrite.
+func (s *TokenSource) Client() *http.Client {
+	return withScopes(oauth2.NewClient(s.ctx, s), s.scopes)
+}
+
+// Scopes returns the scopes granted with the current token, or those requested by the config.
+func (s *TokenSource) scopes() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return tokenScopes(s.token, s.conf.Scopes)
+}
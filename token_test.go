@@ -0,0 +1,251 @@
+package yahoo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestFileTokenStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "yahoo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := NewFileTokenStore(filepath.Join(dir, "config", "token.json"))
+
+	if _, err := store.Load(); err != ErrNoToken {
+		t.Errorf("Expected ErrNoToken from an empty store got %v", err)
+	}
+
+	token := &oauth2.Token{AccessToken: "access", RefreshToken: "refresh", Expiry: time.Unix(1500000000, 0)}
+	if err := store.Save(token); err != nil {
+		t.Fatalf("Unexpected FileTokenStore.Save error: %v", err)
+	}
+
+	info, err := os.Stat(store.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Incorrect token file permissions got %v, expected %v", info.Mode().Perm(), os.FileMode(0600))
+	}
+
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Unexpected FileTokenStore.Load error: %v", err)
+	}
+	if loaded.AccessToken != "access" || loaded.RefreshToken != "refresh" || !loaded.Expiry.Equal(token.Expiry) {
+		t.Errorf("FileTokenStore.Load returned incorrect token %v", loaded)
+	}
+
+	// no temporary files are left behind.
+	files, _ := ioutil.ReadDir(filepath.Dir(store.Path))
+	if len(files) != 1 {
+		t.Errorf("Expected a single file in the token directory got %d", len(files))
+	}
+}
+
+// memoryTokenStore is a TokenStore which counts how often it is saved.
+type memoryTokenStore struct {
+	token *oauth2.Token
+	saves int
+	// saveErr is returned by Save when set.
+	saveErr error
+}
+
+func (s *memoryTokenStore) Load() (*oauth2.Token, error) {
+	if s.token == nil {
+		return nil, ErrNoToken
+	}
+	return s.token, nil
+}
+
+func (s *memoryTokenStore) Save(t *oauth2.Token) error {
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+	s.token = t
+	s.saves++
+	return nil
+}
+
+// newTokenServer starts a token endpoint which hands out numbered access tokens.
+func newTokenServer(t *testing.T) (*httptest.Server, *oauth2.Config) {
+	n := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("refresh_token") != "refresh" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":"invalid_grant"}`)
+			return
+		}
+
+		n++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"access-%d","token_type":"bearer","expires_in":3600,"refresh_token":"refresh"}`, n)
+	}))
+
+	conf := NewConfig("clientId", "clientSecret", "")
+	conf.Endpoint.TokenURL = server.URL
+	return server, conf
+}
+
+func TestTokenSourceRefresh(t *testing.T) {
+	server, conf := newTokenServer(t)
+	defer server.Close()
+
+	store := &memoryTokenStore{token: &oauth2.Token{AccessToken: "expired", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Hour)}}
+	ts := NewTokenSource(context.Background(), conf, store)
+
+	token, err := ts.Token()
+	if err != nil {
+		t.Fatalf("Unexpected TokenSource.Token error: %v", err)
+	}
+
+	if token.AccessToken != "access-1" {
+		t.Errorf("TokenSource did not refresh the token got %s", token.AccessToken)
+	}
+	if store.saves != 1 || store.token.AccessToken != "access-1" {
+		t.Errorf("TokenSource did not save the refreshed token, saves %d", store.saves)
+	}
+
+	// a valid token is neither refreshed nor saved again.
+	if token, _ = ts.Token(); token.AccessToken != "access-1" || store.saves != 1 {
+		t.Errorf("TokenSource refreshed a valid token got %s, saves %d", token.AccessToken, store.saves)
+	}
+}
+
+func TestTokenSourceSaveError(t *testing.T) {
+	server, conf := newTokenServer(t)
+	defer server.Close()
+
+	expired := &oauth2.Token{AccessToken: "expired", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Hour)}
+	store := &memoryTokenStore{token: expired, saveErr: errors.New("read-only file system")}
+	ts := NewTokenSource(context.Background(), conf, store)
+
+	var saveErrors, refreshes int
+	ts.OnSaveError = func(err error) { saveErrors++ }
+	ts.OnRefresh = func(old, new *oauth2.Token) { refreshes++ }
+
+	// the refreshed token is used even though it could not be saved.
+	token, err := ts.Token()
+	if err != nil {
+		t.Fatalf("Unexpected TokenSource.Token error when the store fails: %v", err)
+	}
+	if token.AccessToken != "access-1" || saveErrors != 1 || refreshes != 1 {
+		t.Errorf("TokenSource returned %s with %d save errors and %d refreshes, expected access-1, 1 and 1", token.AccessToken, saveErrors, refreshes)
+	}
+
+	// a valid token is not saved again until the next refresh.
+	if token, err = ts.Token(); err != nil || token.AccessToken != "access-1" || saveErrors != 1 {
+		t.Errorf("TokenSource returned %v, %v with %d save errors before the next refresh", token, err, saveErrors)
+	}
+
+	store.saveErr = nil
+	if token, err = ts.RefreshIfExpiring(2 * time.Hour); err != nil || token.AccessToken != "access-2" {
+		t.Fatalf("TokenSource.RefreshIfExpiring returned %v, %v", token, err)
+	}
+	if store.saves != 1 || store.token.AccessToken != "access-2" {
+		t.Errorf("TokenSource did not save the token after the next refresh, saves %d", store.saves)
+	}
+}
+
+func TestTokenSourceNoToken(t *testing.T) {
+	store := &memoryTokenStore{}
+	ts := NewTokenSource(context.Background(), NewConfig("clientId", "clientSecret", ""), store)
+
+	if _, err := ts.Token(); err != ErrNoToken {
+		t.Errorf("Expected ErrNoToken got %v", err)
+	}
+
+	token := &oauth2.Token{AccessToken: "access", Expiry: time.Now().Add(time.Hour)}
+	if err := ts.SetToken(token); err != nil {
+		t.Fatalf("Unexpected TokenSource.SetToken error: %v", err)
+	}
+
+	if store.token != token {
+		t.Error("TokenSource.SetToken did not save the token")
+	}
+
+	if got, err := ts.Token(); err != nil || got.AccessToken != "access" {
+		t.Errorf("TokenSource returned %v, %v after SetToken", got, err)
+	}
+}
+
+func TestTokenSourceHooks(t *testing.T) {
+	server, conf := newTokenServer(t)
+	defer server.Close()
+
+	store := &memoryTokenStore{token: &oauth2.Token{AccessToken: "expired", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Hour)}}
+	ts := NewTokenSource(context.Background(), conf, store)
+
+	var refreshed []string
+	var failures, invalid int
+	ts.OnRefresh = func(old, new *oauth2.Token) {
+		refreshed = append(refreshed, old.AccessToken+">"+new.AccessToken)
+	}
+	ts.OnRefreshError = func(err error) { failures++ }
+	ts.OnInvalid = func(err error) { invalid++ }
+
+	if _, err := ts.Token(); err != nil {
+		t.Fatalf("Unexpected TokenSource.Token error: %v", err)
+	}
+	if len(refreshed) != 1 || refreshed[0] != "expired>access-1" {
+		t.Errorf("OnRefresh was called with %v", refreshed)
+	}
+
+	// a valid token does not call the hooks.
+	ts.Token()
+	if len(refreshed) != 1 {
+		t.Errorf("OnRefresh was called without a refresh %v", refreshed)
+	}
+
+	// the token expires within the hour so it is refreshed early.
+	token, err := ts.RefreshIfExpiring(2 * time.Hour)
+	if err != nil {
+		t.Fatalf("Unexpected TokenSource.RefreshIfExpiring error: %v", err)
+	}
+	if token.AccessToken != "access-2" || len(refreshed) != 2 {
+		t.Errorf("RefreshIfExpiring did not refresh the token got %s", token.AccessToken)
+	}
+	if token, _ = ts.RefreshIfExpiring(time.Minute); token.AccessToken != "access-2" {
+		t.Errorf("RefreshIfExpiring refreshed a token which is not expiring got %s", token.AccessToken)
+	}
+	if e := ts.Expiry(); !e.Equal(token.Expiry) {
+		t.Errorf("TokenSource.Expiry returned %v, expected %v", e, token.Expiry)
+	}
+
+	if failures != 0 || invalid != 0 {
+		t.Errorf("Unexpected failure hooks, failures %d invalid %d", failures, invalid)
+	}
+
+	// a revoked refresh token calls both failure hooks.
+	ts.SetToken(&oauth2.Token{AccessToken: "expired", RefreshToken: "revoked", Expiry: time.Now().Add(-time.Hour)})
+	if _, err := ts.Token(); err == nil {
+		t.Fatal("Expected an error refreshing a revoked token")
+	}
+	if failures != 1 || invalid != 1 {
+		t.Errorf("Expected one failure and one invalid hook got %d and %d", failures, invalid)
+	}
+
+	// other refresh failures only call OnRefreshError.
+	server.Close()
+	ts.SetToken(&oauth2.Token{AccessToken: "expired", RefreshToken: "refresh", Expiry: time.Now().Add(-time.Hour)})
+	if _, err := ts.Token(); err == nil {
+		t.Fatal("Expected an error refreshing without a token endpoint")
+	}
+	if failures != 2 || invalid != 1 {
+		t.Errorf("Expected two failures and one invalid hook got %d and %d", failures, invalid)
+	}
+}